@@ -0,0 +1,47 @@
+package parsemail
+
+// Accounting sums up the bytes of a parsed message, so services enforcing
+// quotas don't have to measure them again
+type Accounting struct {
+	// HeaderBytes is the size of the raw header, with the blank line ending it
+	HeaderBytes int64
+
+	// TextBodyBytes and HTMLBodyBytes are the decoded sizes of the
+	// TextBodyParts and HTMLBodyParts, before trimming
+	TextBodyBytes []int64
+	HTMLBodyBytes []int64
+
+	// AttachmentBytes and EmbeddedFileBytes are the decoded sizes of the
+	// Attachments and EmbeddedFiles found in the message
+	AttachmentBytes   []int64
+	EmbeddedFileBytes []int64
+
+	// TotalBytes is the sum of all of the above
+	TotalBytes int64
+}
+
+// finishAccounting adds the header, attachments and embedded files of e to
+// the body part sizes counted while parsing
+func finishAccounting(e *Email) {
+	a := &e.Accounting
+	a.HeaderBytes = int64(len(e.RawHeader))
+	a.TotalBytes = a.HeaderBytes
+
+	for _, n := range a.TextBodyBytes {
+		a.TotalBytes += n
+	}
+
+	for _, n := range a.HTMLBodyBytes {
+		a.TotalBytes += n
+	}
+
+	for _, at := range e.Attachments {
+		a.AttachmentBytes = append(a.AttachmentBytes, at.Size)
+		a.TotalBytes += at.Size
+	}
+
+	for _, ef := range e.EmbeddedFiles {
+		a.EmbeddedFileBytes = append(a.EmbeddedFileBytes, ef.Size)
+		a.TotalBytes += ef.Size
+	}
+}
@@ -0,0 +1,52 @@
+package parsemail
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAccounting(t *testing.T) {
+	header := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n"
+
+	var testData = map[int]struct {
+		mailData string
+		expected Accounting
+	}{
+		1: {
+			mailData: header + "--m\nContent-Type: multipart/alternative; boundary=a\n\n" +
+				"--a\nContent-Type: text/plain\n\nhello\n\n" +
+				"--a\nContent-Type: text/html\n\n<p>hello</p>\n--a--\n" +
+				"--m\nContent-Type: image/png\nContent-ID: <img@x>\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+				"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\"\nContent-Transfer-Encoding: base64\n\nd29ybGQh\n--m--\n",
+			expected: Accounting{
+				HeaderBytes:       int64(len(header)),
+				TextBodyBytes:     []int64{6},
+				HTMLBodyBytes:     []int64{12},
+				AttachmentBytes:   []int64{6},
+				EmbeddedFileBytes: []int64{5},
+				TotalBytes:        int64(len(header)) + 6 + 12 + 6 + 5,
+			},
+		},
+		2: {
+			mailData: "Subject: Hi\n\nplain body\n",
+			expected: Accounting{
+				HeaderBytes:   13,
+				TextBodyBytes: []int64{11},
+				TotalBytes:    24,
+			},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(e.Accounting, td.expected) {
+			t.Errorf("[Test Case %v] Wrong accounting. Expected: %+v, Got: %+v", index, td.expected, e.Accounting)
+		}
+	}
+}
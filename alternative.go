@@ -0,0 +1,52 @@
+package parsemail
+
+// Alternative is a part of a multipart/alternative body with the text and
+// HTML body content it contributed. Alternatives that aren't rendered as a
+// body, like calendar parts, have neither.
+type Alternative struct {
+	ContentType string
+	Text        string
+	HTML        string
+}
+
+// BodyPartOrigin tells which multipart/alternative group, and which
+// alternative within it, a body part came from. Groups are numbered from 1 in
+// message order, a zero AlternativeGroup means the part isn't an alternative.
+// Parts of nested groups refer to the innermost one.
+type BodyPartOrigin struct {
+	AlternativeGroup    int
+	AlternativePosition int
+}
+
+// setBodyPartOrigins sets the origin of the parts added by an alternative that weren't claimed by a nested group
+func setBodyPartOrigins(origins []BodyPartOrigin, group, position int) {
+	for i := range origins {
+		if origins[i].AlternativeGroup == 0 {
+			origins[i] = BodyPartOrigin{AlternativeGroup: group, AlternativePosition: position}
+		}
+	}
+}
+
+// BestBody returns the body the sender prefers along with its content type,
+// text/html or text/plain. For multipart/alternative messages it is the last
+// alternative with a body, as alternatives are ordered by increasing
+// preference (RFC 2046 section 5.1.4), otherwise the HTML body is preferred
+// over the text body.
+func (e Email) BestBody() (contentType, body string) {
+	for i := len(e.Alternatives) - 1; i >= 0; i-- {
+		a := e.Alternatives[i]
+		if a.HTML != "" {
+			return contentTypeTextHtml, a.HTML
+		}
+
+		if a.Text != "" {
+			return contentTypeTextPlain, a.Text
+		}
+	}
+
+	if e.HTMLBody != "" {
+		return contentTypeTextHtml, e.HTMLBody
+	}
+
+	return contentTypeTextPlain, e.TextBody
+}
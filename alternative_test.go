@@ -0,0 +1,120 @@
+package parsemail
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBestBody(t *testing.T) {
+	var testData = map[int]struct {
+		mailData     string
+		alternatives []string
+		contentType  string
+		body         string
+	}{
+		1: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/alternative; boundary=b\n\n" +
+				"--b\nContent-Type: text/plain\n\nPlain\n--b\nContent-Type: text/html\n\n<p>Rich</p>\n--b--\n",
+			alternatives: []string{"text/plain", "text/html"},
+			contentType:  "text/html",
+			body:         "<p>Rich</p>",
+		},
+		2: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/alternative; boundary=b\n\n" +
+				"--b\nContent-Type: text/html\n\n<p>Rich</p>\n--b\nContent-Type: text/plain\n\nPlain\n" +
+				"--b\nContent-Type: text/calendar; method=REQUEST\n\nBEGIN:VCALENDAR\nEND:VCALENDAR\n--b--\n",
+			alternatives: []string{"text/html", "text/plain", "text/calendar"},
+			contentType:  "text/plain",
+			body:         "Plain",
+		},
+		3: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+				"--m\nContent-Type: multipart/alternative; boundary=b\n\n" +
+				"--b\nContent-Type: text/plain\n\nPlain\n" +
+				"--b\nContent-Type: multipart/related; boundary=r\n\n--r\nContent-Type: text/html\n\n<p>Related</p>\n--r--\n" +
+				"--b--\n--m--\n",
+			alternatives: []string{"text/plain", "multipart/related"},
+			contentType:  "text/html",
+			body:         "<p>Related</p>",
+		},
+		4: {
+			mailData:    "From: jdoe@machine.example\n\nJust text\n",
+			contentType: "text/plain",
+			body:        "Just text",
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		var types []string
+		for _, a := range e.Alternatives {
+			types = append(types, a.ContentType)
+		}
+
+		if !assertSliceEq(td.alternatives, types) {
+			t.Errorf("[Test Case %v] Wrong alternatives. Expected: %v, Got: %v", index, td.alternatives, types)
+		}
+
+		contentType, body := e.BestBody()
+		if contentType != td.contentType || body != td.body {
+			t.Errorf("[Test Case %v] Wrong best body. Expected: %v %q, Got: %v %q", index, td.contentType, td.body, contentType, body)
+		}
+	}
+}
+
+func TestWatchHTML(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/alternative; boundary=b\n\n" +
+		"--b\nContent-Type: text/plain\n\nPlain\n" +
+		"--b\nContent-Type: text/watch-html; charset=utf-8\nContent-Transfer-Encoding: quoted-printable\n\n<b>Watch</b> =\nview\n" +
+		"--b\nContent-Type: text/html\n\n<p>Rich</p>\n--b--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.WatchHTMLBody != "<b>Watch</b> view" {
+		t.Errorf("Wrong watch HTML body: %q", e.WatchHTMLBody)
+	}
+
+	if e.HTMLBody != "<p>Rich</p>" || e.TextBody != "Plain" {
+		t.Errorf("Watch HTML misfiled into bodies: %q, %q", e.HTMLBody, e.TextBody)
+	}
+
+	if len(e.Alternatives) != 3 || e.Alternatives[1].ContentType != "text/watch-html" {
+		t.Errorf("Wrong alternatives: %+v", e.Alternatives)
+	}
+}
+
+func TestBodyPartOrigins(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/related; boundary=r\n\n" +
+		"--r\nContent-Type: text/plain\n\nIntro\n" +
+		"--r\nContent-Type: multipart/alternative; boundary=a\n\n" +
+		"--a\nContent-Type: text/plain\n\nPlain\n" +
+		"--a\nContent-Type: multipart/related; boundary=n\n\n" +
+		"--n\nContent-Type: multipart/alternative; boundary=b\n\n" +
+		"--b\nContent-Type: text/plain\n\nInner plain\n--b\nContent-Type: text/html\n\n<p>Inner</p>\n--b--\n" +
+		"--n\nContent-Type: text/html\n\n<p>Outer</p>\n--n--\n" +
+		"--a--\n--r--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	textOrigins := []BodyPartOrigin{{}, {1, 0}, {2, 0}}
+	if !reflect.DeepEqual(textOrigins, e.TextBodyPartOrigins) {
+		t.Errorf("Wrong text body part origins. Expected: %v, Got: %v", textOrigins, e.TextBodyPartOrigins)
+	}
+
+	htmlOrigins := []BodyPartOrigin{{2, 1}, {1, 1}}
+	if !reflect.DeepEqual(htmlOrigins, e.HTMLBodyPartOrigins) {
+		t.Errorf("Wrong html body part origins. Expected: %v, Got: %v", htmlOrigins, e.HTMLBodyPartOrigins)
+	}
+}
@@ -0,0 +1,196 @@
+package parsemail
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFilenameLen is the length in bytes sanitized filenames are cut to, the limit of most file systems
+const maxFilenameLen = 255
+
+// ErrNotSeekable is returned when the attachment data doesn't support random access
+var ErrNotSeekable = errors.New("Attachment data is not seekable")
+
+// sizedReaderAt is implemented by attachment data that supports random access
+type sizedReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// ReadAt reads len(p) bytes of the decoded attachment data starting at offset off.
+// It does not affect reads from Data.
+func (a Attachment) ReadAt(p []byte, off int64) (int, error) {
+	ra, ok := a.Data.(io.ReaderAt)
+	if !ok {
+		return 0, ErrNotSeekable
+	}
+
+	return ra.ReadAt(p, off)
+}
+
+// SectionReader returns a new independent reader over the whole decoded attachment
+// data. It implements io.ReadSeeker and is suitable for http.ServeContent, so
+// attachments can be range-served without copying them.
+func (a Attachment) SectionReader() (*io.SectionReader, error) {
+	sra, ok := a.Data.(sizedReaderAt)
+	if !ok {
+		return nil, ErrNotSeekable
+	}
+
+	return io.NewSectionReader(sra, 0, sra.Size()), nil
+}
+
+// WriteMultipart copies the attachment data into a new file part of mw named
+// fieldName, using the attachment filename and content type. Seekable data is
+// read from its start.
+func (a Attachment) WriteMultipart(mw *multipart.Writer, fieldName string) error {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     fieldName,
+		"filename": a.Filename,
+	}))
+	h.Set("Content-Type", contentType)
+
+	w, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	var data io.Reader = a.Data
+	if sr, err := a.SectionReader(); err == nil {
+		data = sr
+	}
+
+	_, err = io.Copy(w, data)
+	return err
+}
+
+// MultipartBody returns a multipart/form-data body holding the attachment in the
+// file field fieldName, along with its content type. The attachment data is
+// streamed through a pipe as the body is read, without buffering it. Closing the
+// body before reading it to the end aborts the copy.
+func (a Attachment) MultipartBody(fieldName string) (body io.ReadCloser, contentType string) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		err := a.WriteMultipart(mw, fieldName)
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr, mw.FormDataContentType()
+}
+
+// NewUploadRequest returns a request uploading the attachment to url as the file
+// field fieldName of a streamed multipart/form-data body
+func (a Attachment) NewUploadRequest(method, url, fieldName string) (*http.Request, error) {
+	body, contentType := a.MultipartBody(fieldName)
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
+}
+
+// SanitizeFilename returns name made safe to use as a file name on disk: path
+// components, control and reserved characters and leading dots are removed
+// and the name is cut to 255 bytes, keeping its extension. Names that end up
+// empty are replaced by "attachment".
+func SanitizeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i != -1 {
+		name = name[i+1:]
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`<>:"|?*`, r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+	name = strings.TrimRight(name, ". ")
+
+	if len(name) > maxFilenameLen {
+		ext := filepath.Ext(name)
+		if len(ext) > maxFilenameLen/2 {
+			ext = ""
+		}
+
+		base := name[:maxFilenameLen-len(ext)]
+		for !utf8.ValidString(base) {
+			base = base[:len(base)-1]
+		}
+		name = base + ext
+	}
+
+	if name == "" {
+		return "attachment"
+	}
+
+	return name
+}
+
+// SaveTo writes the attachment data to a new file in dir named after the
+// sanitized filename, see SanitizeFilename, and returns its path. Existing
+// files are never overwritten, a number is added to the name instead.
+func (a Attachment) SaveTo(dir string) (string, error) {
+	name := SanitizeFilename(a.Filename)
+	ext := filepath.Ext(name)
+
+	var f *os.File
+	var err error
+	for i := 1; ; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), i, ext)
+		}
+
+		f, err = os.OpenFile(filepath.Join(dir, candidate), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !os.IsExist(err) {
+			break
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	var data io.Reader = a.Data
+	if sr, srErr := a.SectionReader(); srErr == nil {
+		data = sr
+	}
+
+	if _, err = io.Copy(f, data); err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
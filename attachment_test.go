@@ -0,0 +1,223 @@
+package parsemail
+
+import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttachmentRandomAccess(t *testing.T) {
+	e, err := Parse(strings.NewReader(data1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.Attachments) != 1 {
+		t.Fatalf("Expected 1 attachment, got %v", len(e.Attachments))
+	}
+
+	a := e.Attachments[0]
+	b := make([]byte, 4)
+	if _, err := a.ReadAt(b, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "PDF-" {
+		t.Errorf("Wrong ReadAt data. Expected: 'PDF-', Got: '%s'", b)
+	}
+
+	sr, err := a.SectionReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Range", "bytes=0-4")
+	http.ServeContent(rec, req, a.Filename, time.Time{}, sr)
+
+	if rec.Code != http.StatusPartialContent {
+		t.Errorf("Wrong status. Expected: %v, Got: %v", http.StatusPartialContent, rec.Code)
+	}
+
+	if body := rec.Body.String(); body != "%PDF-" {
+		t.Errorf("Wrong range body. Expected: '%%PDF-', Got: '%s'", body)
+	}
+
+	full, err := ioutil.ReadAll(a.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(full), "%PDF-") {
+		t.Errorf("Data was affected by random access reads")
+	}
+
+	if _, err := (Attachment{Data: strings.NewReader("x")}).SectionReader(); err != nil {
+		t.Errorf("Expected strings.Reader to be seekable, got %v", err)
+	}
+
+	if _, err := (Attachment{Data: ioutil.NopCloser(nil)}).SectionReader(); err != ErrNotSeekable {
+		t.Errorf("Expected ErrNotSeekable, got %v", err)
+	}
+}
+
+func TestAttachmentUpload(t *testing.T) {
+	e, err := Parse(strings.NewReader(data1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var received []byte
+	var filename, contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, fh, err := r.FormFile("document")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+
+		received, _ = ioutil.ReadAll(f)
+		filename = fh.Filename
+		contentType = fh.Header.Get("Content-Type")
+	}))
+	defer srv.Close()
+
+	req, err := e.Attachments[0].NewUploadRequest("POST", srv.URL, "document")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Upload failed with status %v", resp.StatusCode)
+	}
+
+	if filename != e.Attachments[0].Filename || contentType != "application/pdf" {
+		t.Errorf("Wrong uploaded file metadata: '%s' %s", filename, contentType)
+	}
+
+	if !strings.HasPrefix(string(received), "%PDF-") {
+		t.Errorf("Wrong uploaded data: %s", received)
+	}
+}
+
+func TestAttachmentWriteMultipartRepeated(t *testing.T) {
+	e, err := Parse(strings.NewReader(data1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := e.Attachments[0]
+	for i := 0; i < 2; i++ {
+		body, contentType := a.MultipartBody("document")
+		_, params, _ := mime.ParseMediaType(contentType)
+		part, err := multipart.NewReader(body, params["boundary"]).NextPart()
+		if err != nil {
+			t.Fatalf("[Upload %v] Unexpected error: %v", i, err)
+		}
+
+		data, _ := ioutil.ReadAll(part)
+		body.Close()
+		if int64(len(data)) != a.Size || !strings.HasPrefix(string(data), "%PDF-") {
+			t.Errorf("[Upload %v] Wrong uploaded data. Expected %v bytes, Got: %v", i, a.Size, len(data))
+		}
+	}
+
+	if data, _ := ioutil.ReadAll(a.Data); int64(len(data)) != a.Size {
+		t.Errorf("Data was consumed by WriteMultipart. Expected %v bytes, Got: %v", a.Size, len(data))
+	}
+}
+
+func TestAttachmentDigest(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"hello.txt\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	// sha256 of "hello"
+	digest := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	for index, opts := range map[int][]Option{
+		1: nil,
+		2: {WithSpooling(1, "")},
+		3: {WithAttachmentStreaming(func(Attachment) error { return nil })},
+	} {
+		e, err := ParseWithOptions(strings.NewReader(mailData), opts...)
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		a := e.Attachments[0]
+		if a.Size != 5 {
+			t.Errorf("[Test Case %v] Wrong size. Expected: 5, Got: %v", index, a.Size)
+		}
+
+		if a.SHA256 != digest {
+			t.Errorf("[Test Case %v] Wrong digest. Expected: %v, Got: %v", index, digest, a.SHA256)
+		}
+
+		e.Close()
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	var testData = map[int]struct {
+		name     string
+		expected string
+	}{
+		1: {name: "report.pdf", expected: "report.pdf"},
+		2: {name: "../../etc/passwd", expected: "passwd"},
+		3: {name: `C:\Windows\system32\evil.dll`, expected: "evil.dll"},
+		4: {name: "..", expected: "attachment"},
+		5: {name: ".bashrc", expected: "bashrc"},
+		6: {name: "in\x00voice\r\n?.pdf", expected: "invoice.pdf"},
+		7: {name: "", expected: "attachment"},
+		8: {name: strings.Repeat("é", 200) + ".pdf", expected: strings.Repeat("é", 125) + ".pdf"},
+	}
+
+	for index, td := range testData {
+		if got := SanitizeFilename(td.name); got != td.expected {
+			t.Errorf("[Test Case %v] Wrong filename. Expected: %q, Got: %q", index, td.expected, got)
+		}
+	}
+}
+
+func TestAttachmentSaveTo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parsemail-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	a := Attachment{Filename: "../report.txt", Data: strings.NewReader("hello")}
+	path, err := a.SaveTo(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if path != filepath.Join(dir, "report.txt") {
+		t.Errorf("Wrong path. Expected: %q, Got: %q", filepath.Join(dir, "report.txt"), path)
+	}
+
+	if data, _ := ioutil.ReadFile(path); string(data) != "hello" {
+		t.Errorf("Wrong file content. Expected: 'hello', Got: %q", data)
+	}
+
+	a.Data = strings.NewReader("again")
+	if path, err = a.SaveTo(dir); err != nil || path != filepath.Join(dir, "report (2).txt") {
+		t.Errorf("Expected a numbered file name, got %q, %v", path, err)
+	}
+}
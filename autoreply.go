@@ -0,0 +1,83 @@
+package parsemail
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// autoReplySubjectPattern matches subjects used by vacation responders in common languages
+var autoReplySubjectPattern = regexp.MustCompile(`(?i)^\s*(` + strings.Join([]string{
+	`out of (the )?office`,
+	`auto(matic)?[ -]?reply`,
+	`auto(matic)? response`,
+	`autoresponder`,
+	`on vacation`,
+	`away from (the )?office`,
+	`abwesenheitsnotiz`,
+	`automatische antwort`,
+	`abwesend`,
+	`r[ée]ponse automatique`,
+	`absence du bureau`,
+	`respuesta autom[áa]tica`,
+	`fuera de la oficina`,
+	`risposta automatica`,
+	`fuori sede`,
+	`fuori ufficio`,
+	`resposta autom[áa]tica`,
+	`fora do escrit[óo]rio`,
+	`automatisch antwoord`,
+	`afwezig`,
+	`automatiskt svar`,
+	`fr[åa]nvaro`,
+	`automatisk svar`,
+	`automaattinen vastaus`,
+	`automatyczna odpowied[źz]`,
+	`автоответ`,
+	`автоматический ответ`,
+	`自动回复`,
+	`自動回覆`,
+	`自動応答`,
+	`不在通知`,
+}, "|") + `)`)
+
+// detectAutoReply sets LikelyAutoReply and AutoReplyEvidence when the message
+// looks like it was sent by a vacation responder. An Auto-Submitted value of
+// auto-generated alone isn't enough, as it is also used by notifications.
+func detectAutoReply(e *Email) {
+	var evidence []string
+	generated := false
+
+	auto := strings.ToLower(strings.TrimSpace(e.Header.Get("Auto-Submitted")))
+	if i := strings.IndexAny(auto, " \t;("); i != -1 {
+		auto = auto[:i]
+	}
+
+	switch auto {
+	case "auto-replied":
+		evidence = append(evidence, "Auto-Submitted: auto-replied")
+	case "auto-generated":
+		generated = true
+	}
+
+	for _, name := range []string{"X-Autoreply", "X-Autorespond", "X-Autoresponder"} {
+		if v := strings.ToLower(strings.TrimSpace(e.Header.Get(name))); v != "" && v != "no" && v != "false" {
+			evidence = append(evidence, name+" header present")
+		}
+	}
+
+	if strings.EqualFold(strings.TrimSpace(e.Header.Get("Precedence")), "auto_reply") {
+		evidence = append(evidence, "Precedence: auto_reply")
+	}
+
+	if autoReplySubjectPattern.MatchString(e.Subject) {
+		evidence = append(evidence, fmt.Sprintf("Subject %q matches an auto-reply pattern", e.Subject))
+	}
+
+	if generated && len(evidence) > 0 {
+		evidence = append(evidence, "Auto-Submitted: auto-generated")
+	}
+
+	e.LikelyAutoReply = len(evidence) > 0
+	e.AutoReplyEvidence = evidence
+}
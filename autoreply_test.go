@@ -0,0 +1,58 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectAutoReply(t *testing.T) {
+	var testData = map[int]struct {
+		mailData string
+		evidence []string
+	}{
+		1: {
+			mailData: rfc5322exampleA11,
+		},
+		2: {
+			mailData: "From: jane@example.org\nTo: support@example.com\nSubject: Out of Office: Ticket #42\nAuto-Submitted: auto-replied\n\nI'm away.\n",
+			evidence: []string{"Auto-Submitted: auto-replied", `Subject "Out of Office: Ticket #42" matches an auto-reply pattern`},
+		},
+		3: {
+			mailData: "From: jane@example.org\nTo: support@example.com\nSubject: =?utf-8?q?Abwesenheitsnotiz=3A_Ticket?=\n\nIch bin nicht da.\n",
+			evidence: []string{`Subject "Abwesenheitsnotiz: Ticket" matches an auto-reply pattern`},
+		},
+		4: {
+			mailData: "From: jane@example.org\nTo: support@example.com\nSubject: =?utf-8?q?R=C3=A9ponse_automatique?=\n\nAbsent.\n",
+			evidence: []string{`Subject "Réponse automatique" matches an auto-reply pattern`},
+		},
+		5: {
+			mailData: "From: jane@example.org\nTo: support@example.com\nSubject: Re: Ticket #42\nX-Autoreply: yes\nPrecedence: auto_reply\n\nAway.\n",
+			evidence: []string{"X-Autoreply header present", "Precedence: auto_reply"},
+		},
+		6: {
+			mailData: "From: noreply@example.org\nTo: jane@example.org\nSubject: Your invoice\nAuto-Submitted: auto-generated\nPrecedence: bulk\n\nInvoice attached.\n",
+		},
+		7: {
+			mailData: "From: jane@example.org\nTo: support@example.com\nSubject: Automatic reply: Ticket #42\nAuto-Submitted: auto-generated\n\nAway.\n",
+			evidence: []string{`Subject "Automatic reply: Ticket #42" matches an auto-reply pattern`, "Auto-Submitted: auto-generated"},
+		},
+		8: {
+			mailData: "From: jane@example.org\nTo: support@example.com\nSubject: Re: out of office policy\n\nLet's discuss.\n",
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Fatalf("[Test Case %v] %v", index, err)
+		}
+
+		if e.LikelyAutoReply != (len(td.evidence) > 0) {
+			t.Errorf("[Test Case %v] Wrong LikelyAutoReply: %v", index, e.LikelyAutoReply)
+		}
+
+		if !assertSliceEq(td.evidence, e.AutoReplyEvidence) {
+			t.Errorf("[Test Case %v] Wrong evidence. Expected: %v, Got: %v", index, td.evidence, e.AutoReplyEvidence)
+		}
+	}
+}
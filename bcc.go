@@ -0,0 +1,60 @@
+package parsemail
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// envelopeRecipientHeaders are added on delivery and name the envelope recipient of the copy
+var envelopeRecipientHeaders = []string{"Delivered-To", "X-Original-To", "Envelope-To", "X-Envelope-To"}
+
+var receivedForPattern = regexp.MustCompile(`(?i)\bfor\s+<?([^\s<>;]+@[^\s<>;]+)>?`)
+
+// detectBcc sets LikelyBcc and BccEvidence when the message was delivered as a
+// blind copy: it carries a Bcc header, has no visible recipients, or was
+// delivered to a recipient missing from To and Cc.
+func detectBcc(e *Email) {
+	var evidence []string
+	if len(e.Header["Bcc"]) > 0 {
+		evidence = append(evidence, "Bcc header present")
+	}
+
+	lists := [][]*mail.Address{e.To, e.Cc}
+	for _, rb := range e.ResentBlocks {
+		lists = append(lists, rb.To, rb.Cc)
+	}
+
+	visible := map[string]bool{}
+	for _, list := range lists {
+		for _, a := range list {
+			visible[strings.ToLower(a.Address)] = true
+		}
+	}
+
+	if len(visible) == 0 {
+		evidence = append(evidence, "No To or Cc recipients")
+	}
+
+	for _, name := range envelopeRecipientHeaders {
+		for _, v := range e.Header[name] {
+			addr := strings.ToLower(strings.Trim(strings.TrimSpace(v), "<>"))
+			if addr != "" && len(visible) > 0 && !visible[addr] {
+				evidence = append(evidence, fmt.Sprintf("%s recipient %s not in To or Cc", name, addr))
+			}
+		}
+	}
+
+	for _, v := range e.Header["Received"] {
+		if m := receivedForPattern.FindStringSubmatch(v); m != nil {
+			addr := strings.ToLower(m[1])
+			if len(visible) > 0 && !visible[addr] {
+				evidence = append(evidence, fmt.Sprintf("Received for %s not in To or Cc", addr))
+			}
+		}
+	}
+
+	e.LikelyBcc = len(evidence) > 0
+	e.BccEvidence = evidence
+}
@@ -0,0 +1,49 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectBcc(t *testing.T) {
+	var testData = map[int]struct {
+		mailData string
+		evidence []string
+	}{
+		1: {
+			mailData: rfc5322exampleA11,
+		},
+		2: {
+			mailData: rfc5322exampleA4,
+		},
+		3: {
+			mailData: "From: John Doe <jdoe@machine.example>\nTo: Mary Smith <mary@example.net>\nBcc: boss@example.net\n\nHi.\n",
+			evidence: []string{"Bcc header present"},
+		},
+		4: {
+			mailData: "From: John Doe <jdoe@machine.example>\nDelivered-To: boss@example.net\n\nHi.\n",
+			evidence: []string{"No To or Cc recipients"},
+		},
+		5: {
+			mailData: "Received: from x.y.test by example.net for <Boss@example.net>; 21 Nov 1997 10:05:43 -0600\n" +
+				"Delivered-To: boss@example.net\nDelivered-To: MARY@example.net\n" +
+				"From: John Doe <jdoe@machine.example>\nTo: Mary Smith <mary@example.net>\n\nHi.\n",
+			evidence: []string{"Delivered-To recipient boss@example.net not in To or Cc", "Received for boss@example.net not in To or Cc"},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Fatalf("[Test Case %v] %v", index, err)
+		}
+
+		if e.LikelyBcc != (len(td.evidence) > 0) {
+			t.Errorf("[Test Case %v] Wrong LikelyBcc: %v", index, e.LikelyBcc)
+		}
+
+		if !assertSliceEq(td.evidence, e.BccEvidence) {
+			t.Errorf("[Test Case %v] Wrong evidence. Expected: %v, Got: %v", index, td.evidence, e.BccEvidence)
+		}
+	}
+}
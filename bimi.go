@@ -0,0 +1,49 @@
+package parsemail
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// BIMI holds the Brand Indicators for Message Identification headers. Selector
+// comes from the sender's BIMI-Selector header, Location (the logo URL) and
+// Authority (the Verified Mark Certificate URL) from the BIMI-Location header
+// added by the receiving system, as does Indicator, the decoded BIMI-Indicator SVG.
+type BIMI struct {
+	Version   string
+	Selector  string
+	Location  string
+	Authority string
+	Indicator []byte
+}
+
+func (hp headerParser) parseBIMI() *BIMI {
+	selector := hp.header.Get("BIMI-Selector")
+	location := hp.header.Get("BIMI-Location")
+	indicator := hp.header.Get("BIMI-Indicator")
+	if selector == "" && location == "" && indicator == "" {
+		return nil
+	}
+
+	b := &BIMI{}
+	if selector != "" {
+		tags := parseTagList(selector)
+		b.Version = tags["v"]
+		b.Selector = tags["s"]
+	}
+
+	if location != "" {
+		tags := parseTagList(location)
+		if b.Version == "" {
+			b.Version = tags["v"]
+		}
+		b.Location = tags["l"]
+		b.Authority = tags["a"]
+	}
+
+	if indicator != "" {
+		b.Indicator, _ = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(indicator), ""))
+	}
+
+	return b
+}
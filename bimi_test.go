@@ -0,0 +1,39 @@
+package parsemail
+
+import (
+	"net/mail"
+	"testing"
+)
+
+func TestParseBIMI(t *testing.T) {
+	hp := headerParser{header: &mail.Header{}}
+	if b := hp.parseBIMI(); b != nil {
+		t.Errorf("Expected no BIMI info, got %v", b)
+	}
+
+	header := mail.Header{
+		"Bimi-Selector":  {"v=BIMI1; s=brand;"},
+		"Bimi-Location":  {"v=BIMI1; l=https://example.com/bimi/logo.svg;\n a=https://example.com/bimi/vmc.pem"},
+		"Bimi-Indicator": {"PHN2Zz48L3N2Zz4="},
+	}
+	hp = headerParser{header: &header}
+	b := hp.parseBIMI()
+	if b == nil {
+		t.Fatal("BIMI headers not parsed")
+	}
+
+	expected := BIMI{
+		Version:   "BIMI1",
+		Selector:  "brand",
+		Location:  "https://example.com/bimi/logo.svg",
+		Authority: "https://example.com/bimi/vmc.pem",
+	}
+
+	if b.Version != expected.Version || b.Selector != expected.Selector || b.Location != expected.Location || b.Authority != expected.Authority {
+		t.Errorf("Wrong BIMI info. Expected: %+v, Got: %+v", expected, *b)
+	}
+
+	if string(b.Indicator) != "<svg></svg>" {
+		t.Errorf("Wrong indicator: '%s'", b.Indicator)
+	}
+}
@@ -0,0 +1,72 @@
+package parsemail
+
+import (
+	"io"
+	"net/textproto"
+	"strings"
+)
+
+// TextBodyReader returns a new reader over the text body. When the message was
+// parsed WithBodyReaders it reads the decoded text body parts, joined by the
+// body separator, from where they were kept, in memory or spooled to disk,
+// without building a string of them. Otherwise it reads TextBody.
+func (e Email) TextBodyReader() io.Reader {
+	return bodyReader(e.TextBody, e.textBodyData, e.bodySeparator)
+}
+
+// HTMLBodyReader returns a new reader over the HTML body, see TextBodyReader
+func (e Email) HTMLBodyReader() io.Reader {
+	return bodyReader(e.HTMLBody, e.htmlBodyData, e.bodySeparator)
+}
+
+func bodyReader(body string, data []io.Reader, sep string) io.Reader {
+	if data == nil {
+		return strings.NewReader(body)
+	}
+
+	readers := make([]io.Reader, 0, 2*len(data))
+	for i, d := range data {
+		if i > 0 && sep != "" {
+			readers = append(readers, strings.NewReader(sep))
+		}
+
+		sra := d.(sizedReaderAt)
+		readers = append(readers, io.NewSectionReader(sra, 0, sra.Size()))
+	}
+
+	return io.MultiReader(readers...)
+}
+
+// readBodyData decodes the body part r with the header h like attachment data,
+// counting it against the decoded size limit
+func readBodyData(e *Email, r io.Reader, h textproto.MIMEHeader, o *options) (io.Reader, error) {
+	var w io.Writer
+	if limiter := newPartLimiter(e, false); limiter != nil {
+		w = limiter
+	}
+
+	return readPartData(r, h, w, o)
+}
+
+// addBodyData decodes the body part r with the header h into the body data of e
+func addBodyData(e *Email, r io.Reader, h textproto.MIMEHeader, html bool, o *options) error {
+	data, err := readBodyData(e, r, h, o)
+	if err != nil {
+		return err
+	}
+
+	appendBodyData(e, data, h, html)
+	return nil
+}
+
+func appendBodyData(e *Email, data io.Reader, h textproto.MIMEHeader, html bool) {
+	size := data.(sizedReaderAt).Size()
+	if html {
+		e.htmlBodyData = append(e.htmlBodyData, data)
+		recordHTMLBodyPart(e, size, h)
+		return
+	}
+
+	e.textBodyData = append(e.textBodyData, data)
+	recordTextBodyPart(e, size, h)
+}
@@ -0,0 +1,88 @@
+package parsemail
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestBodyReaders(t *testing.T) {
+	var testData = map[int]struct {
+		mailData string
+		options  []Option
+		text     string
+		html     string
+	}{
+		1: {
+			mailData: "From: jdoe@machine.example\nContent-Type: text/plain\n\nHello\n",
+			text:     "Hello\n",
+		},
+		2: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/alternative; boundary=a\n\n" +
+				"--a\nContent-Type: text/plain\nContent-Transfer-Encoding: base64\n\nSGVsbG8=\n" +
+				"--a\nContent-Type: text/html\n\n<p>Hello</p>\n--a--\n",
+			text: "Hello",
+			html: "<p>Hello</p>",
+		},
+		3: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+				"--m\nContent-Type: text/plain\n\nfirst\n--m\nContent-Type: text/plain\n\nsecond\n--m--\n",
+			options: []Option{WithBodySeparator("\n--\n"), WithSpooling(1, "")},
+			text:    "first\n--\nsecond",
+		},
+		4: {
+			mailData: "From: jdoe@machine.example\nContent-Type: application/octet-stream\n\n<html><body>Hi</body></html>\n",
+			options:  []Option{WithOctetStreamHeuristics()},
+			html:     "<html><body>Hi</body></html>\n",
+		},
+	}
+
+	for index, td := range testData {
+		e, err := ParseWithOptions(strings.NewReader(td.mailData), append(td.options, WithBodyReaders())...)
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if e.TextBody != "" || e.HTMLBody != "" || e.TextBodyParts != nil || e.HTMLBodyParts != nil {
+			t.Errorf("[Test Case %v] Bodies were kept as strings: %q, %q", index, e.TextBody, e.HTMLBody)
+		}
+
+		for i := 0; i < 2; i++ {
+			if text, _ := ioutil.ReadAll(e.TextBodyReader()); string(text) != td.text {
+				t.Errorf("[Test Case %v] Wrong text body. Expected: %q, Got: %q", index, td.text, text)
+			}
+
+			if html, _ := ioutil.ReadAll(e.HTMLBodyReader()); string(html) != td.html {
+				t.Errorf("[Test Case %v] Wrong HTML body. Expected: %q, Got: %q", index, td.html, html)
+			}
+		}
+
+		if e.Accounting.TotalBytes-e.Accounting.HeaderBytes != int64(len(strings.Replace(td.text, "\n--\n", "", 1))+len(td.html)) {
+			t.Errorf("[Test Case %v] Wrong body accounting: %+v", index, e.Accounting)
+		}
+
+		if err := e.Close(); err != nil {
+			t.Errorf("[Test Case %v] Unexpected close error: %v", index, err)
+		}
+	}
+}
+
+func TestBodyReadersLimits(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: text/plain\n\n" + strings.Repeat("a", 1000) + "\n"
+
+	if _, err := ParseWithOptions(strings.NewReader(mailData), WithBodyReaders(), WithMaxDecodedSize(500)); err == nil {
+		t.Errorf("Expected the decoded size limit to apply to body readers")
+	}
+}
+
+func TestBodyReaderWithoutOption(t *testing.T) {
+	e, err := Parse(strings.NewReader("From: jdoe@machine.example\nContent-Type: text/plain\n\nHello\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text, _ := ioutil.ReadAll(e.TextBodyReader()); string(text) != e.TextBody || e.TextBody != "Hello" {
+		t.Errorf("Wrong text body reader. Expected: %q, Got: %q", e.TextBody, text)
+	}
+}
@@ -0,0 +1,62 @@
+package parsemail
+
+import "strings"
+
+// BodyStats are metrics of a body for spam scoring and analytics. For HTML
+// bodies lines and quoted lines are counted on the text rendering, see
+// HTMLToText, and Tags is the number of elements.
+type BodyStats struct {
+	Bytes int
+	Lines int
+
+	// QuotedLines is the number of lines quoting another message with ">" and QuotedRatio their share of Lines
+	QuotedLines int
+	QuotedRatio float64
+
+	// Tags is the number of HTML elements and TagDensity the number of elements per kilobyte of HTML
+	Tags       int
+	TagDensity float64
+}
+
+// TextBodyStats returns the metrics of the text body
+func (e Email) TextBodyStats() BodyStats {
+	return textStats(e.TextBody)
+}
+
+// HTMLBodyStats returns the metrics of the HTML body
+func (e Email) HTMLBodyStats() BodyStats {
+	if e.HTMLBody == "" {
+		return BodyStats{}
+	}
+
+	s := textStats(HTMLToText(e.HTMLBody))
+	s.Bytes = len(e.HTMLBody)
+
+	ParseHTML(e.HTMLBody).Walk(func(n *HTMLNode) bool {
+		if n.Type == HTMLElementNode {
+			s.Tags++
+		}
+		return true
+	})
+	s.TagDensity = float64(s.Tags) * 1024 / float64(s.Bytes)
+
+	return s
+}
+
+func textStats(text string) (s BodyStats) {
+	s.Bytes = len(text)
+	if text == "" {
+		return
+	}
+
+	lines := strings.Split(strings.TrimSuffix(strings.Replace(text, "\r\n", "\n", -1), "\n"), "\n")
+	s.Lines = len(lines)
+	for _, l := range lines {
+		if strings.HasPrefix(strings.TrimLeft(l, " \t"), ">") {
+			s.QuotedLines++
+		}
+	}
+	s.QuotedRatio = float64(s.QuotedLines) / float64(s.Lines)
+
+	return
+}
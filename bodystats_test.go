@@ -0,0 +1,31 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBodyStats(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/alternative; boundary=b\n\n" +
+		"--b\nContent-Type: text/plain\n\nYes.\n\n> Lunch?\n> At noon?\n--b\n" +
+		"Content-Type: text/html\n\n<p>Yes.</p><blockquote><p>Lunch?</p></blockquote>\n--b--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := BodyStats{Bytes: 25, Lines: 4, QuotedLines: 2, QuotedRatio: 0.5}
+	if s := e.TextBodyStats(); s != text {
+		t.Errorf("Wrong text body stats. Expected: %+v, Got: %+v", text, s)
+	}
+
+	html := BodyStats{Bytes: 49, Lines: 3, QuotedLines: 1, QuotedRatio: 1.0 / 3, Tags: 3, TagDensity: 3 * 1024 / 49.0}
+	if s := e.HTMLBodyStats(); s != html {
+		t.Errorf("Wrong HTML body stats. Expected: %+v, Got: %+v", html, s)
+	}
+
+	if s := (Email{}).HTMLBodyStats(); s != (BodyStats{}) {
+		t.Errorf("Expected empty stats, Got: %+v", s)
+	}
+}
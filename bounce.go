@@ -0,0 +1,181 @@
+package parsemail
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// BounceType classifies why a message came back, see ClassifyBounce
+type BounceType int
+
+// Bounce types, BounceNone is used for messages that aren't bounces
+const (
+	BounceNone BounceType = iota
+	// BounceHard is a permanent failure, like an unknown recipient
+	BounceHard
+	// BounceSoft is a temporary failure, like a full mailbox or a delayed delivery
+	BounceSoft
+	// BounceBlock is a rejection by policy, like a blocklisted sender or content considered spam
+	BounceBlock
+	// BounceAutoReply is an automatic reply, like a vacation message
+	BounceAutoReply
+)
+
+func (t BounceType) String() string {
+	switch t {
+	case BounceHard:
+		return "Hard"
+	case BounceSoft:
+		return "Soft"
+	case BounceBlock:
+		return "Block"
+	case BounceAutoReply:
+		return "AutoReply"
+	default:
+		return "None"
+	}
+}
+
+// Bounce is the classification of a bounce for one affected recipient
+type Bounce struct {
+	Type      BounceType
+	Recipient string
+	// Status is the enhanced status code (RFC 3463) like 5.1.1, when known
+	Status string
+	// SMTPCode is the SMTP reply code like 550, when known
+	SMTPCode   string
+	Diagnostic string
+}
+
+var (
+	bounceSenderPattern  = regexp.MustCompile(`(?i)^(mailer-daemon|postmaster|mail-daemon|mailerdaemon)@`)
+	bounceSubjectPattern = regexp.MustCompile(`(?i)(undeliver|delivery status notification|delivery (has )?failed|mail delivery failed|returned mail|failure notice|delivery failure|non[- ]?delivery|could not be delivered|unzustellbar|nicht zustellbar|non remis|no entregado)`)
+	smtpCodePattern      = regexp.MustCompile(`\b([245]\d\d)[ -]`)
+	enhancedCodePattern  = regexp.MustCompile(`\b([245]\.\d{1,3}\.\d{1,3})\b`)
+	bodyAddressPattern   = regexp.MustCompile(`[^\s<>()"',;:\[\]]+@[^\s<>()"',;:\[\]]+\.[a-zA-Z]{2,}`)
+	blockPattern         = regexp.MustCompile(`(?i)(block|blacklist|blocklist|denylist|spam|policy|reputation|listed|banned|refused|rbl|spamhaus|abuse)`)
+	mailboxFullPattern   = regexp.MustCompile(`(?i)(mailbox (is )?full|over ?quota|quota exceeded|insufficient (system )?storage)`)
+)
+
+// ClassifyBounce classifies e when it's a bounce or an automatic reply,
+// returning one Bounce per affected recipient, or nothing for other messages.
+// Delivery status reports (RFC 3464) are classified by the status codes of
+// their failed or delayed recipients, other bounces by the SMTP codes and
+// addresses found in their text. Rejections by policy (status 5.7.x or a
+// diagnostic mentioning blocklists or spam) are BounceBlock, full mailboxes
+// BounceSoft.
+func ClassifyBounce(e Email) (bounces []Bounce) {
+	if e.DeliveryStatus != nil {
+		for _, r := range e.DeliveryStatus.Recipients {
+			if r.Action != "failed" && r.Action != "delayed" {
+				continue
+			}
+
+			b := Bounce{Status: r.Status, Diagnostic: r.DiagnosticCode}
+			if r.FinalRecipient != nil {
+				b.Recipient = r.FinalRecipient.Address
+			}
+			if r.OriginalRecipient != nil {
+				b.Recipient = r.OriginalRecipient.Address
+			}
+			if m := smtpCodePattern.FindStringSubmatch(r.DiagnosticCode + " "); m != nil {
+				b.SMTPCode = m[1]
+			}
+
+			b.Type = bounceType(b.Status, b.SMTPCode, b.Diagnostic)
+			if r.Action == "delayed" {
+				b.Type = BounceSoft
+			}
+
+			bounces = append(bounces, b)
+		}
+
+		return
+	}
+
+	if isAutoReply(e) {
+		b := Bounce{Type: BounceAutoReply}
+		if len(e.From) > 0 {
+			b.Recipient = e.From[0].Address
+		}
+
+		return []Bounce{b}
+	}
+
+	if !looksLikeBounce(e) {
+		return nil
+	}
+
+	text := e.TextBody
+	if text == "" {
+		text = e.HTMLBody
+	}
+
+	b := Bounce{Recipient: bouncedRecipient(e, text)}
+	if m := enhancedCodePattern.FindStringSubmatch(text); m != nil {
+		b.Status = m[1]
+	}
+	if m := smtpCodePattern.FindStringSubmatch(text); m != nil && m[1][0] != '2' {
+		b.SMTPCode = m[1]
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if (b.SMTPCode != "" && strings.Contains(line, b.SMTPCode)) || (b.Status != "" && strings.Contains(line, b.Status)) {
+			b.Diagnostic = strings.TrimSpace(line)
+			break
+		}
+	}
+
+	b.Type = bounceType(b.Status, b.SMTPCode, text)
+
+	return []Bounce{b}
+}
+
+// bounceType classifies a failure by its status codes, defaulting to a hard bounce
+func bounceType(status, smtpCode, diagnostic string) BounceType {
+	switch {
+	case strings.HasPrefix(status, "5.7.") || blockPattern.MatchString(diagnostic):
+		return BounceBlock
+	case strings.HasPrefix(status, "4.") || strings.HasPrefix(smtpCode, "4"):
+		return BounceSoft
+	case strings.HasSuffix(status, ".2.2") || mailboxFullPattern.MatchString(diagnostic):
+		return BounceSoft
+	}
+
+	return BounceHard
+}
+
+func looksLikeBounce(e Email) bool {
+	for _, a := range e.From {
+		if bounceSenderPattern.MatchString(a.Address) {
+			return true
+		}
+	}
+
+	return bounceSubjectPattern.MatchString(e.Subject) && strings.TrimSpace(e.Header.Get("Return-Path")) == "<>"
+}
+
+// bouncedRecipient returns the first address in text other than the bounce sender and receiver
+func bouncedRecipient(e Email, text string) string {
+	skip := map[string]bool{}
+	for _, list := range [][]*mail.Address{e.From, e.To} {
+		for _, a := range list {
+			skip[strings.ToLower(a.Address)] = true
+		}
+	}
+
+	for _, a := range bodyAddressPattern.FindAllString(text, -1) {
+		if !skip[strings.ToLower(a)] {
+			return a
+		}
+	}
+
+	return ""
+}
+
+// isAutoReply reports whether e was sent automatically in reply to another message,
+// either per RFC 3834 or by the out-of-office heuristics
+func isAutoReply(e Email) bool {
+	return e.LikelyAutoReply || strings.HasPrefix(strings.ToLower(strings.TrimSpace(e.Header.Get("Auto-Submitted"))), "auto-replied")
+}
@@ -0,0 +1,107 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyBounce(t *testing.T) {
+	dsn := func(status, diagnostic string) string {
+		return "From: MAILER-DAEMON@mx.example.net\nTo: sender@example.com\nContent-Type: multipart/report; report-type=delivery-status; boundary=b\n\n" +
+			"--b\nContent-Type: text/plain\n\nFailed.\n--b\nContent-Type: message/delivery-status\n\nReporting-MTA: dns; mx.example.net\n\n" +
+			"Final-Recipient: rfc822; jdoe@example.org\nAction: failed\nStatus: " + status + "\nDiagnostic-Code: smtp; " + diagnostic + "\n\n" +
+			"Final-Recipient: rfc822; ok@example.org\nAction: delivered\nStatus: 2.0.0\n--b--\n"
+	}
+
+	var testData = map[int]struct {
+		mailData  string
+		bounces   int
+		kind      BounceType
+		recipient string
+		status    string
+		smtpCode  string
+	}{
+		1: {
+			mailData:  dsn("5.1.1", "550 5.1.1 user unknown"),
+			bounces:   1,
+			kind:      BounceHard,
+			recipient: "jdoe@example.org",
+			status:    "5.1.1",
+			smtpCode:  "550",
+		},
+		2: {
+			mailData:  dsn("4.2.2", "452 4.2.2 mailbox full"),
+			bounces:   1,
+			kind:      BounceSoft,
+			recipient: "jdoe@example.org",
+			status:    "4.2.2",
+			smtpCode:  "452",
+		},
+		3: {
+			mailData:  dsn("5.7.1", "550 5.7.1 message rejected"),
+			bounces:   1,
+			kind:      BounceBlock,
+			recipient: "jdoe@example.org",
+			status:    "5.7.1",
+			smtpCode:  "550",
+		},
+		4: {
+			mailData:  dsn("5.0.0", "554 Your IP is listed at zen.spamhaus.org"),
+			bounces:   1,
+			kind:      BounceBlock,
+			recipient: "jdoe@example.org",
+			status:    "5.0.0",
+			smtpCode:  "554",
+		},
+		5: {
+			mailData: "From: MAILER-DAEMON@mail.example.net\nTo: sender@example.com\nSubject: failure notice\n\n" +
+				"Hi. This is the qmail-send program at mail.example.net.\nI'm afraid I wasn't able to deliver your message to the following addresses.\n\n" +
+				"<jdoe@example.org>:\n192.0.2.1 does not like recipient.\nRemote host said: 550 5.1.1 <jdoe@example.org>: Recipient address rejected\n",
+			bounces:   1,
+			kind:      BounceHard,
+			recipient: "jdoe@example.org",
+			status:    "5.1.1",
+			smtpCode:  "550",
+		},
+		6: {
+			mailData: "From: Mail System <postmaster@example.org>\nTo: sender@example.com\nSubject: Undeliverable: Hello\n\n" +
+				"Delivery has failed to these recipients: jane@example.org\nThe recipient's mailbox is full. 552 Mailbox quota exceeded\n",
+			bounces:   1,
+			kind:      BounceSoft,
+			recipient: "jane@example.org",
+			smtpCode:  "552",
+		},
+		7: {
+			mailData:  "From: jane@example.org\nTo: sender@example.com\nSubject: Out of office\nAuto-Submitted: auto-replied\n\nI'm away.\n",
+			bounces:   1,
+			kind:      BounceAutoReply,
+			recipient: "jane@example.org",
+		},
+		8: {
+			mailData: "From: jane@example.org\nTo: sender@example.com\nSubject: Hello\n\nHi, your 550 page report.\n",
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		bounces := ClassifyBounce(e)
+		if len(bounces) != td.bounces {
+			t.Errorf("[Test Case %v] Wrong number of bounces. Expected: %v, Got: %v", index, td.bounces, bounces)
+			continue
+		}
+
+		if td.bounces == 0 {
+			continue
+		}
+
+		b := bounces[0]
+		if b.Type != td.kind || b.Recipient != td.recipient || b.Status != td.status || b.SMTPCode != td.smtpCode {
+			t.Errorf("[Test Case %v] Wrong bounce. Expected: %v %s %s %s, Got: %v %s %s %s", index, td.kind, td.recipient, td.status, td.smtpCode, b.Type, b.Recipient, b.Status, b.SMTPCode)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package parsemail
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+const contentTypeTextCalendar = "text/calendar"
+
+// Participation statuses of a calendar attendee (RFC 5545 PARTSTAT)
+const (
+	PartStatNeedsAction = "NEEDS-ACTION"
+	PartStatAccepted    = "ACCEPTED"
+	PartStatDeclined    = "DECLINED"
+	PartStatTentative   = "TENTATIVE"
+	PartStatDelegated   = "DELEGATED"
+)
+
+// CalendarReply is an iTIP reply (a text/calendar part with METHOD:REPLY)
+// sent by an attendee to answer a meeting invitation.
+type CalendarReply struct {
+	UID       string
+	Sequence  int
+	Summary   string
+	Organizer string
+	Attendees []CalendarAttendee
+}
+
+// CalendarAttendee is an attendee of a calendar reply with its participation status
+type CalendarAttendee struct {
+	Address  string
+	Name     string
+	PartStat string
+}
+
+// calendarProperty is a single unfolded iCalendar content line
+type calendarProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseCalendarPart sets CalendarReply of e when the text/calendar part in r is a reply
+func parseCalendarPart(e *Email, r io.Reader, params map[string]string, encoding string, compression string) error {
+	content, err := decodeBodyPart(e, r, encoding, compression)
+	if err != nil {
+		return err
+	}
+
+	if reply := parseCalendarReply(content, params["method"]); reply != nil {
+		e.CalendarReply = reply
+	}
+
+	return nil
+}
+
+// parseCalendarReply returns the first event of an iCalendar object with
+// METHOD:REPLY, or nil if the object isn't a reply. The method parameter of the
+// Content-Type is used when the object doesn't declare one itself.
+func parseCalendarReply(content string, method string) *CalendarReply {
+	var reply *CalendarReply
+	var event *CalendarReply
+	done := false
+
+	for _, prop := range parseCalendarLines(content) {
+		switch {
+		case prop.name == "METHOD" && event == nil:
+			method = prop.value
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT") && !done:
+			event = &CalendarReply{}
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT") && event != nil:
+			reply, event, done = event, nil, true
+		case event == nil:
+		case prop.name == "UID":
+			event.UID = prop.value
+		case prop.name == "SEQUENCE":
+			event.Sequence, _ = strconv.Atoi(prop.value)
+		case prop.name == "SUMMARY":
+			event.Summary = unescapeCalendarText(prop.value)
+		case prop.name == "ORGANIZER":
+			event.Organizer = calendarAddress(prop.value)
+		case prop.name == "ATTENDEE":
+			partStat := strings.ToUpper(prop.params["PARTSTAT"])
+			if partStat == "" {
+				partStat = PartStatNeedsAction
+			}
+
+			event.Attendees = append(event.Attendees, CalendarAttendee{
+				Address:  calendarAddress(prop.value),
+				Name:     prop.params["CN"],
+				PartStat: partStat,
+			})
+		}
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(method), "REPLY") {
+		return nil
+	}
+
+	return reply
+}
+
+// parseCalendarLines unfolds content lines (RFC 5545 section 3.1) and splits them into name, parameters and value
+func parseCalendarLines(content string) (props []calendarProperty) {
+	content = strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(content)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		colon := calendarValueStart(line)
+		if colon == -1 {
+			continue
+		}
+
+		fields := splitCalendarParams(line[:colon])
+		prop := calendarProperty{name: strings.ToUpper(fields[0]), params: map[string]string{}, value: line[colon+1:]}
+		for _, f := range fields[1:] {
+			if i := strings.IndexByte(f, '='); i != -1 {
+				prop.params[strings.ToUpper(f[:i])] = strings.Trim(f[i+1:], `"`)
+			}
+		}
+
+		props = append(props, prop)
+	}
+
+	return
+}
+
+// calendarValueStart returns the index of the colon ending the name and parameters, skipping quoted parameter values
+func calendarValueStart(line string) int {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func splitCalendarParams(s string) (fields []string) {
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				fields = append(fields, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(fields, s[start:])
+}
+
+// calendarAddress returns the address of a CAL-ADDRESS value without the mailto: scheme
+func calendarAddress(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= len("mailto:") && strings.EqualFold(s[:len("mailto:")], "mailto:") {
+		s = s[len("mailto:"):]
+	}
+
+	return s
+}
+
+func unescapeCalendarText(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
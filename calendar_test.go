@@ -0,0 +1,96 @@
+package parsemail
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+const calendarReply = "BEGIN:VCALENDAR\r\nPRODID:-//Example//EN\r\nVERSION:2.0\r\nMETHOD:REPLY\r\nBEGIN:VEVENT\r\n" +
+	"UID:meeting-42@example.com\r\nSEQUENCE:2\r\nSUMMARY:Planning\\, Q3\r\n" +
+	"ORGANIZER;CN=John Doe:mailto:jdoe@example.com\r\n" +
+	"ATTENDEE;PARTSTAT=DECLINED;CN=\"Smith, Mary\":MAILTO:mary@exa\r\n mple.net\r\n" +
+	"END:VEVENT\r\nEND:VCALENDAR\r\n"
+
+func TestCalendarReply(t *testing.T) {
+	var testData = map[int]struct {
+		mailData string
+		reply    *CalendarReply
+	}{
+		1: {
+			mailData: "From: Mary Smith <mary@example.net>\nSubject: Declined: Planning\n" +
+				"Content-Type: multipart/alternative; boundary=b\n\n" +
+				"--b\nContent-Type: text/plain\n\nDeclined.\n" +
+				"--b\nContent-Type: text/calendar; method=REPLY; charset=utf-8\n\n" + calendarReply + "\n--b--\n",
+			reply: &CalendarReply{
+				UID:       "meeting-42@example.com",
+				Sequence:  2,
+				Summary:   "Planning, Q3",
+				Organizer: "jdoe@example.com",
+				Attendees: []CalendarAttendee{{Address: "mary@example.net", Name: "Smith, Mary", PartStat: PartStatDeclined}},
+			},
+		},
+		2: {
+			mailData: "From: Mary Smith <mary@example.net>\nSubject: Accepted: Planning\n" +
+				"Content-Type: multipart/mixed; boundary=b\n\n" +
+				"--b\nContent-Type: text/plain\nContent-Disposition: attachment; filename=note.txt\nContent-Transfer-Encoding: base64\n\nQWNjZXB0ZWQ=\n" +
+				"--b\nContent-Type: text/calendar\nContent-Disposition: attachment; filename=invite.ics\nContent-Transfer-Encoding: base64\n\n" +
+				base64.StdEncoding.EncodeToString([]byte(strings.Replace(calendarReply, "DECLINED", "accepted", 1))) + "\n--b--\n",
+			reply: &CalendarReply{
+				UID:       "meeting-42@example.com",
+				Sequence:  2,
+				Summary:   "Planning, Q3",
+				Organizer: "jdoe@example.com",
+				Attendees: []CalendarAttendee{{Address: "mary@example.net", Name: "Smith, Mary", PartStat: PartStatAccepted}},
+			},
+		},
+		3: {
+			mailData: "From: John Doe <jdoe@example.com>\nSubject: Invitation: Planning\nContent-Type: text/calendar; method=REQUEST\n\n" +
+				strings.Replace(calendarReply, "METHOD:REPLY", "METHOD:REQUEST", 1),
+		},
+		4: {
+			mailData: "From: Mary Smith <mary@example.net>\nSubject: Tentative: Planning\nContent-Type: text/calendar; method=REPLY\n\n" +
+				"BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nATTENDEE;PARTSTAT=TENTATIVE:mailto:mary@example.net\nEND:VEVENT\nEND:VCALENDAR\n",
+			reply: &CalendarReply{
+				UID:       "1",
+				Attendees: []CalendarAttendee{{Address: "mary@example.net", PartStat: PartStatTentative}},
+			},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if td.reply == nil {
+			if e.CalendarReply != nil {
+				t.Errorf("[Test Case %v] Expected no calendar reply, Got: %+v", index, e.CalendarReply)
+			}
+			continue
+		}
+
+		if e.CalendarReply == nil {
+			t.Errorf("[Test Case %v] Missing calendar reply", index)
+			continue
+		}
+
+		r := e.CalendarReply
+		if r.UID != td.reply.UID || r.Sequence != td.reply.Sequence || r.Summary != td.reply.Summary || r.Organizer != td.reply.Organizer {
+			t.Errorf("[Test Case %v] Wrong calendar reply. Expected: %+v, Got: %+v", index, td.reply, r)
+		}
+
+		if len(r.Attendees) != len(td.reply.Attendees) {
+			t.Errorf("[Test Case %v] Wrong attendees. Expected: %+v, Got: %+v", index, td.reply.Attendees, r.Attendees)
+			continue
+		}
+
+		for i, a := range td.reply.Attendees {
+			if r.Attendees[i] != a {
+				t.Errorf("[Test Case %v] Wrong attendee %v. Expected: %+v, Got: %+v", index, i, a, r.Attendees[i])
+			}
+		}
+	}
+}
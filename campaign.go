@@ -0,0 +1,63 @@
+package parsemail
+
+import (
+	"net/textproto"
+	"strings"
+)
+
+// campaignHeaders are the header fields email service providers use to tag campaigns and mailings
+var campaignHeaders = []string{
+	"Feedback-ID",
+	"X-Campaign",
+	"X-Campaign-ID",
+	"X-CampaignID",
+	"X-Mailgun-Tag",
+	"X-Mailgun-Campaign-ID",
+	"X-Mailgun-Variables",
+	"X-MC-User",
+	"X-Mandrill-User",
+	"X-SG-EID",
+	"X-SMTPAPI",
+	"X-SES-Configuration-Set",
+	"X-PM-Tag",
+	"X-MSYS-API",
+	"X-SFMC-Stack",
+	"X-Job",
+	"X-Mailer-RecptId",
+	"X-Report-Abuse",
+}
+
+// parseCampaign collects the campaign headers present into a map keyed by the
+// lower case header name. Multiple values of a field are joined with ", ". The
+// Feedback-ID is additionally split into its "feedback-id.sender" part and the
+// remaining "feedback-id.identifiers".
+func (hp headerParser) parseCampaign() map[string]string {
+	var campaign map[string]string
+	for _, name := range campaignHeaders {
+		var values []string
+		for _, v := range (*hp.header)[textproto.CanonicalMIMEHeaderKey(name)] {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+
+		if campaign == nil {
+			campaign = map[string]string{}
+		}
+		campaign[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+
+	if fid, ok := campaign["feedback-id"]; ok {
+		parts := strings.Split(fid, ":")
+		campaign["feedback-id.sender"] = parts[len(parts)-1]
+		if len(parts) > 1 {
+			campaign["feedback-id.identifiers"] = strings.Join(parts[:len(parts)-1], ":")
+		}
+	}
+
+	return campaign
+}
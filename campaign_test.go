@@ -0,0 +1,39 @@
+package parsemail
+
+import (
+	"net/mail"
+	"testing"
+)
+
+func TestParseCampaign(t *testing.T) {
+	hp := headerParser{header: &mail.Header{"Subject": {"Hello"}}}
+	if c := hp.parseCampaign(); c != nil {
+		t.Errorf("Expected no campaign metadata, got %v", c)
+	}
+
+	header := mail.Header{
+		"Feedback-Id":   {"spring-sale:customer42:newsletter:esp-sender"},
+		"X-Mailgun-Tag": {"newsletter", "spring"},
+		"X-Campaign":    {" 2019-Q2 "},
+	}
+	hp = headerParser{header: &header}
+	c := hp.parseCampaign()
+
+	expected := map[string]string{
+		"feedback-id":             "spring-sale:customer42:newsletter:esp-sender",
+		"feedback-id.sender":      "esp-sender",
+		"feedback-id.identifiers": "spring-sale:customer42:newsletter",
+		"x-mailgun-tag":           "newsletter, spring",
+		"x-campaign":              "2019-Q2",
+	}
+
+	if len(c) != len(expected) {
+		t.Errorf("Wrong campaign metadata. Expected: %v, Got: %v", expected, c)
+	}
+
+	for k, v := range expected {
+		if c[k] != v {
+			t.Errorf("Wrong %s. Expected: '%s', Got: '%s'", k, v, c[k])
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package parsemail
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// CharsetDecoderFunc converts text encoded in a charset to UTF-8
+type CharsetDecoderFunc func(data []byte) (string, error)
+
+// CharsetPrecedence selects which charset an HTML body is decoded from when
+// its <meta> charset disagrees with the charset of its Content-Type, see
+// WithHTMLCharsetPrecedence
+type CharsetPrecedence int
+
+const (
+	// KeepHTMLCharset leaves HTML bodies in the charset they were sent in, this is the default
+	KeepHTMLCharset CharsetPrecedence = iota
+	// PreferMIMECharset decodes from the Content-Type charset, falling back to the <meta> charset
+	PreferMIMECharset
+	// PreferMetaCharset decodes from the <meta> charset, falling back to the Content-Type charset
+	PreferMetaCharset
+)
+
+// metaCharsetPattern matches both <meta charset> and <meta http-equiv> charset declarations
+var metaCharsetPattern = regexp.MustCompile(`(?is)<meta\s[^>]*?charset\s*=\s*["']?\s*([-\w.:]+)`)
+
+// metaCharsetPrescanSize is the number of bytes browsers search for a <meta> charset
+const metaCharsetPrescanSize = 1024
+
+// charsetAliases maps common alternative charset labels to their canonical name
+var charsetAliases = map[string]string{
+	"utf8":       "utf-8",
+	"ascii":      "us-ascii",
+	"latin1":     "iso-8859-1",
+	"l1":         "iso-8859-1",
+	"iso8859-1":  "iso-8859-1",
+	"iso_8859-1": "iso-8859-1",
+	"cp1252":     "windows-1252",
+	"x-cp1252":   "windows-1252",
+}
+
+var (
+	charsetDecodersMu sync.RWMutex
+	charsetDecoders   = map[string]CharsetDecoderFunc{
+		"utf-8":        decodeUTF8,
+		"us-ascii":     decodeASCII,
+		"iso-8859-1":   decodeLatin1,
+		"windows-1252": decodeWindows1252,
+	}
+)
+
+// windows1252 holds the code points of the bytes 0x80 to 0x9f in windows-1252,
+// undefined bytes map to the C1 control with the same value
+var windows1252 = [32]rune{
+	0x20ac, 0x81, 0x201a, 0x0192, 0x201e, 0x2026, 0x2020, 0x2021, 0x02c6, 0x2030, 0x0160, 0x2039, 0x0152, 0x8d, 0x017d, 0x8f,
+	0x90, 0x2018, 0x2019, 0x201c, 0x201d, 0x2022, 0x2013, 0x2014, 0x02dc, 0x2122, 0x0161, 0x203a, 0x0153, 0x9d, 0x017e, 0x0178,
+}
+
+// RegisterCharsetDecoder registers fn for HTML bodies in charset. utf-8,
+// us-ascii, iso-8859-1 and windows-1252 are registered by default. Registering
+// a nil fn removes the decoder.
+func RegisterCharsetDecoder(charset string, fn CharsetDecoderFunc) {
+	charsetDecodersMu.Lock()
+	defer charsetDecodersMu.Unlock()
+
+	charset = normalizeCharset(charset)
+	if fn == nil {
+		delete(charsetDecoders, charset)
+		return
+	}
+
+	charsetDecoders[charset] = fn
+}
+
+// reconcileHTMLCharset records a disagreement of the <meta> charset of html
+// with the Content-Type charset in e.Warnings and, unless the precedence is
+// KeepHTMLCharset, returns html decoded to UTF-8 with its <meta> charset updated
+func reconcileHTMLCharset(e *Email, html, mimeCharset string, o *options) string {
+	mimeCharset = normalizeCharset(mimeCharset)
+
+	prescan := html
+	if len(prescan) > metaCharsetPrescanSize {
+		prescan = prescan[:metaCharsetPrescanSize]
+	}
+
+	var metaCharset string
+	loc := metaCharsetPattern.FindStringSubmatchIndex(prescan)
+	if loc != nil {
+		metaCharset = normalizeCharset(html[loc[2]:loc[3]])
+	}
+
+	if metaCharset != "" && mimeCharset != "" && metaCharset != mimeCharset {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("HTML meta charset %s disagrees with Content-Type charset %s", metaCharset, mimeCharset))
+	}
+
+	candidates := []string{mimeCharset, metaCharset}
+	switch o.htmlCharsetPrecedence {
+	case KeepHTMLCharset:
+		return html
+	case PreferMetaCharset:
+		candidates = []string{metaCharset, mimeCharset}
+	}
+
+	for _, charset := range candidates {
+		if charset == "" {
+			continue
+		}
+
+		decoded, err := o.registry.decodeCharset([]byte(html), charset)
+		if err != nil {
+			e.Warnings = append(e.Warnings, fmt.Sprintf("Can't decode HTML body from %s: %v", charset, err))
+			continue
+		}
+
+		if m := metaCharsetPattern.FindStringSubmatchIndex(decoded); m != nil {
+			decoded = decoded[:m[2]] + "utf-8" + decoded[m[3]:]
+		}
+
+		return decoded
+	}
+
+	return html
+}
+
+func (reg *registry) decodeCharset(data []byte, charset string) (string, error) {
+	fn, ok := reg.charsetDecoder(charset)
+	if !ok {
+		return "", fmt.Errorf("Unsupported charset")
+	}
+
+	return fn(data)
+}
+
+// charsetDecoder returns the decoder registered for charset, reg may be nil
+func (reg *registry) charsetDecoder(charset string) (CharsetDecoderFunc, bool) {
+	if reg != nil {
+		if fn, ok := reg.charsetDecoders[charset]; ok {
+			return fn, fn != nil
+		}
+	}
+
+	charsetDecodersMu.RLock()
+	defer charsetDecodersMu.RUnlock()
+
+	fn, ok := charsetDecoders[charset]
+	return fn, ok
+}
+
+func normalizeCharset(charset string) string {
+	charset = strings.ToLower(strings.Trim(strings.TrimSpace(charset), `"'`))
+	if canonical, ok := charsetAliases[charset]; ok {
+		return canonical
+	}
+
+	return charset
+}
+
+func decodeUTF8(data []byte) (string, error) {
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("Invalid UTF-8")
+	}
+
+	return string(data), nil
+}
+
+func decodeASCII(data []byte) (string, error) {
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return "", fmt.Errorf("Invalid US-ASCII byte 0x%02x", b)
+		}
+	}
+
+	return string(data), nil
+}
+
+func decodeLatin1(data []byte) (string, error) {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+
+	return string(runes), nil
+}
+
+func decodeWindows1252(data []byte) (string, error) {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		if b >= 0x80 && b < 0xa0 {
+			runes[i] = windows1252[b-0x80]
+		} else {
+			runes[i] = rune(b)
+		}
+	}
+
+	return string(runes), nil
+}
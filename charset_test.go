@@ -0,0 +1,77 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLCharset(t *testing.T) {
+	var testData = map[int]struct {
+		charset  string
+		html     string
+		options  []Option
+		expected string
+		warnings int
+	}{
+		1: {
+			charset:  "iso-8859-1",
+			html:     "<meta charset=\"utf-8\"><p>Caf\xe9</p>",
+			expected: "<meta charset=\"utf-8\"><p>Caf\xe9</p>",
+			warnings: 1,
+		},
+		2: {
+			charset:  "iso-8859-1",
+			html:     "<meta charset=\"utf-8\"><p>Caf\xe9</p>",
+			options:  []Option{WithHTMLCharsetPrecedence(PreferMIMECharset)},
+			expected: "<meta charset=\"utf-8\"><p>Café</p>",
+			warnings: 1,
+		},
+		3: {
+			charset:  "iso-8859-1",
+			html:     "<meta charset=\"utf-8\"><p>Caf\xe9</p>",
+			options:  []Option{WithHTMLCharsetPrecedence(PreferMetaCharset)},
+			expected: "<meta charset=\"utf-8\"><p>Café</p>",
+			warnings: 2,
+		},
+		4: {
+			charset:  "utf-8",
+			html:     "<meta http-equiv=\"Content-Type\" content=\"text/html; charset=windows-1252\"><p>\x93Hi\x94</p>",
+			options:  []Option{WithHTMLCharsetPrecedence(PreferMetaCharset)},
+			expected: "<meta http-equiv=\"Content-Type\" content=\"text/html; charset=utf-8\"><p>“Hi”</p>",
+			warnings: 1,
+		},
+		5: {
+			charset:  "UTF8",
+			html:     "<meta charset='utf-8'><p>Café</p>",
+			options:  []Option{WithHTMLCharsetPrecedence(PreferMIMECharset)},
+			expected: "<meta charset='utf-8'><p>Café</p>",
+		},
+		6: {
+			html:     "<meta charset=latin1><p>Caf\xe9</p>",
+			options:  []Option{WithHTMLCharsetPrecedence(PreferMIMECharset)},
+			expected: "<meta charset=utf-8><p>Café</p>",
+		},
+	}
+
+	for index, td := range testData {
+		contentType := "text/html"
+		if td.charset != "" {
+			contentType += "; charset=" + td.charset
+		}
+
+		mailData := "From: jdoe@machine.example\nContent-Type: " + contentType + "\n\n" + td.html
+		e, err := ParseWithOptions(strings.NewReader(mailData), td.options...)
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if e.HTMLBody != td.expected {
+			t.Errorf("[Test Case %v] Wrong html body. Expected: %q, Got: %q", index, td.expected, e.HTMLBody)
+		}
+
+		if len(e.Warnings) != td.warnings {
+			t.Errorf("[Test Case %v] Wrong number of warnings. Expected: %v, Got: %v", index, td.warnings, e.Warnings)
+		}
+	}
+}
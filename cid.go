@@ -0,0 +1,115 @@
+package parsemail
+
+import (
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// cssCIDPattern matches url(cid:...) references in CSS
+var cssCIDPattern = regexp.MustCompile(`(?i)url\(\s*(['"]?)cid:([^'")\s]+)(['"]?)\s*\)`)
+
+// DataURI returns the embedded file as a base64 data: URI
+func (ef EmbeddedFile) DataURI() (string, error) {
+	sra, ok := ef.Data.(sizedReaderAt)
+	if !ok {
+		return "", ErrNotSeekable
+	}
+
+	data, err := ioutil.ReadAll(io.NewSectionReader(sra, 0, sra.Size()))
+	if err != nil {
+		return "", err
+	}
+
+	contentType, _, err := mime.ParseMediaType(ef.ContentType)
+	if err != nil {
+		contentType = "application/octet-stream"
+	}
+
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// InlineCIDs returns HTMLBody with its cid: references to EmbeddedFiles, in
+// attributes and CSS, replaced by the URL returned by urlFor for the file, so
+// the HTML renders standalone. With a nil urlFor the files are inlined as
+// data: URIs. References to unknown content ids are left as they are.
+func (e Email) InlineCIDs(urlFor func(EmbeddedFile) string) (string, error) {
+	files := map[string]EmbeddedFile{}
+	for _, ef := range e.EmbeddedFiles {
+		files[ef.CID] = ef
+	}
+
+	replaced := map[string]string{}
+	var err error
+	resolve := func(ref string) (string, bool) {
+		cid := normalizeCID(ref)
+		if u, ok := replaced[cid]; ok {
+			return u, true
+		}
+
+		ef, ok := files[cid]
+		if !ok || err != nil {
+			return "", false
+		}
+
+		var u string
+		if urlFor != nil {
+			u = urlFor(ef)
+		} else if u, err = ef.DataURI(); err != nil {
+			return "", false
+		}
+
+		replaced[cid] = u
+		return u, true
+	}
+
+	doc := ParseHTML(e.HTMLBody)
+	doc.Walk(func(n *HTMLNode) bool {
+		switch {
+		case n.Type == HTMLTextNode && n.Parent != nil && n.Parent.Type == HTMLElementNode && n.Parent.Data == "style":
+			n.Data = replaceCSSCIDs(n.Data, resolve)
+		case n.Type == HTMLElementNode:
+			for i, a := range n.Attr {
+				if a.Key == "style" {
+					n.Attr[i].Val = replaceCSSCIDs(a.Val, resolve)
+				} else if v := strings.TrimSpace(a.Val); len(v) > 4 && strings.EqualFold(v[:4], "cid:") {
+					if u, ok := resolve(v[4:]); ok {
+						n.Attr[i].Val = u
+					}
+				}
+			}
+		}
+
+		return true
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return doc.String(), nil
+}
+
+func replaceCSSCIDs(css string, resolve func(string) (string, bool)) string {
+	return cssCIDPattern.ReplaceAllStringFunc(css, func(m string) string {
+		sub := cssCIDPattern.FindStringSubmatch(m)
+		if u, ok := resolve(sub[2]); ok {
+			return "url(" + sub[1] + u + sub[3] + ")"
+		}
+
+		return m
+	})
+}
+
+// normalizeCID returns the content id a cid: URL refers to, which is URL encoded (RFC 2392)
+func normalizeCID(ref string) string {
+	if unescaped, err := url.PathUnescape(ref); err == nil {
+		ref = unescaped
+	}
+
+	return strings.Trim(ref, "<>")
+}
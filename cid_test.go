@@ -0,0 +1,47 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInlineCIDs(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/related; boundary=b\n\n" +
+		"--b\nContent-Type: text/html\n\n" +
+		`<p style="background: url('cid:bg@example.com')"><img src="cid:logo%40example.com"><img src="cid:missing@example.com"></p>` + "\n" +
+		"--b\nContent-Type: image/png; name=logo.png\nContent-Transfer-Encoding: base64\nContent-ID: <logo@example.com>\n\naGVsbG8=\n" +
+		"--b\nContent-Type: image/gif\nContent-Transfer-Encoding: base64\nContent-ID: <bg@example.com>\n\nR0lG\n" +
+		"--b--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html, err := e.InlineCIDs(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<p style="background: url(&#39;data:image/gif;base64,R0lG&#39;)"><img src="data:image/png;base64,aGVsbG8="><img src="cid:missing@example.com"></p>`
+	if !strings.HasPrefix(html, expected) {
+		t.Errorf("Wrong inlined HTML. Expected: %s, Got: %s", expected, html)
+	}
+
+	html, err = e.InlineCIDs(func(ef EmbeddedFile) string {
+		return "/parts/" + ef.CID
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `<p style="background: url(&#39;/parts/bg@example.com&#39;)"><img src="/parts/logo@example.com">`
+	if !strings.HasPrefix(html, expected) {
+		t.Errorf("Wrong rewritten HTML. Expected: %s, Got: %s", expected, html)
+	}
+
+	// the embedded files are left readable
+	if ef := e.EmbeddedFiles[0]; ef.Data.(sizedReaderAt).Size() != 5 {
+		t.Errorf("Wrong embedded file size: %v", ef.Data.(sizedReaderAt).Size())
+	}
+}
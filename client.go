@@ -0,0 +1,51 @@
+package parsemail
+
+import (
+	"net/textproto"
+	"strings"
+)
+
+// clientFingerprintHeaders are header fields, besides X-Mailer and User-Agent,
+// whose presence or value identifies the composing software
+var clientFingerprintHeaders = []string{
+	"X-Newsreader",
+	"X-MimeOLE",
+	"X-MS-Has-Attach",
+	"X-MS-TNEF-Correlator",
+	"X-MS-Exchange-Organization-AuthAs",
+	"X-Originating-Client",
+	"X-Google-Smtp-Source",
+	"X-Gm-Message-State",
+	"X-Apple-Base-Url",
+	"X-Uniform-Type-Identifier",
+	"X-Client-Info",
+	"X-Php-Originating-Script",
+}
+
+// ClientInfo describes the software that composed a message, from the X-Mailer
+// and User-Agent headers plus other known fingerprinting headers. Fingerprint
+// maps the header names present to their values.
+type ClientInfo struct {
+	Mailer      string
+	UserAgent   string
+	Fingerprint map[string]string
+}
+
+func (hp headerParser) parseClientInfo() (ci ClientInfo) {
+	ci.Mailer = strings.TrimSpace(decodeMimeSentence(hp.header.Get("X-Mailer")))
+	ci.UserAgent = strings.TrimSpace(decodeMimeSentence(hp.header.Get("User-Agent")))
+
+	for _, name := range clientFingerprintHeaders {
+		values, ok := (*hp.header)[textproto.CanonicalMIMEHeaderKey(name)]
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		if ci.Fingerprint == nil {
+			ci.Fingerprint = map[string]string{}
+		}
+		ci.Fingerprint[name] = strings.TrimSpace(values[0])
+	}
+
+	return
+}
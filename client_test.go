@@ -0,0 +1,43 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClientInfo(t *testing.T) {
+	e, err := Parse(strings.NewReader(data2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.12; rv:45.0) Gecko/20100101 Thunderbird/45.8.0"
+	if e.ClientInfo.UserAgent != expected {
+		t.Errorf("Wrong user agent. Expected: '%s', Got: '%s'", expected, e.ClientInfo.UserAgent)
+	}
+
+	e, err = Parse(strings.NewReader(`From: John Doe <jdoe@machine.example>
+X-Mailer: Microsoft Outlook 16.0
+X-MimeOLE: Produced By Microsoft MimeOLE V6.00.2900.2180
+X-MS-Has-Attach: yes
+Date: Fri, 21 Nov 1997 09:55:06 -0600
+
+Hello.
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.ClientInfo.Mailer != "Microsoft Outlook 16.0" {
+		t.Errorf("Wrong mailer: '%s'", e.ClientInfo.Mailer)
+	}
+
+	if e.ClientInfo.UserAgent != "" {
+		t.Errorf("Unexpected user agent: '%s'", e.ClientInfo.UserAgent)
+	}
+
+	if len(e.ClientInfo.Fingerprint) != 2 || e.ClientInfo.Fingerprint["X-MS-Has-Attach"] != "yes" ||
+		e.ClientInfo.Fingerprint["X-MimeOLE"] != "Produced By Microsoft MimeOLE V6.00.2900.2180" {
+		t.Errorf("Wrong fingerprint: %v", e.ClientInfo.Fingerprint)
+	}
+}
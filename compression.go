@@ -0,0 +1,95 @@
+package parsemail
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// DecompressorFunc returns a reader decompressing r
+type DecompressorFunc func(r io.Reader) (io.Reader, error)
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   = map[string]DecompressorFunc{
+		"gzip":    gzipDecompressor,
+		"x-gzip":  gzipDecompressor,
+		"deflate": deflateDecompressor,
+	}
+)
+
+// RegisterDecompressor registers fn for parts carrying the Content-Encoding
+// (not Content-Transfer-Encoding) encoding, as applied by some gateways. gzip
+// and deflate are registered by default. Parts are decompressed after their
+// transfer encoding is decoded, parts with unregistered content encodings are
+// left as they are. Registering a nil fn removes the decompressor.
+func RegisterDecompressor(encoding string, fn DecompressorFunc) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+
+	encoding = strings.ToLower(encoding)
+	if fn == nil {
+		delete(decompressors, encoding)
+		return
+	}
+
+	decompressors[encoding] = fn
+}
+
+// decompressor returns the decompressor registered for coding, reg may be nil
+func (reg *registry) decompressor(coding string) DecompressorFunc {
+	if reg != nil {
+		if fn, ok := reg.decompressors[coding]; ok {
+			return fn
+		}
+	}
+
+	decompressorsMu.RLock()
+	defer decompressorsMu.RUnlock()
+
+	return decompressors[coding]
+}
+
+// decompressPart wraps r with the decompressors for the comma separated Content-Encoding value, last applied first
+func (reg *registry) decompressPart(r io.Reader, contentEncoding string) (io.Reader, error) {
+	if contentEncoding == "" {
+		return r, nil
+	}
+
+	codings := strings.Split(contentEncoding, ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		fn := reg.decompressor(coding)
+		if fn == nil {
+			continue
+		}
+
+		dr, err := fn(r)
+		if err != nil {
+			return nil, fmt.Errorf("Can't decompress %s content: %v", coding, err)
+		}
+		r = dr
+	}
+
+	return r, nil
+}
+
+func gzipDecompressor(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+// deflateDecompressor accepts both zlib wrapped (as in HTTP) and raw deflate streams
+func deflateDecompressor(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+
+	return flate.NewReader(br), nil
+}
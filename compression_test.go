@@ -0,0 +1,122 @@
+package parsemail
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestDecompressParts(t *testing.T) {
+	var gz, zl, raw bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte("Compressed hello."))
+	gw.Close()
+
+	zw := zlib.NewWriter(&zl)
+	zw.Write([]byte("zlib attachment"))
+	zw.Close()
+
+	fw, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	fw.Write([]byte("<p>raw deflate</p>"))
+	fw.Close()
+
+	message := `From: John Doe <jdoe@machine.example>
+Content-Type: multipart/mixed; boundary=outer
+
+--outer
+Content-Type: multipart/alternative; boundary=inner
+
+--inner
+Content-Type: text/plain
+Content-Transfer-Encoding: base64
+Content-Encoding: gzip
+
+` + base64.StdEncoding.EncodeToString(gz.Bytes()) + `
+--inner
+Content-Type: text/html
+Content-Transfer-Encoding: base64
+Content-Encoding: deflate
+
+` + base64.StdEncoding.EncodeToString(raw.Bytes()) + `
+--inner--
+--outer
+Content-Type: text/plain; name="notes.txt"
+Content-Disposition: attachment; filename="notes.txt"
+Content-Transfer-Encoding: base64
+Content-Encoding: deflate
+
+` + base64.StdEncoding.EncodeToString(zl.Bytes()) + `
+--outer--
+`
+
+	e, err := Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.TextBody != "Compressed hello." {
+		t.Errorf("Wrong text body: '%s'", e.TextBody)
+	}
+
+	if e.HTMLBody != "<p>raw deflate</p>" {
+		t.Errorf("Wrong html body: '%s'", e.HTMLBody)
+	}
+
+	if len(e.Attachments) != 1 {
+		t.Fatalf("Wrong number of attachments: %v", len(e.Attachments))
+	}
+
+	if b, _ := ioutil.ReadAll(e.Attachments[0].Data); string(b) != "zlib attachment" {
+		t.Errorf("Wrong attachment data: '%s'", b)
+	}
+}
+
+func TestRegisterDecompressor(t *testing.T) {
+	RegisterDecompressor("X-Upper", func(r io.Reader) (io.Reader, error) {
+		b, err := ioutil.ReadAll(r)
+		return strings.NewReader(strings.ToLower(string(b))), err
+	})
+	defer RegisterDecompressor("x-upper", nil)
+
+	message := "From: John Doe <jdoe@machine.example>\nContent-Type: text/plain\nContent-Encoding: x-upper\n\nHELLO.\n"
+	e, err := Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.TextBody != "hello." {
+		t.Errorf("Wrong text body: '%s'", e.TextBody)
+	}
+
+	message = "From: John Doe <jdoe@machine.example>\nContent-Type: text/plain\nContent-Encoding: gzip\n\nnot gzip\n"
+	if _, err := Parse(strings.NewReader(message)); err == nil {
+		t.Errorf("Expected invalid gzip content to fail")
+	}
+}
+
+func TestSinglePartDecompressionErrors(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: text/plain\nContent-Transfer-Encoding: base64\nContent-Encoding: gzip\n\n" +
+		base64.StdEncoding.EncodeToString([]byte("not gzip data")) + "\n"
+
+	var testData = map[int]struct {
+		options []Option
+		fails   bool
+	}{
+		1: {fails: true},
+		2: {options: []Option{WithCompatLevel(CompatV2)}, fails: true},
+		3: {options: []Option{WithCompatLevel(CompatV1)}},
+	}
+
+	for index, td := range testData {
+		_, err := ParseWithOptions(strings.NewReader(mailData), td.options...)
+		if (err != nil) != td.fails {
+			t.Errorf("[Test Case %v] Wrong result. Expected failure: %v, Got: %v", index, td.fails, err)
+		}
+	}
+}
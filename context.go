@@ -0,0 +1,51 @@
+package parsemail
+
+import (
+	"context"
+	"io"
+)
+
+// ParseContext parses an email message like ParseWithOptions, stopping with
+// ctx.Err() once ctx is done. The context is checked between parts and on
+// every read of the message, so large decodes are interrupted too.
+func ParseContext(ctx context.Context, r io.Reader, opts ...Option) (Email, error) {
+	return parseWithOptions(ctx, r, newOptions(opts))
+}
+
+// ParseContext parses an email message like ParseContext with the options of the Parser
+func (p *Parser) ParseContext(ctx context.Context, r io.Reader) (Email, error) {
+	return parseWithOptions(ctx, r, p.o)
+}
+
+// contextReader fails reads once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return cr.r.Read(p)
+}
+
+// withContext returns r failing reads once ctx is done, r itself for contexts
+// that are never done
+func withContext(ctx context.Context, r io.Reader) io.Reader {
+	if ctx.Done() == nil {
+		return r
+	}
+
+	return contextReader{ctx: ctx, r: r}
+}
+
+// contextErr returns the error of the context e is parsed with, once it is done
+func (e *Email) contextErr() error {
+	if e.ctx == nil {
+		return nil
+	}
+
+	return e.ctx.Err()
+}
@@ -0,0 +1,80 @@
+package parsemail
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowReader returns one byte per read, waiting before each
+type slowReader struct {
+	r     io.Reader
+	delay time.Duration
+}
+
+func (sr slowReader) Read(p []byte) (int, error) {
+	time.Sleep(sr.delay)
+	if len(p) > 1 {
+		p = p[:1]
+	}
+
+	return sr.r.Read(p)
+}
+
+func TestParseContext(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\n\nbody\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\"\nContent-Transfer-Encoding: base64\n\n" +
+		strings.Repeat("aGVsbG8g", 1000) + "\n--m--\n"
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var testData = map[int]struct {
+		ctx      func() (context.Context, context.CancelFunc)
+		reader   func() io.Reader
+		expected error
+	}{
+		1: {
+			ctx:    func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			reader: func() io.Reader { return strings.NewReader(mailData) },
+		},
+		2: {
+			ctx:      func() (context.Context, context.CancelFunc) { return canceled, func() {} },
+			reader:   func() io.Reader { return strings.NewReader(mailData) },
+			expected: context.Canceled,
+		},
+		3: {
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 20*time.Millisecond)
+			},
+			reader:   func() io.Reader { return slowReader{r: strings.NewReader(mailData), delay: time.Millisecond} },
+			expected: context.DeadlineExceeded,
+		},
+	}
+
+	for index, td := range testData {
+		ctx, cancel := td.ctx()
+		e, err := ParseContext(ctx, td.reader())
+		cancel()
+
+		if err != td.expected {
+			t.Errorf("[Test Case %v] Wrong error. Expected: %v, Got: %v", index, td.expected, err)
+		}
+
+		if td.expected == nil && len(e.Attachments) != 1 {
+			t.Errorf("[Test Case %v] Wrong number of attachments. Expected: 1, Got: %v", index, len(e.Attachments))
+		}
+	}
+}
+
+func TestParserContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewParser().ParseContext(ctx, strings.NewReader("Subject: Hi\n\nbody\n")); err != context.Canceled {
+		t.Errorf("Wrong error. Expected: %v, Got: %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,43 @@
+package parsemail
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// ConversationID returns a stable identifier of the conversation the email
+// belongs to, so storage layers can bucket messages without threading them.
+// It is derived from the first of:
+//
+//	the root message id of References
+//	the message id of In-Reply-To
+//	the Message-ID of the email itself, so the root shares the id with its replies
+//	the conversation of the Outlook Thread-Index
+//	the normalized subject
+//
+// Messages that lost their references to the conversation end up in another
+// bucket, use BuildThreads to join them. The result is empty for emails
+// without any of these.
+func (e Email) ConversationID() string {
+	var key string
+	switch {
+	case len(e.References) > 0:
+		key = "id:" + e.References[0]
+	case len(e.InReplyTo) > 0:
+		key = "id:" + e.InReplyTo[0]
+	case e.MessageID != "":
+		key = "id:" + e.MessageID
+	case e.ThreadIndex != nil && e.ThreadIndex.ConversationID() != "":
+		key = "thread-index:" + e.ThreadIndex.ConversationID()
+	default:
+		subject := strings.ToLower(NormalizeSubject(e.Subject))
+		if subject == "" {
+			return ""
+		}
+		key = "subject:" + subject
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
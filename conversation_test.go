@@ -0,0 +1,41 @@
+package parsemail
+
+import "testing"
+
+func TestConversationID(t *testing.T) {
+	root := Email{MessageID: "a@example.com", Subject: "Hello"}
+	reply := Email{MessageID: "b@example.com", InReplyTo: []string{"a@example.com"}, Subject: "Re: Hello"}
+	second := Email{MessageID: "c@example.com", References: []string{"a@example.com", "b@example.com"}, InReplyTo: []string{"b@example.com"}}
+	other := Email{MessageID: "d@example.com", Subject: "Hello"}
+
+	id := root.ConversationID()
+	if len(id) != 32 {
+		t.Errorf("Wrong conversation id: %s", id)
+	}
+
+	if reply.ConversationID() != id || second.ConversationID() != id {
+		t.Errorf("Expected replies in the conversation of the root: %s %s %s", id, reply.ConversationID(), second.ConversationID())
+	}
+
+	if other.ConversationID() == id {
+		t.Errorf("Expected another conversation for an unrelated message")
+	}
+
+	bySubject := Email{Subject: "RE: [list] hello"}
+	if bySubject.ConversationID() != (Email{Subject: "Hello"}).ConversationID() || bySubject.ConversationID() == "" {
+		t.Errorf("Expected messages without ids to be bucketed by normalized subject")
+	}
+
+	ti := parseThreadIndex("AcR19gbhyG9fz2wJRK2bpPF1mWrY5g==")
+	if ti == nil {
+		t.Fatalf("Can't parse Thread-Index")
+	}
+
+	if (Email{ThreadIndex: ti}).ConversationID() == (Email{Subject: "x", ThreadIndex: nil}).ConversationID() {
+		t.Errorf("Expected Thread-Index to be used")
+	}
+
+	if (Email{}).ConversationID() != "" {
+		t.Errorf("Expected empty conversation id for an empty email")
+	}
+}
@@ -0,0 +1,76 @@
+package parsemail
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var quotedMessageIDPattern = regexp.MustCompile(`(?im)^\s*>?\s*Message-ID:\s*<([^<>\s]+@[^<>\s]+)>`)
+
+// BounceMatcher extracts the Message-ID of the sent message a bounce refers
+// to, for looking it up in a sent mail store
+type BounceMatcher struct {
+	// DecodeReturnPath recovers a Message-ID from the address the bounce was
+	// delivered to, for senders encoding their message ids in VERP style
+	// return paths. It is skipped when nil.
+	DecodeReturnPath func(address string) (messageID string, ok bool)
+}
+
+// OriginalMessageID returns the Message-ID of the bounced message, without
+// angle brackets, from the first of:
+//
+//	the returned message or its returned header (see Email.OriginalMessage)
+//	the address the bounce was delivered to, decoded with DecodeReturnPath
+//	the envelope id of the delivery status report, when it holds a message id
+//	a Message-ID header line quoted in the body of non-standard bounces
+func (m BounceMatcher) OriginalMessageID(e Email) (string, bool) {
+	if e.OriginalMessage != nil && e.OriginalMessage.MessageID != "" {
+		return e.OriginalMessage.MessageID, true
+	}
+
+	if ids := splitMessageIDs(e.OriginalHeaders.Get("Message-ID")); len(ids) > 0 {
+		return ids[0], true
+	}
+
+	if m.DecodeReturnPath != nil {
+		for _, address := range bounceDestinations(e) {
+			if id, ok := m.DecodeReturnPath(address); ok && id != "" {
+				return strings.Trim(id, "<>"), true
+			}
+		}
+	}
+
+	if e.DeliveryStatus != nil {
+		if ids := splitMessageIDs(e.DeliveryStatus.OriginalEnvelopeID); len(ids) == 1 && strings.Contains(ids[0], "@") {
+			return ids[0], true
+		}
+	}
+
+	for _, body := range []string{e.TextBody, e.HTMLBody} {
+		if match := quotedMessageIDPattern.FindStringSubmatch(body); match != nil {
+			return match[1], true
+		}
+	}
+
+	return "", false
+}
+
+// bounceDestinations returns the addresses a bounce was delivered to, envelope recipients first
+func bounceDestinations(e Email) (addresses []string) {
+	for _, name := range envelopeRecipientHeaders {
+		for _, v := range e.Header[name] {
+			if v = strings.Trim(strings.TrimSpace(v), "<>"); v != "" {
+				addresses = append(addresses, v)
+			}
+		}
+	}
+
+	for _, list := range [][]*mail.Address{e.To, e.Cc} {
+		for _, a := range list {
+			addresses = append(addresses, a.Address)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,57 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBounceMatcher(t *testing.T) {
+	verp := func(address string) (string, bool) {
+		local, _ := splitAddress(address)
+		if !strings.HasPrefix(local, "bounce-") {
+			return "", false
+		}
+
+		return strings.Replace(strings.TrimPrefix(local, "bounce-"), "=", "@", 1), true
+	}
+
+	var testData = map[int]struct {
+		mailData string
+		matcher  BounceMatcher
+		id       string
+	}{
+		1: {
+			mailData: dsnMessage,
+		},
+		2: {
+			mailData: strings.Replace(dsnMessage, "Subject: Hello\n", "Subject: Hello\nMessage-ID: <sent-1@example.com>\n", 1),
+			id:       "sent-1@example.com",
+		},
+		3: {
+			mailData: strings.Replace(dsnMessage, "To: sender@example.com\n", "To: bounce-sent-2=example.com@example.com\n", 1),
+			matcher:  BounceMatcher{DecodeReturnPath: verp},
+			id:       "sent-2@example.com",
+		},
+		4: {
+			mailData: strings.Replace(dsnMessage, "Original-Envelope-Id: id+3D42", "Original-Envelope-Id: <sent-3@example.com>", 1),
+			id:       "sent-3@example.com",
+		},
+		5: {
+			mailData: "From: MAILER-DAEMON@example.net\nTo: sender@example.com\nSubject: failure notice\n\nSorry.\n\n--- Below this line is a copy of the message.\n\nSubject: Hello\nMessage-ID: <sent-4@example.com>\n",
+			id:       "sent-4@example.com",
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		id, ok := td.matcher.OriginalMessageID(e)
+		if id != td.id || ok != (td.id != "") {
+			t.Errorf("[Test Case %v] Wrong message id. Expected: '%s', Got: '%s' %v", index, td.id, id, ok)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package parsemail
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// ExtractDataURIs moves the data: URI images in src and background attributes
+// of html that decode to at least minSize bytes into embedded files, pointing
+// the attributes to them with cid: URLs. Content ids are derived from the
+// image data, so repeated images share a single file. The HTML is returned
+// unchanged when nothing is extracted.
+func ExtractDataURIs(html string, minSize int) (string, []EmbeddedFile) {
+	var files []EmbeddedFile
+	cids := map[string]bool{}
+
+	doc := ParseHTML(html)
+	doc.Walk(func(n *HTMLNode) bool {
+		if n.Type != HTMLElementNode {
+			return true
+		}
+
+		for i, a := range n.Attr {
+			if a.Key != "src" && a.Key != "background" {
+				continue
+			}
+
+			contentType, data, ok := parseDataURI(a.Val)
+			if !ok || !strings.HasPrefix(contentType, "image/") || len(data) < minSize {
+				continue
+			}
+
+			sum := sha256.Sum256(data)
+			cid := fmt.Sprintf("%x@data-uri.invalid", sum[:8])
+			if !cids[cid] {
+				cids[cid] = true
+				files = append(files, EmbeddedFile{CID: cid, ContentType: contentType, Data: bytes.NewReader(data)})
+			}
+
+			n.Attr[i].Val = "cid:" + cid
+		}
+
+		return true
+	})
+
+	if len(files) == 0 {
+		return html, nil
+	}
+
+	return doc.String(), files
+}
+
+// parseDataURI decodes a data: URI (RFC 2397), the media type defaults to text/plain
+func parseDataURI(s string) (contentType string, data []byte, ok bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 5 || !strings.EqualFold(s[:5], "data:") {
+		return "", nil, false
+	}
+
+	comma := strings.IndexByte(s, ',')
+	if comma == -1 {
+		return "", nil, false
+	}
+
+	meta, payload := s[5:comma], s[comma+1:]
+	isBase64 := false
+	if i := strings.LastIndexByte(meta, ';'); i != -1 && strings.EqualFold(strings.TrimSpace(meta[i+1:]), "base64") {
+		meta, isBase64 = meta[:i], true
+	}
+
+	contentType = "text/plain"
+	if strings.TrimSpace(meta) != "" {
+		mediaType, _, err := mime.ParseMediaType(meta)
+		if err != nil {
+			return "", nil, false
+		}
+		contentType = mediaType
+	}
+
+	var err error
+	if isBase64 {
+		payload = strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+				return -1
+			}
+			return r
+		}, payload)
+		data, err = base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+		}
+	} else {
+		var unescaped string
+		unescaped, err = url.PathUnescape(payload)
+		data = []byte(unescaped)
+	}
+
+	if err != nil {
+		return "", nil, false
+	}
+
+	return contentType, data, true
+}
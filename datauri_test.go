@@ -0,0 +1,58 @@
+package parsemail
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestExtractDataURIs(t *testing.T) {
+	html := `<p><img src="data:image/png;base64,aGVsbG8gd29ybGQ="><img src="data:image/gif;base64,R0lG">` +
+		`<td background="data:image/png;base64,aGVsbG8gd29ybGQ="></td><img src="data:text/plain,hello%20world!"></p>`
+
+	got, files := ExtractDataURIs(html, 8)
+	if len(files) != 1 {
+		t.Fatalf("Wrong number of files: %+v", files)
+	}
+
+	ef := files[0]
+	if ef.ContentType != "image/png" || !strings.HasSuffix(ef.CID, "@data-uri.invalid") {
+		t.Errorf("Wrong embedded file: %+v", ef)
+	}
+
+	data, _ := ioutil.ReadAll(ef.Data)
+	if string(data) != "hello world" {
+		t.Errorf("Wrong embedded file data: %q", data)
+	}
+
+	expected := `<p><img src="cid:` + ef.CID + `"><img src="data:image/gif;base64,R0lG"><td background="cid:` + ef.CID + `"></td><img src="data:text/plain,hello%20world!"></p>`
+	if got != expected {
+		t.Errorf("Wrong HTML. Expected: %s, Got: %s", expected, got)
+	}
+
+	if got, files := ExtractDataURIs(html, 1024); got != html || len(files) != 0 {
+		t.Errorf("Expected unchanged HTML, Got: %s, %+v", got, files)
+	}
+}
+
+func TestDataURIExtraction(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: text/html\n\n<img src=\"data:image/png;base64,aGVsbG8gd29ybGQ=\">\n"
+
+	e, err := ParseWithOptions(strings.NewReader(mailData), WithDataURIExtraction(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.EmbeddedFiles) != 1 || !strings.HasPrefix(e.HTMLBody, `<img src="cid:`+e.EmbeddedFiles[0].CID+`">`) {
+		t.Errorf("Data URI not extracted: %q, %+v", e.HTMLBody, e.EmbeddedFiles)
+	}
+
+	inlined, err := e.InlineCIDs(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(inlined, `<img src="data:image/png;base64,aGVsbG8gd29ybGQ=">`) {
+		t.Errorf("Wrong inlined HTML: %s", inlined)
+	}
+}
@@ -0,0 +1,184 @@
+package parsemail
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dateLayout is the layout dates are normalized to by parseObsoleteDate
+const dateLayout = "2 Jan 2006 15:04:05 -0700"
+
+// DateParserFunc parses the value of a date header field
+type DateParserFunc func(s string) (time.Time, error)
+
+var (
+	dateLayoutsMu sync.RWMutex
+	dateLayouts   []string
+)
+
+// RegisterDateLayout adds a time.Parse layout tried for date header fields
+// (Date, Resent-Date and the dates of delivery status reports) the built-in
+// parsing fails on. Layouts are tried in registration order.
+func RegisterDateLayout(layout string) {
+	dateLayoutsMu.Lock()
+	defer dateLayoutsMu.Unlock()
+
+	dateLayouts = append(dateLayouts, layout)
+}
+
+// parseRegisteredDate parses s with the layouts added by WithDateLayout, then
+// with those added by RegisterDateLayout. reg may be nil.
+func (reg *registry) parseRegisteredDate(s string) (time.Time, bool) {
+	var layouts []string
+	if reg != nil {
+		layouts = reg.dateLayouts
+	}
+
+	dateLayoutsMu.RLock()
+	layouts = append(layouts[:len(layouts):len(layouts)], dateLayouts...)
+	dateLayoutsMu.RUnlock()
+
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// zoneOffsets maps the obsolete zone names of RFC 5322 section 4.3 and some
+// common abbreviations to their offsets
+var zoneOffsets = map[string]string{
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"Z":    "+0000",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"BST":  "+0100",
+	"JST":  "+0900",
+}
+
+// parseObsoleteDate parses the date formats found in real world Date headers which
+// aren't valid RFC 5322 date-times: obsolete zone names, two and three digit
+// years, missing seconds, missing zones, comments, extra whitespace and the
+// asctime form ("Mon Jan 2 15:04:05 2006"). Military zones and missing zones
+// are treated as UTC, as RFC 5322 recommends for -0000.
+func parseObsoleteDate(s string) (time.Time, error) {
+	original := s
+	s, _ = splitComments(s)
+	fields := strings.Fields(strings.Replace(s, ",", " ", -1))
+	if len(fields) > 0 && isDayName(fields[0]) {
+		fields = fields[1:]
+	}
+
+	// asctime: month day time year [zone]
+	if len(fields) >= 4 && monthName(fields[0]) != "" && strings.Contains(fields[2], ":") {
+		fields = append([]string{fields[1], fields[0], fields[3], fields[2]}, fields[4:]...)
+	}
+
+	if len(fields) < 4 {
+		return time.Time{}, fmt.Errorf("Malformed date: %s", original)
+	}
+
+	month := monthName(fields[1])
+	year, err := normalizeYear(fields[2])
+	if month == "" || err != nil {
+		return time.Time{}, fmt.Errorf("Malformed date: %s", original)
+	}
+
+	clock := fields[3]
+	if strings.Count(clock, ":") == 1 {
+		clock += ":00"
+	}
+
+	zone := "+0000"
+	if len(fields) > 4 {
+		if zone = normalizeZone(fields[4]); zone == "" {
+			return time.Time{}, fmt.Errorf("Unknown time zone in date: %s", original)
+		}
+	}
+
+	return time.Parse(dateLayout, strings.Join([]string{fields[0], month, year, clock, zone}, " "))
+}
+
+func isDayName(s string) bool {
+	if len(s) < 3 {
+		return false
+	}
+
+	switch strings.ToLower(s[:3]) {
+	case "mon", "tue", "wed", "thu", "fri", "sat", "sun":
+		return true
+	}
+
+	return false
+}
+
+// monthName returns the three letter name of a month given by its short or full name
+func monthName(s string) string {
+	if len(s) < 3 {
+		return ""
+	}
+
+	for m := time.January; m <= time.December; m++ {
+		name := m.String()
+		if strings.EqualFold(s, name) || strings.EqualFold(s, name[:3]) {
+			return name[:3]
+		}
+	}
+
+	return ""
+}
+
+// normalizeYear returns the four digit year of RFC 5322 section 4.3: two
+// digit years below 50 are in the 2000s, other two and three digit years are
+// offset from 1900
+func normalizeYear(s string) (string, error) {
+	year, err := strconv.Atoi(s)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case len(s) == 2 && year < 50:
+		year += 2000
+	case len(s) <= 3:
+		year += 1900
+	}
+
+	return strconv.Itoa(year), nil
+}
+
+// normalizeZone returns the numeric form of a zone, or "" for unknown zones
+func normalizeZone(s string) string {
+	if offset, ok := zoneOffsets[strings.ToUpper(s)]; ok {
+		return offset
+	}
+
+	if len(s) == 1 && isASCIILetter(s[0]) {
+		return "+0000"
+	}
+
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		digits := strings.Replace(s[1:], ":", "", 1)
+		if _, err := strconv.Atoi(digits); err == nil && len(digits) == 4 {
+			return s[:1] + digits
+		}
+	}
+
+	return ""
+}
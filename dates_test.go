@@ -0,0 +1,95 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	var testData = map[int]struct {
+		date     string
+		expected time.Time
+		fails    bool
+	}{
+		1:  {date: "Fri, 21 Nov 1997 09:55:06 -0600", expected: time.Date(1997, 11, 21, 15, 55, 6, 0, time.UTC)},
+		2:  {date: "21 Nov 97 09:55:06 GMT", expected: time.Date(1997, 11, 21, 9, 55, 6, 0, time.UTC)},
+		3:  {date: "Thu, 13 Feb 1969 23:32 -0330", expected: time.Date(1969, 2, 14, 3, 2, 0, 0, time.UTC)},
+		4:  {date: "Mon,  4 Mar 2019   10:00:00  EST", expected: time.Date(2019, 3, 4, 15, 0, 0, 0, time.UTC)},
+		5:  {date: "Mon, 4 Mar 2019 10:00:00 +0000 (UTC)", expected: time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)},
+		6:  {date: "4 Mar 19 10:00:00 UT", expected: time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)},
+		7:  {date: "Thu, 1 Jan 103 00:00:00 +0000", expected: time.Date(2003, 1, 1, 0, 0, 0, 0, time.UTC)},
+		8:  {date: "Mon Mar  4 10:00:00 2019", expected: time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)},
+		9:  {date: "Monday, 4 March 2019 10:00:00 +01:00", expected: time.Date(2019, 3, 4, 9, 0, 0, 0, time.UTC)},
+		10: {date: "4 Mar 2019 10:00:00 PDT", expected: time.Date(2019, 3, 4, 17, 0, 0, 0, time.UTC)},
+		11: {date: "4 Mar 2019 10:00:00 A", expected: time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)},
+		12: {date: "yesterday", fails: true},
+		13: {date: "4 Foo 2019 10:00:00 +0000", fails: true},
+		14: {date: "4 Mar 2019 10:00:00 XYZT", fails: true},
+	}
+
+	for index, td := range testData {
+		d, err := parseObsoleteDate(td.date)
+		if (err != nil) != td.fails {
+			t.Errorf("[Test Case %v] Wrong result. Expected failure: %v, Got: %v", index, td.fails, err)
+			continue
+		}
+
+		if !td.fails && !d.Equal(td.expected) {
+			t.Errorf("[Test Case %v] Wrong date. Expected: %v, Got: %v", index, td.expected, d.UTC())
+		}
+	}
+}
+
+func TestMalformedDateWarning(t *testing.T) {
+	e, err := Parse(strings.NewReader("From: a@example.com\nDate: 21 Nov 97 09:55 EST\n\nbody\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !e.Date.Equal(time.Date(1997, 11, 21, 14, 55, 0, 0, time.UTC)) || len(e.Warnings) != 0 {
+		t.Errorf("Wrong date: %v %v", e.Date, e.Warnings)
+	}
+
+	e, _ = Parse(strings.NewReader("From: a@example.com\nDate: sometime\n\nbody\n"))
+	if !e.Date.IsZero() || len(e.Warnings) != 1 {
+		t.Errorf("Expected zero date with a warning, got: %v %v", e.Date, e.Warnings)
+	}
+}
+
+func TestCustomDateParsing(t *testing.T) {
+	message := "From: a@example.com\nDate: 2019-03-04T10:00:00Z\nResent-Date: 04.03.2019 11:30\n\nbody\n"
+
+	e, _ := Parse(strings.NewReader(message))
+	if !e.Date.IsZero() || len(e.Warnings) != 2 {
+		t.Errorf("Expected unparsable dates without custom layouts, got: %v %v", e.Date, e.Warnings)
+	}
+
+	RegisterDateLayout(time.RFC3339)
+	defer func() {
+		dateLayoutsMu.Lock()
+		dateLayouts = nil
+		dateLayoutsMu.Unlock()
+	}()
+
+	germanDate := func(s string) (time.Time, error) {
+		return time.Parse("02.01.2006 15:04", s)
+	}
+
+	e, err := ParseWithOptions(strings.NewReader(message), WithDateParser(germanDate))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !e.Date.Equal(time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Wrong date with registered layout: %v", e.Date)
+	}
+
+	if len(e.ResentBlocks) != 1 || !e.ResentBlocks[0].Date.Equal(time.Date(2019, 3, 4, 11, 30, 0, 0, time.UTC)) {
+		t.Errorf("Wrong resent date with date parser: %v", e.ResentBlocks)
+	}
+
+	if len(e.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", e.Warnings)
+	}
+}
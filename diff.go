@@ -0,0 +1,177 @@
+package parsemail
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// Difference is a single difference between two emails found by Diff. Field
+// names what differs, like "Header Subject", "TextBody" or "Attachment 0
+// data"; A and B are the values in the first and second email. Line is the
+// first differing line (counting from 1) for bodies, 0 otherwise.
+type Difference struct {
+	Field string
+	A     string
+	B     string
+	Line  int
+}
+
+func (d Difference) String() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%s differs from line %d", d.Field, d.Line)
+	}
+
+	return fmt.Sprintf("%s: %q != %q", d.Field, d.A, d.B)
+}
+
+// Diff compares the header fields, bodies, attachments and embedded files of
+// two emails and returns their differences, in a stable order. Header fields
+// listed in ignoreHeaders, like Received, aren't compared. Attachment data is
+// compared by SHA-256, which requires random access to it (see
+// Attachment.ReadAt), otherwise ErrNotSeekable is returned.
+func Diff(a, b Email, ignoreHeaders ...string) (diffs []Difference, err error) {
+	ignored := map[string]bool{}
+	for _, name := range ignoreHeaders {
+		ignored[textproto.CanonicalMIMEHeaderKey(name)] = true
+	}
+
+	names := map[string]bool{}
+	for name := range a.Header {
+		names[name] = true
+	}
+	for name := range b.Header {
+		names[name] = true
+	}
+
+	var sorted []string
+	for name := range names {
+		if !ignored[textproto.CanonicalMIMEHeaderKey(name)] {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		va, vb := strings.Join(a.Header[name], "\n"), strings.Join(b.Header[name], "\n")
+		if va != vb {
+			diffs = append(diffs, Difference{Field: "Header " + name, A: va, B: vb})
+		}
+	}
+
+	if line := firstDifferingLine(a.TextBody, b.TextBody); line > 0 {
+		diffs = append(diffs, Difference{Field: "TextBody", A: a.TextBody, B: b.TextBody, Line: line})
+	}
+
+	if line := firstDifferingLine(a.HTMLBody, b.HTMLBody); line > 0 {
+		diffs = append(diffs, Difference{Field: "HTMLBody", A: a.HTMLBody, B: b.HTMLBody, Line: line})
+	}
+
+	var partsA, partsB []diffPart
+	for _, at := range a.Attachments {
+		partsA = append(partsA, diffPart{at.Filename, at.ContentType, at.Data})
+	}
+	for _, at := range b.Attachments {
+		partsB = append(partsB, diffPart{at.Filename, at.ContentType, at.Data})
+	}
+
+	ad, err := diffParts("Attachment", partsA, partsB)
+	if err != nil {
+		return nil, err
+	}
+	diffs = append(diffs, ad...)
+
+	partsA, partsB = nil, nil
+	for _, ef := range a.EmbeddedFiles {
+		partsA = append(partsA, diffPart{ef.CID, ef.ContentType, ef.Data})
+	}
+	for _, ef := range b.EmbeddedFiles {
+		partsB = append(partsB, diffPart{ef.CID, ef.ContentType, ef.Data})
+	}
+
+	ed, err := diffParts("EmbeddedFile", partsA, partsB)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(diffs, ed...), nil
+}
+
+// diffPart is the compared data of an attachment or embedded file, name is the filename or content id
+type diffPart struct {
+	name        string
+	contentType string
+	data        io.Reader
+}
+
+func diffParts(kind string, a, b []diffPart) (diffs []Difference, err error) {
+	if len(a) != len(b) {
+		diffs = append(diffs, Difference{Field: kind + " count", A: fmt.Sprint(len(a)), B: fmt.Sprint(len(b))})
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		field := fmt.Sprintf("%s %d ", kind, i)
+		if a[i].name != b[i].name {
+			diffs = append(diffs, Difference{Field: field + "name", A: a[i].name, B: b[i].name})
+		}
+
+		if a[i].contentType != b[i].contentType {
+			diffs = append(diffs, Difference{Field: field + "content type", A: a[i].contentType, B: b[i].contentType})
+		}
+
+		ha, err := partDigest(a[i].data)
+		if err != nil {
+			return nil, err
+		}
+
+		hb, err := partDigest(b[i].data)
+		if err != nil {
+			return nil, err
+		}
+
+		if ha != hb {
+			diffs = append(diffs, Difference{Field: field + "data", A: ha, B: hb})
+		}
+	}
+
+	return
+}
+
+// partDigest returns the hex SHA-256 of part data without consuming it
+func partDigest(r io.Reader) (string, error) {
+	sra, ok := r.(sizedReaderAt)
+	if !ok {
+		return "", ErrNotSeekable
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(sra, 0, sra.Size())); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// firstDifferingLine returns the first line (counting from 1) on which a and b differ, or 0 when they are equal
+func firstDifferingLine(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	la, lb := strings.Split(a, "\n"), strings.Split(b, "\n")
+	for i := 0; i < len(la) && i < len(lb); i++ {
+		if la[i] != lb[i] {
+			return i + 1
+		}
+	}
+
+	if len(la) < len(lb) {
+		return len(la) + 1
+	}
+
+	return len(lb) + 1
+}
@@ -0,0 +1,51 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	message := func(subject, received, body, attachment string) Email {
+		e, err := Parse(strings.NewReader("Received: " + received + "\nFrom: jane@example.com\nSubject: " + subject +
+			"\nContent-Type: multipart/mixed; boundary=b\n\n--b\nContent-Type: multipart/alternative; boundary=a\n\n--a\nContent-Type: text/plain\n\n" + body +
+			"\n--a--\n--b\nContent-Type: text/plain\nContent-Disposition: attachment; filename=a.txt\nContent-Transfer-Encoding: base64\n\n" + attachment + "\n--b--\n"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		return e
+	}
+
+	a := message("Report", "by mx1", "Hello\nWorld", "SGVsbG8=")
+	if diffs, err := Diff(a, message("Report", "by mx2", "Hello\nWorld", "SGVsbG8="), "received"); err != nil || len(diffs) != 0 {
+		t.Errorf("Expected no differences, got %v %v", diffs, err)
+	}
+
+	diffs, err := Diff(a, message("Report 2", "by mx1", "Hello\nEveryone", "SGVsbG8h"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		`Header Subject: "Report" != "Report 2"`,
+		"TextBody differs from line 2",
+		`Attachment 0 data: "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969" != "334d016f755cd6dc58c53a86e183882f8ec14f52fb05345887c8a5edd42c87b7"`,
+	}
+
+	if len(diffs) != len(expected) {
+		t.Fatalf("Wrong differences. Expected: %v, Got: %v", expected, diffs)
+	}
+
+	for i, d := range diffs {
+		if d.String() != expected[i] {
+			t.Errorf("Wrong difference %v. Expected: '%s', Got: '%s'", i, expected[i], d.String())
+		}
+	}
+
+	b := a
+	b.Attachments = nil
+	if diffs, _ := Diff(a, b); len(diffs) != 1 || diffs[0].Field != "Attachment count" {
+		t.Errorf("Expected attachment count difference, got %v", diffs)
+	}
+}
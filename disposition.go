@@ -0,0 +1,102 @@
+package parsemail
+
+import (
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	headerContentDisposition = "Content-Disposition"
+	headerContentDescription = "Content-Description"
+)
+
+// descriptionFilenamePattern matches Content-Description values that are file names, as set by some clients
+var descriptionFilenamePattern = regexp.MustCompile(`^[^/\\:*?"<>|]+\.[A-Za-z0-9]{1,8}$`)
+
+// ContentDisposition is a parsed Content-Disposition header (RFC 2183). Type
+// is the lower case disposition type, like inline or attachment, and Params
+// holds all of its parameters. Size is -1 and the dates are zero when the
+// parameters are absent or malformed.
+type ContentDisposition struct {
+	Type   string
+	Params map[string]string
+
+	Size             int64
+	CreationDate     time.Time
+	ModificationDate time.Time
+	ReadDate         time.Time
+}
+
+// parseContentDisposition parses a Content-Disposition value, keeping the type of
+// values with malformed parameters. Dates also try the layouts of reg, which may be nil.
+func parseContentDisposition(s string, reg *registry) ContentDisposition {
+	cd := ContentDisposition{Size: -1}
+	if strings.TrimSpace(s) == "" {
+		return cd
+	}
+
+	typ, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		cd.Type = strings.ToLower(strings.TrimSpace(strings.Split(s, ";")[0]))
+		return cd
+	}
+
+	cd.Type, cd.Params = typ, params
+	if size, err := strconv.ParseInt(strings.TrimSpace(params["size"]), 10, 64); err == nil && size >= 0 {
+		cd.Size = size
+	}
+
+	cd.CreationDate = parseDispositionDate(params["creation-date"], reg)
+	cd.ModificationDate = parseDispositionDate(params["modification-date"], reg)
+	cd.ReadDate = parseDispositionDate(params["read-date"], reg)
+
+	return cd
+}
+
+// parseDispositionDate parses the RFC 5322 date of a disposition parameter, malformed dates are zero
+func parseDispositionDate(s string, reg *registry) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+
+	if t, err := mail.ParseDate(s); err == nil {
+		return t
+	}
+
+	if t, err := parseObsoleteDate(s); err == nil {
+		return t
+	}
+
+	t, _ := reg.parseRegisteredDate(s)
+	return t
+}
+
+// partFilename returns the filename of the Content-Disposition of part or,
+// for senders that only name the part elsewhere, the name parameter of its
+// Content-Type or a Content-Description that is a file name
+func partFilename(part *multipart.Part) string {
+	if filename := part.FileName(); filename != "" {
+		return filename
+	}
+
+	if _, params, err := mime.ParseMediaType(part.Header.Get(headerContentType)); err == nil && strings.TrimSpace(params["name"]) != "" {
+		return strings.TrimSpace(params["name"])
+	}
+
+	if description := strings.TrimSpace(decodeMimeSentence(part.Header.Get(headerContentDescription))); descriptionFilenamePattern.MatchString(description) {
+		return description
+	}
+
+	return ""
+}
+
+// partContentID returns the Content-ID of part without angle brackets
+func partContentID(part *multipart.Part) string {
+	return strings.Trim(strings.TrimSpace(decodeMimeSentence(part.Header.Get("Content-Id"))), "<>")
+}
@@ -0,0 +1,248 @@
+package parsemail
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseContentDisposition(t *testing.T) {
+	var testData = map[int]struct {
+		value        string
+		typ          string
+		filename     string
+		size         int64
+		creationDate time.Time
+		readDate     time.Time
+	}{
+		1: {
+			value:        `attachment; filename="report.pdf"; size=1024; creation-date="Wed, 12 Feb 1997 16:29:51 -0500"; read-date="bogus"`,
+			typ:          "attachment",
+			filename:     "report.pdf",
+			size:         1024,
+			creationDate: time.Date(1997, 2, 12, 16, 29, 51, 0, time.FixedZone("", -5*60*60)),
+		},
+		2: {
+			value: "INLINE",
+			typ:   "inline",
+			size:  -1,
+		},
+		3: {
+			value: "attachment; filename=",
+			typ:   "attachment",
+			size:  -1,
+		},
+		4: {
+			size: -1,
+		},
+	}
+
+	for index, td := range testData {
+		cd := parseContentDisposition(td.value, nil)
+		if cd.Type != td.typ {
+			t.Errorf("[Test Case %v] Wrong type. Expected: %q, Got: %q", index, td.typ, cd.Type)
+		}
+
+		if cd.Params["filename"] != td.filename {
+			t.Errorf("[Test Case %v] Wrong filename. Expected: %q, Got: %q", index, td.filename, cd.Params["filename"])
+		}
+
+		if cd.Size != td.size {
+			t.Errorf("[Test Case %v] Wrong size. Expected: %v, Got: %v", index, td.size, cd.Size)
+		}
+
+		if !cd.CreationDate.Equal(td.creationDate) {
+			t.Errorf("[Test Case %v] Wrong creation date. Expected: %v, Got: %v", index, td.creationDate, cd.CreationDate)
+		}
+
+		if !cd.ReadDate.Equal(td.readDate) {
+			t.Errorf("[Test Case %v] Wrong read date. Expected: %v, Got: %v", index, td.readDate, cd.ReadDate)
+		}
+	}
+}
+
+func TestAttachmentDisposition(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\";\n modification-date=\"Thu, 13 Feb 1997 10:00:00 +0000\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cd := e.Attachments[0].Disposition
+	expected := time.Date(1997, 2, 13, 10, 0, 0, 0, time.UTC)
+	if cd.Type != "attachment" || !cd.ModificationDate.Equal(expected) {
+		t.Errorf("Wrong disposition. Expected attachment modified at %v, Got: %+v", expected, cd)
+	}
+}
+
+func TestAttachmentFilenameFallback(t *testing.T) {
+	var testData = map[int]struct {
+		headers  string
+		filename string
+	}{
+		1: {
+			headers:  "Content-Type: application/pdf; name=\"report.pdf\"\nContent-Disposition: attachment; filename=\"disposition.pdf\"",
+			filename: "disposition.pdf",
+		},
+		2: {
+			headers:  "Content-Type: application/pdf; name=\"report.pdf\"",
+			filename: "report.pdf",
+		},
+		3: {
+			headers:  "Content-Type: application/pdf; name=\"=?UTF-8?Q?R=C3=A9sum=C3=A9.pdf?=\"",
+			filename: "Résumé.pdf",
+		},
+		4: {
+			headers:  "Content-Type: application/pdf\nContent-Description: scan 2024.pdf",
+			filename: "scan 2024.pdf",
+		},
+	}
+
+	for index, td := range testData {
+		mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+			"--m\n" + td.headers + "\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+		e, err := Parse(strings.NewReader(mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if len(e.Attachments) != 1 || e.Attachments[0].Filename != td.filename {
+			t.Errorf("[Test Case %v] Wrong attachments. Expected one named %q, Got: %+v", index, td.filename, e.Attachments)
+		}
+	}
+
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: application/pdf\nContent-Description: Quarterly report\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+	if _, err := Parse(strings.NewReader(mailData)); err == nil {
+		t.Errorf("Expected a descriptive Content-Description not to name an attachment")
+	}
+}
+
+func TestPartMetadata(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: multipart/related; boundary=r\n\n" +
+		"--r\nContent-Type: text/html\n\n<img src=\"cid:logo\">\n" +
+		"--r\nContent-Type: image/png\nContent-Id: <logo>\nContent-Disposition: inline; filename=\"logo.png\"\nContent-Description: Company logo\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--r--\n" +
+		"--m\nContent-Type: application/pdf\nContent-Id: <report@example.com>\nContent-Disposition: attachment; filename=\"report.pdf\"\nContent-Description: =?UTF-8?Q?Rapport_financier?=\nContent-Language: fr, en\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ef := e.EmbeddedFiles[0]
+	if ef.Filename != "logo.png" || ef.Disposition.Type != "inline" || ef.Size != 5 {
+		t.Errorf("Wrong embedded file. Expected logo.png, inline, 5 bytes, Got: %q, %q, %v", ef.Filename, ef.Disposition.Type, ef.Size)
+	}
+
+	a := e.Attachments[0]
+	if a.ContentID != "report@example.com" || a.Disposition.Type != "attachment" {
+		t.Errorf("Wrong attachment. Expected report@example.com, attachment, Got: %q, %q", a.ContentID, a.Disposition.Type)
+	}
+
+	if ef.Description != "Company logo" || len(ef.ContentLanguage) != 0 {
+		t.Errorf("Wrong embedded file description. Expected Company logo without languages, Got: %q, %v", ef.Description, ef.ContentLanguage)
+	}
+
+	if a.Description != "Rapport financier" || !assertSliceEq(a.ContentLanguage, []string{"fr", "en"}) {
+		t.Errorf("Wrong attachment description. Expected Rapport financier in fr, en, Got: %q, %v", a.Description, a.ContentLanguage)
+	}
+}
+
+func TestBodyPartLanguages(t *testing.T) {
+	var testData = map[int]struct {
+		mailData string
+		text     [][]string
+		html     [][]string
+	}{
+		1: {
+			mailData: "From: jdoe@machine.example\nContent-Type: text/plain\nContent-Language: de\n\nHallo\n",
+			text:     [][]string{{"de"}},
+		},
+		2: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+				"--m\nContent-Type: text/plain\nContent-Language: en\n\nHello\n" +
+				"--m\nContent-Type: text/plain\nContent-Language: fr, de\n\nBonjour\n" +
+				"--m\nContent-Type: text/plain\n\nUndeclared\n--m--\n",
+			text: [][]string{{"en"}, {"fr", "de"}, nil},
+		},
+		3: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/alternative; boundary=a\n\n" +
+				"--a\nContent-Type: text/plain\nContent-Language: es\n\nHola\n" +
+				"--a\nContent-Type: text/html\nContent-Language: es\n\n<p>Hola</p>\n--a--\n",
+			text: [][]string{{"es"}},
+			html: [][]string{{"es"}},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(td.text, e.TextBodyPartLanguages) {
+			t.Errorf("[Test Case %v] Wrong text body part languages. Expected: %v, Got: %v", index, td.text, e.TextBodyPartLanguages)
+		}
+
+		if !reflect.DeepEqual(td.html, e.HTMLBodyPartLanguages) {
+			t.Errorf("[Test Case %v] Wrong html body part languages. Expected: %v, Got: %v", index, td.html, e.HTMLBodyPartLanguages)
+		}
+	}
+}
+
+func TestPartClassification(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: inline\n\nSee attached\n" +
+		"--m\nContent-Type: multipart/related; boundary=r\n\n" +
+		"--r\nContent-Type: text/html\n\n<img src=\"cid:chart\"><embed src=\"cid:doc\">\n" +
+		"--r\nContent-Type: image/png\nContent-Disposition: attachment; filename=\"photo.png\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+		"--r\nContent-Type: application/pdf\nContent-Id: <doc>\nContent-Disposition: inline\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+		"--r\nContent-Type: image/png\nContent-Id: <chart>\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--r--\n" +
+		"--m\nContent-Type: application/pdf\nContent-Disposition: inline; filename=\"inline.pdf\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.TextBody != "See attached" {
+		t.Errorf("Wrong text body. Expected: %q, Got: %q", "See attached", e.TextBody)
+	}
+
+	var embedded, attachments []string
+	for _, ef := range e.EmbeddedFiles {
+		embedded = append(embedded, ef.CID)
+	}
+
+	for _, a := range e.Attachments {
+		attachments = append(attachments, a.Filename)
+	}
+
+	if expected := []string{"doc", "chart"}; !assertSliceEq(expected, embedded) {
+		t.Errorf("Wrong embedded files. Expected: %v, Got: %v", expected, embedded)
+	}
+
+	if expected := []string{"photo.png", "inline.pdf"}; !assertSliceEq(expected, attachments) {
+		t.Errorf("Wrong attachments. Expected: %v, Got: %v", expected, attachments)
+	}
+
+	legacy := "From: jdoe@machine.example\nContent-Type: multipart/related; boundary=r\n\n" +
+		"--r\nContent-Type: text/html\n\n<p>Hi</p>\n" +
+		"--r\nContent-Type: image/png\nContent-Disposition: attachment; filename=\"photo.png\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--r--\n"
+
+	e, err = ParseWithOptions(strings.NewReader(legacy), WithCompatLevel(CompatV4))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(e.EmbeddedFiles) != 1 || len(e.Attachments) != 0 {
+		t.Errorf("Expected CompatV4 to keep attachment disposed images as embedded files, got %v embedded files and %v attachments", len(e.EmbeddedFiles), len(e.Attachments))
+	}
+}
@@ -0,0 +1,155 @@
+package parsemail
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// Canonicalization is a DKIM canonicalization algorithm (RFC 6376 section 3.4)
+type Canonicalization string
+
+const (
+	CanonicalizationSimple  Canonicalization = "simple"
+	CanonicalizationRelaxed Canonicalization = "relaxed"
+)
+
+// ErrNoRawBody is returned when the raw body is needed but wasn't kept, see WithRawBody
+var ErrNoRawBody = errors.New("Raw body was not kept, parse WithRawBody")
+
+// CanonicalizeHeader canonicalizes a single raw header field, including its
+// folded continuation lines, with c. Algorithms other than relaxed are
+// treated as simple. Line endings are normalized to CRLF.
+func CanonicalizeHeader(field string, c Canonicalization) string {
+	if c != CanonicalizationRelaxed {
+		return toCRLF(strings.TrimRight(field, "\r\n")) + "\r\n"
+	}
+
+	colon := strings.IndexByte(field, ':')
+	if colon == -1 {
+		return ""
+	}
+
+	name := strings.ToLower(strings.TrimRight(field[:colon], " \t"))
+	value := strings.NewReplacer("\r\n", "", "\n", "").Replace(field[colon+1:])
+
+	return name + ":" + strings.TrimSpace(collapseWSP(value)) + "\r\n"
+}
+
+// CanonicalizeBody canonicalizes a raw message body with c. Algorithms other
+// than relaxed are treated as simple. Line endings are normalized to CRLF.
+func CanonicalizeBody(body []byte, c Canonicalization) []byte {
+	lines := strings.Split(strings.TrimSuffix(toCRLF(string(body)), "\r\n"), "\r\n")
+	if c == CanonicalizationRelaxed {
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(collapseWSP(line), " ")
+		}
+	}
+
+	end := len(lines)
+	for end > 0 && lines[end-1] == "" {
+		end--
+	}
+
+	if end == 0 {
+		if c == CanonicalizationRelaxed {
+			return []byte{}
+		}
+		return []byte("\r\n")
+	}
+
+	var buf bytes.Buffer
+	for _, line := range lines[:end] {
+		buf.WriteString(line)
+		buf.WriteString("\r\n")
+	}
+
+	return buf.Bytes()
+}
+
+// CanonicalHeaders returns the header fields listed in names, as in the h= tag
+// of a DKIM signature, canonicalized with c and concatenated. Like DKIM
+// verifiers, repeated names select instances of the field from the bottom of
+// the header up and names without a remaining instance are skipped.
+func (e Email) CanonicalHeaders(c Canonicalization, names ...string) []byte {
+	fields := splitRawHeaderFields(e.RawHeader)
+	used := make([]bool, len(fields))
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		for i := len(fields) - 1; i >= 0; i-- {
+			if used[i] || !strings.EqualFold(rawFieldName(fields[i]), name) {
+				continue
+			}
+
+			used[i] = true
+			buf.WriteString(CanonicalizeHeader(fields[i], c))
+			break
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// CanonicalBody returns RawBody canonicalized with c, it requires parsing WithRawBody
+func (e Email) CanonicalBody(c Canonicalization) ([]byte, error) {
+	if e.RawBody == nil {
+		return nil, ErrNoRawBody
+	}
+
+	return CanonicalizeBody(e.RawBody, c), nil
+}
+
+// splitRawHeaderFields splits a raw header into its fields with their continuation lines and line endings
+func splitRawHeaderFields(raw []byte) (fields []string) {
+	for _, line := range strings.SplitAfter(string(raw), "\n") {
+		switch {
+		case strings.TrimRight(line, "\r\n") == "":
+			continue
+		case (line[0] == ' ' || line[0] == '\t') && len(fields) > 0:
+			fields[len(fields)-1] += line
+		default:
+			fields = append(fields, line)
+		}
+	}
+
+	return
+}
+
+func rawFieldName(field string) string {
+	if colon := strings.IndexByte(field, ':'); colon != -1 {
+		return strings.TrimSpace(field[:colon])
+	}
+
+	return ""
+}
+
+// toCRLF converts bare LF line endings to CRLF
+func toCRLF(s string) string {
+	return strings.Replace(strings.Replace(s, "\r\n", "\n", -1), "\n", "\r\n", -1)
+}
+
+// collapseWSP replaces runs of spaces and tabs with a single space
+func collapseWSP(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' {
+			space = true
+			continue
+		}
+
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+
+	if space {
+		b.WriteByte(' ')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalization(t *testing.T) {
+	// the example of RFC 6376 section 3.4.5
+	mailData := "A: X\r\nB : Y\t\r\n\tZ  \r\n\r\n C \r\nD \t E\r\n\r\n\r\n"
+
+	e, err := ParseWithOptions(strings.NewReader(mailData), WithRawBody())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var testData = map[int]struct {
+		c       Canonicalization
+		headers string
+		body    string
+	}{
+		1: {
+			c:       CanonicalizationRelaxed,
+			headers: "a:X\r\nb:Y Z\r\n",
+			body:    " C\r\nD E\r\n",
+		},
+		2: {
+			c:       CanonicalizationSimple,
+			headers: "A: X\r\nB : Y\t\r\n\tZ  \r\n",
+			body:    " C \r\nD \t E\r\n",
+		},
+	}
+
+	for index, td := range testData {
+		if headers := string(e.CanonicalHeaders(td.c, "a", "b", "missing")); headers != td.headers {
+			t.Errorf("[Test Case %v] Wrong headers. Expected: %q, Got: %q", index, td.headers, headers)
+		}
+
+		body, err := e.CanonicalBody(td.c)
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+		} else if string(body) != td.body {
+			t.Errorf("[Test Case %v] Wrong body. Expected: %q, Got: %q", index, td.body, body)
+		}
+	}
+}
+
+func TestCanonicalizeBody(t *testing.T) {
+	var testData = map[int]struct {
+		body     string
+		c        Canonicalization
+		expected string
+	}{
+		1: {body: "", c: CanonicalizationSimple, expected: "\r\n"},
+		2: {body: "", c: CanonicalizationRelaxed, expected: ""},
+		3: {body: "Hello\n\n\n", c: CanonicalizationSimple, expected: "Hello\r\n"},
+		4: {body: "Hello", c: CanonicalizationRelaxed, expected: "Hello\r\n"},
+		5: {body: " \r\n\t\r\n", c: CanonicalizationRelaxed, expected: ""},
+	}
+
+	for index, td := range testData {
+		if got := string(CanonicalizeBody([]byte(td.body), td.c)); got != td.expected {
+			t.Errorf("[Test Case %v] Wrong body. Expected: %q, Got: %q", index, td.expected, got)
+		}
+	}
+}
+
+func TestCanonicalHeadersOrder(t *testing.T) {
+	mailData := "Received: first\nReceived: second\nFrom: jdoe@machine.example\n\nBody\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "received:second\r\nreceived:first\r\n"
+	if headers := string(e.CanonicalHeaders(CanonicalizationRelaxed, "Received", "Received", "Received")); headers != expected {
+		t.Errorf("Wrong headers. Expected: %q, Got: %q", expected, headers)
+	}
+
+	if _, err := e.CanonicalBody(CanonicalizationSimple); err != ErrNoRawBody {
+		t.Errorf("Expected ErrNoRawBody, got: %v", err)
+	}
+}
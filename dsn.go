@@ -0,0 +1,281 @@
+package parsemail
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	contentTypeMultipartReport        = "multipart/report"
+	contentTypeMessageDeliveryStatus  = "message/delivery-status"
+	contentTypeMessageGlobalDelStatus = "message/global-delivery-status"
+	contentTypeMessageRFC822          = "message/rfc822"
+	contentTypeMessageGlobal          = "message/global"
+	contentTypeTextRFC822Headers      = "text/rfc822-headers"
+	contentTypeMessageGlobalHeaders   = "message/global-headers"
+)
+
+var dsnBlockSeparator = regexp.MustCompile(`\r?\n[ \t]*\r?\n`)
+
+// TypedAddress is an address prefixed with its type, like "rfc822;jane@example.com",
+// as used by the Original-Recipient and Final-Recipient fields.
+type TypedAddress struct {
+	Type    string
+	Address string
+}
+
+// String returns the address in "type;address" form
+func (ta TypedAddress) String() string {
+	return ta.Type + ";" + ta.Address
+}
+
+// DeliveryStatus is a parsed message/delivery-status part (RFC 3464) of a
+// delivery status notification
+type DeliveryStatus struct {
+	ReportingMTA       string
+	ReceivedFromMTA    string
+	OriginalEnvelopeID string
+	ArrivalDate        time.Time
+
+	Recipients []RecipientStatus
+}
+
+// RecipientStatus is the delivery status of a single recipient. OriginalRecipient
+// is the recipient as given in the ORCPT parameter, before any aliasing or
+// forwarding, and is nil when the reporting MTA didn't know it.
+type RecipientStatus struct {
+	OriginalRecipient *TypedAddress
+	FinalRecipient    *TypedAddress
+	Action            string
+	Status            string
+	RemoteMTA         string
+	DiagnosticCode    string
+	LastAttemptDate   time.Time
+}
+
+// parseTypedAddress parses a "type;address" value, decoding xtext (RFC 3461)
+// when decodeXtext is set. It returns nil for empty or malformed values.
+func parseTypedAddress(s string, decodeXtext bool) *TypedAddress {
+	parts := strings.SplitN(s, ";", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+
+	ta := &TypedAddress{
+		Type:    strings.ToLower(strings.TrimSpace(parts[0])),
+		Address: strings.TrimSpace(parts[1]),
+	}
+
+	if decodeXtext {
+		ta.Address = DecodeXtext(ta.Address)
+	}
+
+	if ta.Type == "" || ta.Address == "" {
+		return nil
+	}
+
+	return ta
+}
+
+// DecodeXtext decodes the xtext encoding (RFC 3461) of SMTP DSN parameters like
+// ORCPT, where "+" followed by two upper case hex digits stands for a byte.
+// Other characters are left as they are.
+func DecodeXtext(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '+' && i+2 < len(s) && isUpperHex(s[i+1]) && isUpperHex(s[i+2]) {
+			b, _ := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			sb.WriteByte(byte(b))
+			i += 2
+			continue
+		}
+
+		sb.WriteByte(s[i])
+	}
+
+	return sb.String()
+}
+
+func isUpperHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'A' && c <= 'F')
+}
+
+// parseDeliveryStatus parses the per-message and per-recipient field blocks of a delivery-status body
+func parseDeliveryStatus(body string, o *options) (*DeliveryStatus, error) {
+	var blocks []textproto.MIMEHeader
+	for _, b := range dsnBlockSeparator.Split(strings.TrimSpace(body), -1) {
+		h, err := textproto.NewReader(bufio.NewReader(strings.NewReader(b + "\r\n\r\n"))).ReadMIMEHeader()
+		if err != nil {
+			return nil, fmt.Errorf("Malformed delivery status: %v", err)
+		}
+
+		blocks = append(blocks, h)
+	}
+
+	ds := &DeliveryStatus{}
+	if len(blocks) == 0 {
+		return ds, nil
+	}
+
+	hp := headerParser{registry: &o.registry}
+	msgFields := blocks[0]
+	ds.ReportingMTA = dsnValue(msgFields.Get("Reporting-MTA"))
+	ds.ReceivedFromMTA = dsnValue(msgFields.Get("Received-From-MTA"))
+	ds.OriginalEnvelopeID = DecodeXtext(strings.TrimSpace(msgFields.Get("Original-Envelope-Id")))
+	ds.ArrivalDate = hp.parseTime(msgFields.Get("Arrival-Date"))
+
+	for _, rf := range blocks[1:] {
+		ds.Recipients = append(ds.Recipients, RecipientStatus{
+			OriginalRecipient: parseTypedAddress(rf.Get("Original-Recipient"), true),
+			FinalRecipient:    parseTypedAddress(rf.Get("Final-Recipient"), false),
+			Action:            strings.ToLower(strings.TrimSpace(rf.Get("Action"))),
+			Status:            dsnStatusCode(rf.Get("Status")),
+			RemoteMTA:         dsnValue(rf.Get("Remote-MTA")),
+			DiagnosticCode:    dsnValue(rf.Get("Diagnostic-Code")),
+			LastAttemptDate:   hp.parseTime(rf.Get("Last-Attempt-Date")),
+		})
+	}
+
+	return ds, nil
+}
+
+// dsnStatusCode returns the status code of a Status field without a trailing comment
+func dsnStatusCode(s string) string {
+	if f := strings.Fields(s); len(f) > 0 {
+		return f[0]
+	}
+
+	return ""
+}
+
+// dsnValue returns the value of a "type; value" field like Reporting-MTA without its type
+func dsnValue(s string) string {
+	if i := strings.Index(s, ";"); i != -1 {
+		s = s[i+1:]
+	}
+
+	return strings.TrimSpace(s)
+}
+
+func parseMultipartReport(e *Email, msg io.Reader, boundary string, o *options) error {
+	if err := e.budget.enter(); err != nil {
+		return err
+	}
+	defer e.budget.leave()
+
+	e.locator.enter(boundary)
+	defer e.locator.leave()
+
+	mr := multipart.NewReader(msg, boundary)
+	for {
+		part, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		e.locator.next()
+
+		if err := e.contextErr(); err != nil {
+			return err
+		}
+
+		contentType, params, err := parseContentType(part.Header.Get(headerContentType))
+		if err != nil {
+			return err
+		}
+
+		switch contentType {
+		case contentTypeTextPlain:
+			if err := addTextBodyPart(e, part, part.Header, o); err != nil {
+				return err
+			}
+		case contentTypeTextHtml:
+			if err := addHTMLBodyPart(e, part, part.Header, params["charset"], o); err != nil {
+				return err
+			}
+		case contentTypeMultipartAlternative:
+			if err := parseMultipartAlternative(e, part, params["boundary"], o); err != nil {
+				return err
+			}
+		case contentTypeMessageDeliveryStatus, contentTypeMessageGlobalDelStatus:
+			status, err := decodeBodyPart(e, part, part.Header.Get(headerContentEncoding), part.Header.Get(headerCompression))
+			if err != nil {
+				return err
+			}
+
+			if e.DeliveryStatus, err = parseDeliveryStatus(status, o); err != nil {
+				return err
+			}
+		case contentTypeMessageRFC822, contentTypeMessageGlobal:
+			returned, err := decodeBodyPart(e, part, part.Header.Get(headerContentEncoding), part.Header.Get(headerCompression))
+			if err != nil {
+				return err
+			}
+
+			origin := int64(-1)
+			if e.locator != nil && identityEncoded(part.Header) {
+				origin = e.locator.end().BodyStart
+			}
+
+			if err := parseReturnedMessage(e, returned, origin, o); err != nil {
+				return err
+			}
+		case contentTypeTextRFC822Headers, contentTypeMessageGlobalHeaders:
+			returned, err := decodeBodyPart(e, part, part.Header.Get(headerContentEncoding), part.Header.Get(headerCompression))
+			if err != nil {
+				return err
+			}
+
+			e.OriginalHeaders = parseReturnedHeaders(e, returned)
+		default:
+			// other report types
+			if isAttachment(part) {
+				if err := addAttachment(e, part, contentType, params, o); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseReturnedMessage sets OriginalMessage and OriginalHeaders from a returned
+// message, parsed within the limits of e. Bounces often include truncated
+// messages, when the message can't be parsed only its headers are kept.
+// Exceeding a limit fails. origin is passed on to parseNestedMessage.
+func parseReturnedMessage(e *Email, returned string, origin int64, o *options) error {
+	original, err := parseNestedMessage(e, strings.NewReader(returned), origin, o)
+	if isFatalNestedError(e, err) {
+		return err
+	} else if err != nil {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("Returned message kept as headers only: %v", err))
+		e.OriginalHeaders = parseReturnedHeaders(e, returned)
+		return nil
+	}
+
+	e.OriginalMessage = &original
+	e.OriginalHeaders = original.Header
+	return nil
+}
+
+// parseReturnedHeaders parses the header fields of a returned message or a text/rfc822-headers part
+func parseReturnedHeaders(e *Email, returned string) mail.Header {
+	msg, err := mail.ReadMessage(strings.NewReader(strings.TrimLeft(returned, "\r\n") + "\r\n\r\n"))
+	if err != nil {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("Dropped malformed returned headers: %v", err))
+		return nil
+	}
+
+	header, _ := decodeHeaderMime(msg.Header)
+	return header
+}
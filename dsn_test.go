@@ -0,0 +1,176 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeXtext(t *testing.T) {
+	var testData = map[int]struct {
+		xtext   string
+		decoded string
+	}{
+		1: {xtext: "jane@example.com", decoded: "jane@example.com"},
+		2: {xtext: "jane+2Bnews@example.com", decoded: "jane+news@example.com"},
+		3: {xtext: "a+3Db+20c", decoded: "a=b c"},
+		4: {xtext: "jane+news@example.com", decoded: "jane+news@example.com"},
+		5: {xtext: "trailing+2", decoded: "trailing+2"},
+	}
+
+	for index, td := range testData {
+		if d := DecodeXtext(td.xtext); d != td.decoded {
+			t.Errorf("[Test Case %v] Wrong decoding. Expected: '%s', Got: '%s'", index, td.decoded, d)
+		}
+	}
+}
+
+func TestOriginalRecipientHeader(t *testing.T) {
+	e, err := Parse(strings.NewReader("From: a@example.com\nOriginal-Recipient: RFC822; jane+2Blists@example.com\n\nbody\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.OriginalRecipient == nil || e.OriginalRecipient.Type != "rfc822" || e.OriginalRecipient.Address != "jane+lists@example.com" {
+		t.Errorf("Wrong original recipient: %+v", e.OriginalRecipient)
+	}
+
+	e, _ = Parse(strings.NewReader("From: a@example.com\nOriginal-Recipient: garbage\n\nbody\n"))
+	if e.OriginalRecipient != nil {
+		t.Errorf("Expected no original recipient for malformed value, got %+v", e.OriginalRecipient)
+	}
+}
+
+func TestDeliveryStatusNotification(t *testing.T) {
+	e, err := Parse(strings.NewReader(dsnMessage))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.TextBody != "Your message could not be delivered." {
+		t.Errorf("Wrong text body: '%s'", e.TextBody)
+	}
+
+	ds := e.DeliveryStatus
+	if ds == nil {
+		t.Fatalf("Expected delivery status")
+	}
+
+	if ds.ReportingMTA != "mx.example.net" || ds.OriginalEnvelopeID != "id=42" {
+		t.Errorf("Wrong per-message fields: %+v", ds)
+	}
+
+	if !ds.ArrivalDate.Equal(time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Wrong arrival date: %v", ds.ArrivalDate)
+	}
+
+	if len(ds.Recipients) != 2 {
+		t.Fatalf("Expected 2 recipients, got %v", len(ds.Recipients))
+	}
+
+	r := ds.Recipients[0]
+	if r.OriginalRecipient == nil || r.OriginalRecipient.String() != "rfc822;list+1@example.org" {
+		t.Errorf("Wrong original recipient: %+v", r.OriginalRecipient)
+	}
+
+	if r.FinalRecipient == nil || r.FinalRecipient.Address != "jdoe@example.org" {
+		t.Errorf("Wrong final recipient: %+v", r.FinalRecipient)
+	}
+
+	if r.Action != "failed" || r.Status != "5.1.1" || r.RemoteMTA != "mail.example.org" {
+		t.Errorf("Wrong recipient fields: %+v", r)
+	}
+
+	if r.DiagnosticCode != "550 5.1.1 <jdoe@example.org>: user unknown" {
+		t.Errorf("Wrong diagnostic code: '%s'", r.DiagnosticCode)
+	}
+
+	r = ds.Recipients[1]
+	if r.OriginalRecipient != nil || r.Action != "delayed" || r.Status != "4.4.1" {
+		t.Errorf("Wrong second recipient: %+v", r)
+	}
+}
+
+var dsnMessage = `From: Mail Delivery System <MAILER-DAEMON@mx.example.net>
+To: sender@example.com
+Subject: Undelivered Mail Returned to Sender
+Date: Mon, 04 Mar 2019 10:00:05 +0000
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=delivery-status; boundary="dsn"
+
+--dsn
+Content-Type: text/plain
+
+Your message could not be delivered.
+
+--dsn
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.example.net
+Original-Envelope-Id: id+3D42
+Arrival-Date: Mon, 04 Mar 2019 10:00:00 +0000
+
+Original-Recipient: rfc822;list+2B1@example.org
+Final-Recipient: rfc822; jdoe@example.org
+Action: failed
+Status: 5.1.1 (user unknown)
+Remote-MTA: dns; mail.example.org
+Diagnostic-Code: smtp; 550 5.1.1 <jdoe@example.org>:
+ user unknown
+
+Final-Recipient: rfc822; slow@example.org
+Action: delayed
+Status: 4.4.1
+
+--dsn
+Content-Type: text/rfc822-headers
+
+From: sender@example.com
+To: list@example.org
+Subject: Hello
+
+--dsn--
+`
+
+func TestReturnedMessage(t *testing.T) {
+	e, err := Parse(strings.NewReader(dsnMessage))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.OriginalMessage != nil || e.OriginalHeaders.Get("Subject") != "Hello" || e.OriginalHeaders.Get("To") != "list@example.org" {
+		t.Errorf("Wrong returned headers: %v %v", e.OriginalMessage, e.OriginalHeaders)
+	}
+
+	message := strings.Replace(dsnMessage, "Content-Type: text/rfc822-headers\n\nFrom: sender@example.com\nTo: list@example.org\nSubject: Hello\n",
+		"Content-Type: message/rfc822\n\nFrom: sender@example.com\nTo: list@example.org\nSubject: =?utf-8?q?H=C3=A9llo?=\nMessage-ID: <sent-1@example.com>\n\nThe original.\n", 1)
+
+	e, err = Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	original := e.OriginalMessage
+	if original == nil {
+		t.Fatalf("Expected returned message")
+	}
+
+	if original.MessageID != "sent-1@example.com" || original.Subject != "Héllo" || original.TextBody != "The original." {
+		t.Errorf("Wrong returned message: %s %s %s", original.MessageID, original.Subject, original.TextBody)
+	}
+
+	if e.OriginalHeaders.Get("Subject") != "Héllo" {
+		t.Errorf("Wrong returned headers: %v", e.OriginalHeaders)
+	}
+
+	truncated := strings.Replace(message, "\nThe original.\n", "\nContent-Type: multipart/mixed\n", 1)
+	truncated = strings.Replace(truncated, "Message-ID: <sent-1@example.com>\n", "Message-ID: <sent-1@example.com>\nContent-Type: multipart/mixed; boundary=\"cut\"\n", 1)
+	e, err = Parse(strings.NewReader(truncated))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.OriginalMessage != nil || e.OriginalHeaders.Get("Message-Id") != "<sent-1@example.com>" || len(e.Warnings) != 1 {
+		t.Errorf("Expected headers of truncated returned message: %v %v %v", e.OriginalMessage, e.OriginalHeaders, e.Warnings)
+	}
+}
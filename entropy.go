@@ -0,0 +1,169 @@
+package parsemail
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"math"
+)
+
+// packedEntropyThreshold is the Shannon entropy (bits per byte) above which an
+// executable is considered likely to be packed or encrypted
+const packedEntropyThreshold = 7.2
+
+// packerSignatures are section names and markers left behind by common executable packers
+var packerSignatures = [][]byte{
+	[]byte("UPX0"),
+	[]byte("UPX1"),
+	[]byte("UPX!"),
+	[]byte(".aspack"),
+	[]byte(".adata"),
+	[]byte("MPRESS1"),
+	[]byte(".petite"),
+	[]byte(".nsp0"),
+	[]byte(".themida"),
+	[]byte("FSG!"),
+}
+
+// shannonEntropy returns the entropy of data in bits per byte, ranging from 0 to 8
+func shannonEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+
+	var counts [256]int64
+	for _, b := range data {
+		counts[b]++
+	}
+
+	return countsEntropy(&counts, int64(len(data)))
+}
+
+// countsEntropy returns the entropy of data with the given byte counts
+func countsEntropy(counts *[256]int64, n int64) float64 {
+	entropy := 0.0
+	total := float64(n)
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+
+		p := float64(c) / total
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// isExecutable reports whether data starts with a PE, ELF or Mach-O header
+func isExecutable(data []byte) bool {
+	switch {
+	case bytes.HasPrefix(data, []byte("MZ")):
+		return true
+	case bytes.HasPrefix(data, []byte("\x7fELF")):
+		return true
+	case bytes.HasPrefix(data, []byte{0xfe, 0xed, 0xfa, 0xce}), bytes.HasPrefix(data, []byte{0xce, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(data, []byte{0xfe, 0xed, 0xfa, 0xcf}), bytes.HasPrefix(data, []byte{0xcf, 0xfa, 0xed, 0xfe}):
+		return true
+	}
+
+	return false
+}
+
+// packerSuspected reports whether an executable looks packed, either by a known
+// packer signature or by an entropy too high for regular machine code
+func packerSuspected(data []byte, entropy float64) bool {
+	if !isExecutable(data) {
+		return false
+	}
+
+	for _, sig := range packerSignatures {
+		if bytes.Contains(data, sig) {
+			return true
+		}
+	}
+
+	return entropy > packedEntropyThreshold
+}
+
+// maxPackerSignatureLen is the length of the longest of packerSignatures
+const maxPackerSignatureLen = 8
+
+// dataStats computes the size, SHA-256 digest, entropy and packer check of
+// data written to it, for data that is never held in memory as a whole
+type dataStats struct {
+	counts [256]int64
+	n      int64
+	hash   hash.Hash
+
+	// head holds the first bytes for the executable check and content
+	// sniffing, tail the last bytes
+	// of the previous write, as signatures may span writes
+	head      []byte
+	tail      []byte
+	signature bool
+}
+
+func (s *dataStats) Write(p []byte) (int, error) {
+	if s.hash == nil {
+		s.hash = sha256.New()
+	}
+	s.hash.Write(p)
+
+	for _, b := range p {
+		s.counts[b]++
+	}
+	s.n += int64(len(p))
+
+	if len(s.head) < sniffLen {
+		s.head = append(s.head, p[:minInt(len(p), sniffLen-len(s.head))]...)
+	}
+
+	if !s.signature {
+		window := append(s.tail, p...)
+		for _, sig := range packerSignatures {
+			if bytes.Contains(window, sig) {
+				s.signature = true
+				break
+			}
+		}
+
+		s.tail = append([]byte{}, window[len(window)-minInt(len(window), maxPackerSignatureLen-1):]...)
+	}
+
+	return len(p), nil
+}
+
+// sha256 returns the hex encoded SHA-256 digest of the data
+func (s *dataStats) sha256() string {
+	if s.hash == nil {
+		s.hash = sha256.New()
+	}
+
+	return hex.EncodeToString(s.hash.Sum(nil))
+}
+
+func (s *dataStats) entropy() float64 {
+	if s.n == 0 {
+		return 0
+	}
+
+	return countsEntropy(&s.counts, s.n)
+}
+
+func (s *dataStats) packerSuspected() bool {
+	if !isExecutable(s.head) {
+		return false
+	}
+
+	return s.signature || s.entropy() > packedEntropyThreshold
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,68 @@
+package parsemail
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestShannonEntropy(t *testing.T) {
+	uniform := make([]byte, 256)
+	for i := range uniform {
+		uniform[i] = byte(i)
+	}
+
+	var testData = map[int]struct {
+		data     []byte
+		expected float64
+	}{
+		1: {data: nil, expected: 0},
+		2: {data: []byte("aaaaaaaa"), expected: 0},
+		3: {data: []byte("abababab"), expected: 1},
+		4: {data: uniform, expected: 8},
+	}
+
+	for index, td := range testData {
+		if got := shannonEntropy(td.data); math.Abs(got-td.expected) > 1e-9 {
+			t.Errorf("[Test Case %v] Wrong entropy. Expected: %v, Got: %v", index, td.expected, got)
+		}
+	}
+}
+
+func TestPackerSuspected(t *testing.T) {
+	random := make([]byte, 4096)
+	for i := range random {
+		random[i] = byte(i*7919 + i/256*31)
+	}
+
+	var testData = map[int]struct {
+		data     []byte
+		expected bool
+	}{
+		1: {data: []byte("MZ\x90\x00 plain old program UPX0 UPX1"), expected: true},
+		2: {data: []byte("MZ\x90\x00 plain old program"), expected: false},
+		3: {data: []byte("not an executable UPX!"), expected: false},
+		4: {data: append([]byte("\x7fELF"), random...), expected: true},
+		5: {data: bytes.Repeat([]byte("\x7fELF"), 64), expected: false},
+	}
+
+	for index, td := range testData {
+		if got := packerSuspected(td.data, shannonEntropy(td.data)); got != td.expected {
+			t.Errorf("[Test Case %v] Wrong packer verdict. Expected: %v, Got: %v", index, td.expected, got)
+		}
+
+		// written in 3 byte chunks signatures span writes
+		stats := &dataStats{}
+		for data := td.data; len(data) > 0; data = data[minInt(len(data), 3):] {
+			stats.Write(data[:minInt(len(data), 3)])
+		}
+
+		if got := stats.packerSuspected(); got != td.expected {
+			t.Errorf("[Test Case %v] Wrong streamed packer verdict. Expected: %v, Got: %v", index, td.expected, got)
+		}
+
+		if stats.entropy() != shannonEntropy(td.data) {
+			t.Errorf("[Test Case %v] Wrong streamed entropy. Expected: %v, Got: %v", index, shannonEntropy(td.data), stats.entropy())
+		}
+	}
+}
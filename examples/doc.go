@@ -0,0 +1,9 @@
+// Package examples shows how the parts of parsemail fit together in small,
+// complete programs. Every program is a testable example, so they are
+// compiled and their output verified by go test:
+//
+//	Example_bounceProcessor     map bounces back to the original recipients
+//	Example_attachmentExtractor save the attachments of a message to a directory
+//	Example_sanitizingViewer    serve a redacted message over HTTP
+//	Example_dkimVerifier        check DKIM alignment of the sender identity
+package examples
@@ -0,0 +1,213 @@
+package examples
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DusanKasan/parsemail"
+)
+
+// Bounce processors read delivery status notifications and disable the
+// recipients that failed permanently. The original recipient is used when the
+// reporting MTA knows it, since the final recipient may be an alias.
+func Example_bounceProcessor() {
+	email, err := parsemail.Parse(strings.NewReader(bounceMessage))
+	if err != nil {
+		panic(err)
+	}
+
+	if email.DeliveryStatus == nil {
+		fmt.Println("not a bounce")
+		return
+	}
+
+	for _, r := range email.DeliveryStatus.Recipients {
+		recipient := r.FinalRecipient
+		if r.OriginalRecipient != nil {
+			recipient = r.OriginalRecipient
+		}
+
+		if recipient == nil {
+			continue
+		}
+
+		switch {
+		case r.Action == "failed" && strings.HasPrefix(r.Status, "5."):
+			fmt.Println("disable", recipient.Address, r.Status)
+		default:
+			fmt.Println("retry later", recipient.Address, r.Status)
+		}
+	}
+
+	// Output:
+	// disable news+jdoe@example.org 5.1.1
+	// retry later slow@example.org 4.4.1
+}
+
+// Attachment extractors store every attachment of a message, without trusting
+// the file names chosen by the sender.
+func Example_attachmentExtractor() {
+	email, err := parsemail.Parse(strings.NewReader(attachmentMessage))
+	if err != nil {
+		panic(err)
+	}
+
+	dir, err := ioutil.TempDir("", "attachments")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, a := range email.Attachments {
+		name := filepath.Base(a.Filename)
+		if name == "." || name == string(filepath.Separator) {
+			name = fmt.Sprintf("attachment-%d", i)
+		}
+
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			panic(err)
+		}
+
+		n, err := io.Copy(f, a.Data)
+		f.Close()
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Println(name, a.ContentType, n, "bytes")
+	}
+
+	// Output:
+	// report.txt text/plain 12 bytes
+	// passwd application/octet-stream 5 bytes
+}
+
+// Sanitizing viewers serve messages to a browser. The Handler redacts headers
+// before rendering anything and serves the sanitized HTML body with a
+// restrictive Content-Security-Policy.
+func Example_sanitizingViewer() {
+	message := strings.Replace(attachmentMessage, "<p>See the attached report.</p>", `<p onclick="steal()">See the attached report.<script>steal()</script></p>`, 1)
+	h := parsemail.NewHandler(func(r *http.Request) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(message)), nil
+	})
+	h.Redact = parsemail.Redaction{Drop: []string{"X-Internal-Route"}}
+
+	srv := httptest.NewServer(http.StripPrefix("/messages/1", h))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/messages/1/headers.json")
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+
+	headers, _ := ioutil.ReadAll(resp.Body)
+	fmt.Println(resp.StatusCode, strings.Contains(string(headers), "X-Internal-Route"))
+
+	resp, err = http.Get(srv.URL + "/messages/1/body.html")
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	fmt.Println(resp.StatusCode, resp.Header.Get("Content-Security-Policy") != "")
+	fmt.Println(strings.TrimSpace(string(body)))
+
+	// Output:
+	// 200 false
+	// 200 true
+	// <p>See the attached report.</p>
+}
+
+// DKIM verifiers first check that a signature is aligned with the From
+// domain, which is what receivers act upon. Only an aligned signature is then
+// worth verifying against the DNS key of its d= domain, which needs a
+// resolver and is left out here.
+func Example_dkimVerifier() {
+	email, err := parsemail.Parse(strings.NewReader(attachmentMessage))
+	if err != nil {
+		panic(err)
+	}
+
+	si := email.SenderIdentity()
+	fmt.Println("from domain:", si.FromDomain)
+	fmt.Println("signature domains:", si.DKIMDomains)
+	fmt.Println("aligned:", !si.DKIMMisaligned)
+
+	// Output:
+	// from domain: example.com
+	// signature domains: [mail.example.com]
+	// aligned: true
+}
+
+var bounceMessage = `From: Mail Delivery System <MAILER-DAEMON@mx.example.net>
+To: news@example.com
+Subject: Undelivered Mail Returned to Sender
+Content-Type: multipart/report; report-type=delivery-status; boundary="dsn"
+
+--dsn
+Content-Type: text/plain
+
+Your message could not be delivered.
+
+--dsn
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.example.net
+
+Original-Recipient: rfc822;news+2Bjdoe@example.org
+Final-Recipient: rfc822; jdoe@example.org
+Action: failed
+Status: 5.1.1
+
+Final-Recipient: rfc822; slow@example.org
+Action: delayed
+Status: 4.4.1
+
+--dsn--
+`
+
+var attachmentMessage = `From: Jane Doe <jane@example.com>
+To: john@example.org
+Subject: Report
+X-Internal-Route: relay-7.corp.example.com
+DKIM-Signature: v=1; a=rsa-sha256; d=mail.example.com; s=s1; h=from:to:subject;
+ bh=47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=; b=dGVzdA==
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="mixed"
+
+--mixed
+Content-Type: multipart/alternative; boundary="alt"
+
+--alt
+Content-Type: text/plain
+
+See the attached report.
+--alt
+Content-Type: text/html
+
+<p>See the attached report.</p>
+--alt--
+
+--mixed
+Content-Type: text/plain; name="report.txt"
+Content-Disposition: attachment; filename="report.txt"
+Content-Transfer-Encoding: base64
+
+UmVwb3J0IGJvZHkK
+--mixed
+Content-Type: application/octet-stream
+Content-Disposition: attachment; filename="../../etc/passwd"
+Content-Transfer-Encoding: base64
+
+cm9vdAo=
+--mixed--
+`
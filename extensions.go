@@ -0,0 +1,78 @@
+package parsemail
+
+import (
+	"fmt"
+	"net/textproto"
+	"sync"
+)
+
+// HeaderParserFunc parses the raw values of a header field into a typed value
+type HeaderParserFunc func(values []string) (interface{}, error)
+
+type registeredHeaderParser struct {
+	name  string
+	parse HeaderParserFunc
+}
+
+var (
+	headerParsersMu sync.RWMutex
+	headerParsers   = map[string]registeredHeaderParser{}
+)
+
+// RegisterHeaderParser registers fn as the parser of the header field name. On every
+// parsed message containing the field, fn is called with its raw (not MIME decoded)
+// values and the result is stored in Email.Extensions[name]. An error returned by fn
+// fails the whole parse. Registering a parser for an already registered name replaces
+// it, registering a nil fn removes it.
+func RegisterHeaderParser(name string, fn HeaderParserFunc) {
+	headerParsersMu.Lock()
+	defer headerParsersMu.Unlock()
+
+	key := textproto.CanonicalMIMEHeaderKey(name)
+	if fn == nil {
+		delete(headerParsers, key)
+		return
+	}
+
+	headerParsers[key] = registeredHeaderParser{name: name, parse: fn}
+}
+
+// allHeaderParsers returns a copy of the registered header parsers by canonical
+// field name, with those of reg replacing them. reg may be nil.
+func (reg *registry) allHeaderParsers() map[string]registeredHeaderParser {
+	headerParsersMu.RLock()
+	parsers := make(map[string]registeredHeaderParser, len(headerParsers))
+	for key, p := range headerParsers {
+		parsers[key] = p
+	}
+	headerParsersMu.RUnlock()
+
+	if reg != nil {
+		for key, p := range reg.headerParsers {
+			parsers[key] = p
+		}
+	}
+
+	return parsers
+}
+
+func (hp headerParser) parseExtensions() (extensions map[string]interface{}, err error) {
+	for key, p := range hp.registry.allHeaderParsers() {
+		values, ok := (*hp.header)[key]
+		if !ok || p.parse == nil {
+			continue
+		}
+
+		v, err := p.parse(values)
+		if err != nil {
+			return nil, fmt.Errorf("Can't parse %s header: %v", p.name, err)
+		}
+
+		if extensions == nil {
+			extensions = map[string]interface{}{}
+		}
+		extensions[p.name] = v
+	}
+
+	return
+}
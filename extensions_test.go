@@ -0,0 +1,42 @@
+package parsemail
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRegisterHeaderParser(t *testing.T) {
+	RegisterHeaderParser("X-Company-Case-ID", func(values []string) (interface{}, error) {
+		return strconv.Atoi(strings.TrimSpace(values[0]))
+	})
+	defer RegisterHeaderParser("X-Company-Case-ID", nil)
+
+	message := "From: John Doe <jdoe@machine.example>\nX-Company-Case-Id: 4711\n\nHello.\n"
+	e, err := Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id, ok := e.Extensions["X-Company-Case-ID"].(int); !ok || id != 4711 {
+		t.Errorf("Wrong extension value: %#v", e.Extensions)
+	}
+
+	e, err = Parse(strings.NewReader(rfc5322exampleA11))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Extensions != nil {
+		t.Errorf("Unexpected extensions: %#v", e.Extensions)
+	}
+
+	RegisterHeaderParser("X-Company-Case-ID", func(values []string) (interface{}, error) {
+		return nil, errors.New("invalid case id")
+	})
+
+	if _, err := Parse(strings.NewReader(message)); err == nil {
+		t.Errorf("Expected parser error to fail the parse")
+	}
+}
@@ -0,0 +1,98 @@
+package parsemail
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// ContentExtractor extracts the text of attachments of a content type, like
+// PDF or DOCX documents, for indexing. Implementations usually wrap a third
+// party library, which keeps it out of this package.
+type ContentExtractor interface {
+	ExtractText(r io.Reader, contentType string) (string, error)
+}
+
+// ContentExtractorFunc adapts a function to a ContentExtractor
+type ContentExtractorFunc func(r io.Reader, contentType string) (string, error)
+
+// ExtractText calls f(r, contentType)
+func (f ContentExtractorFunc) ExtractText(r io.Reader, contentType string) (string, error) {
+	return f(r, contentType)
+}
+
+var (
+	contentExtractorsMu sync.RWMutex
+	contentExtractors   = map[string]ContentExtractor{}
+)
+
+// RegisterContentExtractor registers x for attachments of contentType, which
+// may be a wildcard like "image/*". The extracted text is stored in
+// Attachment.Text while parsing, extraction errors are reported in
+// Email.Warnings. Registering a nil x removes the extractor.
+func RegisterContentExtractor(contentType string, x ContentExtractor) {
+	contentExtractorsMu.Lock()
+	defer contentExtractorsMu.Unlock()
+
+	contentType = strings.ToLower(contentType)
+	if x == nil {
+		delete(contentExtractors, contentType)
+		return
+	}
+
+	contentExtractors[contentType] = x
+}
+
+// contentExtractor returns the extractor for contentType, falling back to the
+// wildcard of its top level type. The extractors of reg, which may be nil, come first.
+func (reg *registry) contentExtractor(contentType string) (ContentExtractor, bool) {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+
+	contentType = strings.ToLower(contentType)
+	for _, key := range []string{contentType, strings.Split(contentType, "/")[0] + "/*"} {
+		if x := reg.registeredContentExtractor(key); x != nil {
+			return x, true
+		}
+	}
+
+	return nil, false
+}
+
+// registeredContentExtractor returns the extractor registered for key, nil when there is none
+func (reg *registry) registeredContentExtractor(key string) ContentExtractor {
+	if reg != nil {
+		if x, ok := reg.contentExtractors[key]; ok {
+			return x
+		}
+	}
+
+	contentExtractorsMu.RLock()
+	defer contentExtractorsMu.RUnlock()
+
+	return contentExtractors[key]
+}
+
+// extractAttachmentText sets the Text of the attachments of e that have a registered extractor
+func extractAttachmentText(e *Email, o *options) {
+	for i := range e.Attachments {
+		a := &e.Attachments[i]
+		x, ok := o.registry.contentExtractor(a.ContentType)
+		if !ok {
+			continue
+		}
+
+		sr, err := a.SectionReader()
+		if err != nil {
+			e.Warnings = append(e.Warnings, fmt.Sprintf("Skipped text extraction of %s: %v", a.Filename, err))
+			continue
+		}
+
+		if a.Text, err = x.ExtractText(sr, a.ContentType); err != nil {
+			e.Warnings = append(e.Warnings, fmt.Sprintf("Failed to extract text of %s: %v", a.Filename, err))
+		}
+	}
+}
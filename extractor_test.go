@@ -0,0 +1,49 @@
+package parsemail
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestContentExtractor(t *testing.T) {
+	RegisterContentExtractor("application/pdf", ContentExtractorFunc(func(r io.Reader, contentType string) (string, error) {
+		data, err := ioutil.ReadAll(r)
+		return "pdf text of " + string(data), err
+	}))
+	RegisterContentExtractor("image/*", ContentExtractorFunc(func(r io.Reader, contentType string) (string, error) {
+		return "", errors.New("No OCR")
+	}))
+	defer RegisterContentExtractor("application/pdf", nil)
+	defer RegisterContentExtractor("image/*", nil)
+
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: application/pdf; name=a.pdf\nContent-Disposition: attachment; filename=a.pdf\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+		"--m\nContent-Type: image/png\nContent-Disposition: attachment; filename=b.png\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+		"--m\nContent-Type: application/zip\nContent-Disposition: attachment; filename=c.zip\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := []string{e.Attachments[0].Text, e.Attachments[1].Text, e.Attachments[2].Text}
+	if !assertSliceEq([]string{"pdf text of hello", "", ""}, texts) {
+		t.Errorf("Wrong attachment text: %q", texts)
+	}
+
+	if !assertSliceEq([]string{"Failed to extract text of b.png: No OCR"}, e.Warnings) {
+		t.Errorf("Wrong warnings: %v", e.Warnings)
+	}
+
+	// extraction leaves the data to be read
+	if data, _ := ioutil.ReadAll(e.Attachments[0].Data); string(data) != "hello" {
+		t.Errorf("Wrong attachment data: %q", data)
+	}
+
+	if d := e.SearchDocument(); !assertSliceEq([]string{"pdf text of hello"}, d.AttachmentText) {
+		t.Errorf("Wrong search document attachment text: %q", d.AttachmentText)
+	}
+}
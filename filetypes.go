@@ -0,0 +1,177 @@
+package parsemail
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// genericContentTypes are declared by senders that don't know the type of a file
+var genericContentTypes = map[string]bool{
+	"":                           true,
+	"application/octet-stream":   true,
+	"binary/octet-stream":        true,
+	"application/unknown":        true,
+	"application/x-unknown":      true,
+	"application/download":       true,
+	"application/x-download":     true,
+	"application/force-download": true,
+	"text/plain":                 true,
+}
+
+var (
+	fileTypesMu sync.RWMutex
+
+	// extensionTypes maps lower case extensions to content types and
+	// typeExtensions content types to their preferred extension
+	extensionTypes = map[string]string{}
+	typeExtensions = map[string]string{}
+)
+
+func init() {
+	for _, t := range []struct{ ext, contentType string }{
+		{".pdf", "application/pdf"},
+		{".doc", "application/msword"},
+		{".docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{".xls", "application/vnd.ms-excel"},
+		{".xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{".ppt", "application/vnd.ms-powerpoint"},
+		{".pptx", "application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+		{".odt", "application/vnd.oasis.opendocument.text"},
+		{".ods", "application/vnd.oasis.opendocument.spreadsheet"},
+		{".rtf", "application/rtf"},
+		{".zip", "application/zip"},
+		{".7z", "application/x-7z-compressed"},
+		{".gz", "application/gzip"},
+		{".rar", "application/vnd.rar"},
+		{".tar", "application/x-tar"},
+		{".png", "image/png"},
+		{".jpg", "image/jpeg"},
+		{".jpeg", "image/jpeg"},
+		{".gif", "image/gif"},
+		{".webp", "image/webp"},
+		{".svg", "image/svg+xml"},
+		{".heic", "image/heic"},
+		{".tif", "image/tiff"},
+		{".tiff", "image/tiff"},
+		{".mp3", "audio/mpeg"},
+		{".wav", "audio/wav"},
+		{".mp4", "video/mp4"},
+		{".mov", "video/quicktime"},
+		{".txt", "text/plain"},
+		{".csv", "text/csv"},
+		{".htm", "text/html"},
+		{".html", "text/html"},
+		{".ics", "text/calendar"},
+		{".vcf", "text/vcard"},
+		{".json", "application/json"},
+		{".xml", "application/xml"},
+		{".eml", "message/rfc822"},
+		{".exe", "application/vnd.microsoft.portable-executable"},
+	} {
+		RegisterExtension(t.ext, t.contentType)
+	}
+}
+
+// RegisterExtension maps the filename extension ext, like ".pdf", to
+// contentType for the suggested content types and filenames of attachments.
+// The first extension registered for a content type is the one added to
+// filenames without one. Registering an empty contentType removes the mapping
+// of ext.
+func RegisterExtension(ext, contentType string) {
+	fileTypesMu.Lock()
+	defer fileTypesMu.Unlock()
+
+	ext = normalizeExtension(ext)
+	if contentType == "" {
+		if typeExtensions[extensionTypes[ext]] == ext {
+			delete(typeExtensions, extensionTypes[ext])
+		}
+		delete(extensionTypes, ext)
+		return
+	}
+
+	contentType = strings.ToLower(contentType)
+	extensionTypes[ext] = contentType
+	if _, ok := typeExtensions[contentType]; !ok {
+		typeExtensions[contentType] = ext
+	}
+}
+
+// normalizeExtension lower cases ext and prefixes it with a dot
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	return ext
+}
+
+// extensionType returns the content type ext maps to, reg may be nil
+func (reg *registry) extensionType(ext string) (string, bool) {
+	if reg != nil {
+		if contentType, ok := reg.extensionTypes[ext]; ok {
+			return contentType, contentType != ""
+		}
+	}
+
+	fileTypesMu.RLock()
+	defer fileTypesMu.RUnlock()
+
+	contentType, ok := extensionTypes[ext]
+	return contentType, ok
+}
+
+// typeExtension returns the preferred extension of contentType, reg may be nil
+func (reg *registry) typeExtension(contentType string) (string, bool) {
+	if reg != nil {
+		if ext, ok := reg.typeExtensions[contentType]; ok {
+			return ext, true
+		}
+	}
+
+	fileTypesMu.RLock()
+	ext, ok := typeExtensions[contentType]
+	fileTypesMu.RUnlock()
+
+	// the extension may map to another type or none for reg
+	if mapped, _ := reg.extensionType(ext); ok && mapped != contentType {
+		return "", false
+	}
+
+	return ext, ok
+}
+
+// suggestAttachmentType returns the content type of an attachment corrected by
+// its filename extension when the declared one is generic, and its filename
+// with an extension inferred from the type when it has none. reg may be nil.
+func (reg *registry) suggestAttachmentType(filename, declared, detected string) (contentType, suggestedFilename string) {
+	declared = strings.ToLower(strings.TrimSpace(declared))
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	contentType = declared
+	if genericContentTypes[declared] {
+		if byExt, ok := reg.extensionType(ext); ok {
+			contentType = byExt
+		} else if detected != "" {
+			contentType = detected
+		}
+	}
+
+	if contentType == "" {
+		contentType = contentTypeOctetStream
+	}
+
+	suggestedFilename = filename
+	if _, known := reg.extensionType(ext); !known {
+		if inferred, ok := reg.typeExtension(contentType); ok {
+			if suggestedFilename == "" {
+				suggestedFilename = "attachment"
+			}
+			suggestedFilename += inferred
+		}
+	}
+
+	return
+}
@@ -0,0 +1,52 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggestAttachmentType(t *testing.T) {
+	var testData = map[int]struct {
+		filename    string
+		declared    string
+		detected    string
+		contentType string
+		suggested   string
+	}{
+		1: {filename: "report.pdf", declared: "text/plain", contentType: "application/pdf", suggested: "report.pdf"},
+		2: {filename: "report.PDF", declared: "application/octet-stream", contentType: "application/pdf", suggested: "report.PDF"},
+		3: {filename: "photo.png", declared: "image/jpeg", contentType: "image/jpeg", suggested: "photo.png"},
+		4: {filename: "invoice", declared: "application/pdf", contentType: "application/pdf", suggested: "invoice.pdf"},
+		5: {filename: "scan", declared: "application/octet-stream", detected: "image/png", contentType: "image/png", suggested: "scan.png"},
+		6: {declared: "image/jpeg", contentType: "image/jpeg", suggested: "attachment.jpg"},
+		7: {filename: "blob", contentType: "application/octet-stream", suggested: "blob"},
+	}
+
+	for index, td := range testData {
+		contentType, suggested := new(registry).suggestAttachmentType(td.filename, td.declared, td.detected)
+		if contentType != td.contentType {
+			t.Errorf("[Test Case %v] Wrong content type. Expected: %q, Got: %q", index, td.contentType, contentType)
+		}
+
+		if suggested != td.suggested {
+			t.Errorf("[Test Case %v] Wrong filename. Expected: %q, Got: %q", index, td.suggested, suggested)
+		}
+	}
+}
+
+func TestRegisterExtension(t *testing.T) {
+	RegisterExtension("dwg", "image/vnd.dwg")
+	defer RegisterExtension(".dwg", "")
+
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: application/octet-stream\nContent-Disposition: attachment; filename=\"plan.dwg\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.Attachments[0].SuggestedContentType != "image/vnd.dwg" {
+		t.Errorf("Wrong suggested content type. Expected: image/vnd.dwg, Got: %q", e.Attachments[0].SuggestedContentType)
+	}
+}
@@ -0,0 +1,96 @@
+package parsemail
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/mail"
+	"strings"
+)
+
+// fingerprintFields are the header fields covered by
+// Email.Fingerprint besides the addresses. Transport fields added on the way (Received, Return-Path,
+// Delivered-To, authentication results, spam scores) differ between copies of
+// the same message and are left out.
+var fingerprintFields = []string{"Subject", "Message-Id", "In-Reply-To", "References"}
+
+// Fingerprint returns a stable hash of the content of the email, equal for
+// copies of a message received through different paths or stored by
+// different archives: the originator and recipient addresses, the subject,
+// date and message ids, the bodies with line endings and trailing whitespace
+// normalized, and the names, types and data of attachments and embedded files.
+// Reading the data requires random access (see Attachment.ReadAt), otherwise
+// ErrNotSeekable is returned.
+func (e Email) Fingerprint() (string, error) {
+	h := sha256.New()
+	field := func(name, value string) {
+		fmt.Fprintf(h, "%s\x00%d\x00%s\n", name, len(value), value)
+	}
+
+	addresses := func(name string, list []*mail.Address) {
+		var normalized []string
+		for _, a := range list {
+			if a != nil {
+				normalized = append(normalized, NormalizeAddress(a.Address, false))
+			}
+		}
+		field(name, strings.Join(normalized, ","))
+	}
+
+	addresses("From", e.From)
+	addresses("Sender", []*mail.Address{e.Sender})
+	addresses("Reply-To", e.ReplyTo)
+	addresses("To", e.To)
+	addresses("Cc", e.Cc)
+
+	if !e.Date.IsZero() {
+		field("Date", fmt.Sprint(e.Date.Unix()))
+	}
+
+	for _, name := range fingerprintFields {
+		field(name, strings.Join(strings.Fields(e.Header.Get(name)), " "))
+	}
+
+	field("Text", normalizeBodyLines(e.TextBody))
+	field("HTML", normalizeBodyLines(e.HTMLBody))
+
+	for _, a := range e.Attachments {
+		field("Attachment", a.Filename+"\x00"+a.ContentType)
+		if err := hashReaderAt(h, a.Data); err != nil {
+			return "", err
+		}
+	}
+
+	for _, ef := range e.EmbeddedFiles {
+		field("Embedded", ef.CID+"\x00"+ef.ContentType)
+		if err := hashReaderAt(h, ef.Data); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// normalizeBodyLines converts line endings to LF and removes trailing whitespace of lines and the body
+func normalizeBodyLines(s string) string {
+	lines := strings.Split(strings.Replace(s, "\r\n", "\n", -1), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t\r")
+	}
+
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// hashReaderAt writes the data of r to h without consuming r
+func hashReaderAt(h hash.Hash, r io.Reader) error {
+	sra, ok := r.(sizedReaderAt)
+	if !ok {
+		return ErrNotSeekable
+	}
+
+	fmt.Fprintf(h, "%d\x00", sra.Size())
+	_, err := io.Copy(h, io.NewSectionReader(sra, 0, sra.Size()))
+	return err
+}
@@ -0,0 +1,57 @@
+package parsemail
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	base := "From: Jane <jane@Example.com>\nTo: john@example.org\nSubject: Report\nDate: Mon, 4 Mar 2019 10:00:00 +0000\nMessage-ID: <a@example.com>\n"
+	original := base + "Content-Type: text/plain\n\nHello  \r\nWorld\n"
+	relayed := "Received: from mx by mailbox; Mon, 4 Mar 2019 10:00:05 +0000\nReturn-Path: <bounce@example.com>\nX-Spam-Score: 1.2\n" +
+		strings.Replace(base, "jane@Example.com", "jane@example.com", 1) + "Content-Type: text/plain\n\nHello\nWorld\n"
+	changed := base + "Content-Type: text/plain\n\nHello\nEveryone\n"
+
+	fingerprint := func(message string) string {
+		e, err := Parse(strings.NewReader(message))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		f, err := e.Fingerprint()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		return f
+	}
+
+	if fingerprint(original) != fingerprint(relayed) {
+		t.Errorf("Expected equal fingerprints for copies of a message")
+	}
+
+	if fingerprint(original) == fingerprint(changed) {
+		t.Errorf("Expected different fingerprints for different bodies")
+	}
+
+	withAttachment := func(data string) string {
+		return base + "Content-Type: multipart/mixed; boundary=b\n\n--b\nContent-Type: text/plain\nContent-Disposition: attachment; filename=a.txt\nContent-Transfer-Encoding: base64\n\n" + data + "\n--b--\n"
+	}
+
+	e, _ := Parse(strings.NewReader(withAttachment("SGVsbG8=")))
+	f1, _ := e.Fingerprint()
+	if data, _ := ioutil.ReadAll(e.Attachments[0].Data); string(data) != "Hello" {
+		t.Errorf("Fingerprint consumed the attachment data, got '%s'", data)
+	}
+
+	if f1 != fingerprint(withAttachment("SGVsbG8=")) || f1 == fingerprint(withAttachment("SGVsbG8h")) {
+		t.Errorf("Expected fingerprints to depend on attachment data")
+	}
+
+	e.Attachments[0].Data = strings.NewReader("not seekable")
+	e.Attachments[0].Data = ioutil.NopCloser(e.Attachments[0].Data)
+	if _, err := e.Fingerprint(); err != ErrNotSeekable {
+		t.Errorf("Expected ErrNotSeekable, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package parsemail
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	forwardMarkerPattern = regexp.MustCompile(`(?i)^\s*(?:` +
+		`begin forwarded message:|` +
+		`-{2,}\s*(?:original message|forwarded message|ursprüngliche nachricht|weitergeleitete nachricht|` +
+		`mensaje original|mensaje reenviado|message original|message transféré|messaggio originale|messaggio inoltrato|` +
+		`oorspronkelijk bericht|doorgestuurd bericht)\s*-{2,}|` +
+		`_{10,})\s*$`)
+	forwardHeaderPattern = regexp.MustCompile(`^\s*\*?([A-Za-z][A-Za-z -]{0,20}?)\*?:\s*(.*)$`)
+)
+
+// ForwardedSection is a forwarded or quoted original message found in a
+// body, introduced by a marker line like "Begin forwarded message:" or
+// "-----Original Message-----". Offsets are byte offsets into the body:
+// the marker line starts at Start, the forwarded body at BodyStart, and
+// the section ends at End, where the next section or the body ends.
+type ForwardedSection struct {
+	Marker    string
+	Start     int
+	BodyStart int
+	End       int
+
+	// Header holds the header lines (From, Sent, To, Subject ...) following the marker, keyed by their name as written
+	Header map[string]string
+}
+
+// FindForwarded returns the forwarded sections of a plain text body, in order.
+// Everything before the first section is new content.
+func FindForwarded(text string) (sections []ForwardedSection) {
+	offset := 0
+	lines := strings.SplitAfter(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		start := offset
+		offset += len(line)
+
+		marker := strings.TrimSpace(line)
+		if !forwardMarkerPattern.MatchString(line) {
+			continue
+		}
+
+		// a line of underscores only introduces Outlook's header block
+		if strings.HasPrefix(marker, "_") && (i+1 >= len(lines) || !forwardHeaderPattern.MatchString(strings.TrimRight(lines[i+1], "\r\n"))) {
+			continue
+		}
+
+		s := ForwardedSection{Marker: marker, Start: start, Header: map[string]string{}}
+		for ; i+1 < len(lines); i++ {
+			next := strings.TrimRight(lines[i+1], "\r\n")
+			if strings.TrimSpace(next) == "" && len(s.Header) == 0 {
+				offset += len(lines[i+1])
+				continue
+			}
+
+			m := forwardHeaderPattern.FindStringSubmatch(next)
+			if m == nil {
+				break
+			}
+
+			s.Header[strings.TrimSpace(m[1])] = strings.TrimSpace(m[2])
+			offset += len(lines[i+1])
+		}
+
+		s.BodyStart = offset
+		if len(sections) > 0 {
+			sections[len(sections)-1].End = start
+		}
+
+		sections = append(sections, s)
+	}
+
+	if len(sections) > 0 {
+		sections[len(sections)-1].End = len(text)
+	}
+
+	return
+}
+
+// NewContent returns the text of a plain text body before its first forwarded section
+func NewContent(text string) string {
+	if sections := FindForwarded(text); len(sections) > 0 {
+		return strings.TrimRight(text[:sections[0].Start], " \t\r\n")
+	}
+
+	return text
+}
+
+// Forwarded returns the forwarded sections of the text body, see FindForwarded
+func (e Email) Forwarded() []ForwardedSection {
+	return FindForwarded(e.TextBody)
+}
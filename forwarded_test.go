@@ -0,0 +1,72 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindForwarded(t *testing.T) {
+	var testData = map[int]struct {
+		text       string
+		markers    []string
+		subjects   []string
+		bodies     []string
+		newContent string
+	}{
+		1: {
+			text:       "Just a message.\n",
+			newContent: "Just a message.\n",
+		},
+		2: {
+			text:       "FYI, see below.\n\nBegin forwarded message:\n\nFrom: Jane <jane@example.com>\nSubject: Lunch\nDate: 4 March 2019\n\nLet's have lunch.\n",
+			markers:    []string{"Begin forwarded message:"},
+			subjects:   []string{"Lunch"},
+			bodies:     []string{"\nLet's have lunch.\n"},
+			newContent: "FYI, see below.",
+		},
+		3: {
+			text:       "Thanks!\n\n-----Original Message-----\nFrom: John\nSent: Monday\nSubject: RE: Report\n\nHere it is.\n\n---------- Forwarded message ---------\nFrom: *Jane* <jane@example.com>\nSubject: Report\n\nThe report.\n",
+			markers:    []string{"-----Original Message-----", "---------- Forwarded message ---------"},
+			subjects:   []string{"RE: Report", "Report"},
+			bodies:     []string{"\nHere it is.\n\n", "\nThe report.\n"},
+			newContent: "Thanks!",
+		},
+		4: {
+			text:       "Sure.\r\n\r\n________________________________\r\nFrom: John\r\nSubject: Question\r\n\r\nAre you coming?\r\n",
+			markers:    []string{"________________________________"},
+			subjects:   []string{"Question"},
+			bodies:     []string{"\r\nAre you coming?\r\n"},
+			newContent: "Sure.",
+		},
+		5: {
+			text:       "Signature follows\n____________________\nJane\n",
+			newContent: "Signature follows\n____________________\nJane\n",
+		},
+	}
+
+	for index, td := range testData {
+		sections := FindForwarded(td.text)
+		if len(sections) != len(td.markers) {
+			t.Errorf("[Test Case %v] Wrong number of sections. Expected: %v, Got: %v", index, len(td.markers), len(sections))
+			continue
+		}
+
+		for i, s := range sections {
+			if s.Marker != td.markers[i] || s.Header["Subject"] != td.subjects[i] {
+				t.Errorf("[Test Case %v] Wrong section %v: %+v", index, i, s)
+			}
+
+			if body := td.text[s.BodyStart:s.End]; body != td.bodies[i] {
+				t.Errorf("[Test Case %v] Wrong body %v. Expected: %q, Got: %q", index, i, td.bodies[i], body)
+			}
+
+			if !strings.HasPrefix(td.text[s.Start:], s.Marker) {
+				t.Errorf("[Test Case %v] Section %v doesn't start at its marker", index, i)
+			}
+		}
+
+		if c := NewContent(td.text); c != td.newContent {
+			t.Errorf("[Test Case %v] Wrong new content. Expected: %q, Got: %q", index, td.newContent, c)
+		}
+	}
+}
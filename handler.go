@@ -0,0 +1,137 @@
+package parsemail
+
+import (
+	"encoding/json"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// bodyContentSecurityPolicy keeps scripts, forms and remote resources of untrusted HTML bodies from loading
+const bodyContentSecurityPolicy = "default-src 'none'; img-src data: cid:; style-src 'unsafe-inline'; sandbox"
+
+// MessageSource opens the raw message a request refers to. Returning an error
+// satisfying os.IsNotExist results in 404 Not Found.
+type MessageSource func(r *http.Request) (io.ReadCloser, error)
+
+// Handler serves rendered views of the message returned by its Source:
+//
+//	/headers.json      decoded header fields as JSON
+//	/body.html         HTML body, or the escaped text body when there is none
+//	/attachments/{n}   n-th attachment (counting from 0) with its content type
+//
+// Mount it under a per-message prefix with http.StripPrefix. Redact is
+// applied to the message before any view is rendered. The HTML body is passed
+// through SanitizeHTML before it is served, unless Unsanitized is set.
+type Handler struct {
+	Source      MessageSource
+	Redact      Redaction
+	Unsanitized bool
+}
+
+// NewHandler returns a Handler serving messages opened by src
+func NewHandler(src MessageSource) *Handler {
+	return &Handler{Source: src}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var serve func(http.ResponseWriter, *http.Request, Email)
+	switch p := r.URL.Path; {
+	case p == "/headers.json":
+		serve = serveHeaders
+	case p == "/body.html":
+		serve = func(w http.ResponseWriter, r *http.Request, e Email) {
+			if !h.Unsanitized && e.HTMLBody != "" {
+				e.HTMLBody = SanitizeHTML(e.HTMLBody)
+			}
+
+			serveBody(w, r, e)
+		}
+	case strings.HasPrefix(p, "/attachments/"):
+		n, err := strconv.Atoi(strings.TrimPrefix(p, "/attachments/"))
+		if err != nil || n < 0 {
+			http.NotFound(w, r)
+			return
+		}
+
+		serve = func(w http.ResponseWriter, r *http.Request, e Email) {
+			serveAttachment(w, r, e, n)
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	src, err := h.Source(r)
+	if os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer src.Close()
+
+	e, err := Parse(src)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	serve(w, r, e.Redacted(h.Redact))
+}
+
+func serveHeaders(w http.ResponseWriter, r *http.Request, e Email) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(e.Header)
+}
+
+func serveBody(w http.ResponseWriter, r *http.Request, e Email) {
+	body := e.HTMLBody
+	if body == "" {
+		body = "<pre>" + html.EscapeString(e.TextBody) + "</pre>"
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", bodyContentSecurityPolicy)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.WriteString(w, body)
+}
+
+func serveAttachment(w http.ResponseWriter, r *http.Request, e Email, n int) {
+	if n >= len(e.Attachments) {
+		http.NotFound(w, r)
+		return
+	}
+
+	a := e.Attachments[n]
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	disposition := "attachment"
+	if a.Filename != "" {
+		disposition = mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename})
+	}
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if sr, err := a.SectionReader(); err == nil {
+		http.ServeContent(w, r, "", e.Date, sr)
+		return
+	}
+
+	io.Copy(w, a.Data)
+}
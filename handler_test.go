@@ -0,0 +1,157 @@
+package parsemail
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	messages := map[string]string{
+		"data1": data1,
+		"data2": data2,
+		"text":  rfc5322exampleA11,
+	}
+
+	h := NewHandler(func(r *http.Request) (io.ReadCloser, error) {
+		m, ok := messages[r.URL.Query().Get("m")]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+
+		return ioutil.NopCloser(strings.NewReader(m)), nil
+	})
+	h.Redact = Redaction{Drop: []string{"User-Agent"}}
+
+	var testData = map[int]struct {
+		url         string
+		status      int
+		contentType string
+		check       func(string, *testing.T)
+	}{
+		1: {
+			url:         "/headers.json?m=data2",
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			check: func(body string, t *testing.T) {
+				var h map[string][]string
+				if err := json.Unmarshal([]byte(body), &h); err != nil {
+					t.Fatal(err)
+				}
+
+				if h["Subject"][0] != "Re: Test Subject 2" {
+					t.Errorf("Wrong subject header: %v", h["Subject"])
+				}
+
+				if _, ok := h["User-Agent"]; ok {
+					t.Errorf("User-Agent header was not redacted")
+				}
+			},
+		},
+		2: {
+			url:         "/body.html?m=data2",
+			status:      http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			check: func(body string, t *testing.T) {
+				if body != `<html>data<img src="part2.9599C449.04E5EC81@develhell.com"></html>` {
+					t.Errorf("Wrong body: %s", body)
+				}
+			},
+		},
+		3: {
+			url:         "/body.html?m=text",
+			status:      http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			check: func(body string, t *testing.T) {
+				if !strings.Contains(body, "So, &#34;Hello&#34;.") {
+					t.Errorf("Text body not escaped: %s", body)
+				}
+			},
+		},
+		4: {
+			url:         "/attachments/0?m=data1",
+			status:      http.StatusOK,
+			contentType: "application/pdf",
+			check: func(body string, t *testing.T) {
+				if !strings.HasPrefix(body, "%PDF-") {
+					t.Errorf("Wrong attachment body: %s", body)
+				}
+			},
+		},
+		5: {url: "/attachments/1?m=data1", status: http.StatusNotFound},
+		6: {url: "/attachments/x?m=data1", status: http.StatusNotFound},
+		7: {url: "/headers.json?m=missing", status: http.StatusNotFound},
+		8: {url: "/unknown?m=data1", status: http.StatusNotFound},
+	}
+
+	for index, td := range testData {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", td.url, nil))
+
+		if rec.Code != td.status {
+			t.Errorf("[Test Case %v] Wrong status. Expected: %v, Got: %v", index, td.status, rec.Code)
+			continue
+		}
+
+		if td.contentType != "" && rec.Header().Get("Content-Type") != td.contentType {
+			t.Errorf("[Test Case %v] Wrong content type. Expected: %s, Got: %s", index, td.contentType, rec.Header().Get("Content-Type"))
+		}
+
+		if td.check != nil {
+			td.check(rec.Body.String(), t)
+		}
+	}
+}
+
+func TestHandlerSanitize(t *testing.T) {
+	message := "From: jdoe@machine.example\nContent-Type: text/html\n\n<p onclick=\"steal()\">Hi<script>steal()</script></p>\n"
+	h := NewHandler(func(r *http.Request) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(message)), nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/body.html", nil))
+
+	if body := rec.Body.String(); !strings.HasPrefix(body, "<p>Hi</p>") {
+		t.Errorf("Body not sanitized: %s", body)
+	}
+
+	h.Unsanitized = true
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/body.html", nil))
+
+	if body := rec.Body.String(); !strings.Contains(body, "<script>") {
+		t.Errorf("Body sanitized despite Unsanitized: %s", body)
+	}
+}
+
+func TestHandlerAttachmentDisposition(t *testing.T) {
+	var testData = map[int]struct {
+		disposition string
+		expected    string
+	}{
+		1: {disposition: "attachment; filename=\"report.txt\"", expected: "attachment; filename=report.txt"},
+		2: {disposition: "attachment", expected: "attachment"},
+	}
+
+	for index, td := range testData {
+		message := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+			"--m\nContent-Type: text/plain\n\nbody\n" +
+			"--m\nContent-Type: text/plain\nContent-Disposition: " + td.disposition + "\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+		h := NewHandler(func(r *http.Request) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(message)), nil
+		})
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/attachments/0", nil))
+
+		if got := rec.Header().Get("Content-Disposition"); got != td.expected {
+			t.Errorf("[Test Case %v] Wrong Content-Disposition. Expected: %s, Got: %s", index, td.expected, got)
+		}
+	}
+}
@@ -0,0 +1,534 @@
+package parsemail
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"strings"
+)
+
+// HTMLNodeType is the type of a HTMLNode
+type HTMLNodeType int
+
+// HTML node types
+const (
+	HTMLDocumentNode HTMLNodeType = iota
+	HTMLElementNode
+	HTMLTextNode
+	HTMLCommentNode
+	HTMLDoctypeNode
+)
+
+// HTMLAttribute is an attribute of an element, Key is lower case and Val unescaped
+type HTMLAttribute struct {
+	Key string
+	Val string
+}
+
+// HTMLNode is a node of the tree built by ParseHTML. For elements Data is the
+// lower case tag name, for text and comments it's the unescaped content and for
+// doctypes the declaration.
+type HTMLNode struct {
+	Type HTMLNodeType
+	Data string
+	Attr []HTMLAttribute
+
+	Parent, FirstChild, LastChild, PrevSibling, NextSibling *HTMLNode
+}
+
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlRawTextElements hold text that is neither parsed for tags nor entity decoded
+var htmlRawTextElements = map[string]bool{
+	"script": true, "style": true, "xmp": true, "iframe": true, "noembed": true, "noframes": true,
+}
+
+// htmlRCDataElements hold text that is entity decoded but not parsed for tags
+var htmlRCDataElements = map[string]bool{
+	"textarea": true, "title": true,
+}
+
+// htmlClosesParagraph are the elements whose start tag implicitly ends an open <p>
+var htmlClosesParagraph = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "div": true, "dl": true,
+	"fieldset": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "menu": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "ul": true,
+}
+
+// htmlImpliedEnds maps elements to the open elements their start tag ends, up to the listed scope boundaries
+var htmlImpliedEnds = map[string]struct{ closes, scope []string }{
+	"li":     {[]string{"li"}, []string{"ul", "ol", "menu"}},
+	"dt":     {[]string{"dt", "dd"}, []string{"dl"}},
+	"dd":     {[]string{"dt", "dd"}, []string{"dl"}},
+	"tr":     {[]string{"tr"}, []string{"table", "tbody", "thead", "tfoot"}},
+	"td":     {[]string{"td", "th"}, []string{"tr", "table"}},
+	"th":     {[]string{"td", "th"}, []string{"tr", "table"}},
+	"option": {[]string{"option"}, []string{"select", "datalist"}},
+	"tbody":  {[]string{"thead", "tbody", "tfoot"}, []string{"table"}},
+	"thead":  {[]string{"thead", "tbody", "tfoot"}, []string{"table"}},
+	"tfoot":  {[]string{"thead", "tbody", "tfoot"}, []string{"table"}},
+}
+
+// ParseHTML builds a node tree out of the HTML in s. It is a lenient parser
+// meant for the HTML found in email bodies: unknown or misnested end tags are
+// dropped, common implied end tags are applied and no html, head or body
+// elements are synthesized, so fragments stay fragments.
+func ParseHTML(s string) *HTMLNode {
+	doc := &HTMLNode{Type: HTMLDocumentNode}
+	hp := htmlParser{s: s, stack: []*HTMLNode{doc}}
+	hp.parse()
+
+	return doc
+}
+
+// HTMLDocument parses the HTML body of the email, see ParseHTML
+func (e Email) HTMLDocument() *HTMLNode {
+	return ParseHTML(e.HTMLBody)
+}
+
+type htmlParser struct {
+	s     string
+	pos   int
+	stack []*HTMLNode
+}
+
+func (p *htmlParser) current() *HTMLNode {
+	return p.stack[len(p.stack)-1]
+}
+
+func (p *htmlParser) parse() {
+	for p.pos < len(p.s) {
+		lt := strings.IndexByte(p.s[p.pos:], '<')
+		if lt == -1 {
+			p.addText(html.UnescapeString(p.s[p.pos:]))
+			return
+		}
+
+		if lt > 0 {
+			p.addText(html.UnescapeString(p.s[p.pos : p.pos+lt]))
+			p.pos += lt
+		}
+
+		if !p.parseMarkup() {
+			// a lone "<" is text
+			p.addText("<")
+			p.pos++
+		}
+	}
+}
+
+// parseMarkup parses the tag, comment or declaration at p.pos, returning false if there is none
+func (p *htmlParser) parseMarkup() bool {
+	rest := p.s[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "<!--"):
+		end := strings.Index(rest[4:], "-->")
+		if end == -1 {
+			p.addChild(&HTMLNode{Type: HTMLCommentNode, Data: rest[4:]})
+			p.pos = len(p.s)
+		} else {
+			p.addChild(&HTMLNode{Type: HTMLCommentNode, Data: rest[4 : 4+end]})
+			p.pos += 4 + end + 3
+		}
+		return true
+	case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+		end := strings.IndexByte(rest, '>')
+		if end == -1 {
+			end = len(rest)
+			p.pos = len(p.s)
+		} else {
+			p.pos += end + 1
+		}
+
+		decl := rest[2:end]
+		if rest[1] == '!' && len(decl) >= 7 && strings.EqualFold(decl[:7], "doctype") {
+			p.addChild(&HTMLNode{Type: HTMLDoctypeNode, Data: strings.TrimSpace(decl[7:])})
+		} else {
+			p.addChild(&HTMLNode{Type: HTMLCommentNode, Data: decl})
+		}
+
+		return true
+	case strings.HasPrefix(rest, "</"):
+		if len(rest) < 3 || !isASCIILetter(rest[2]) {
+			return false
+		}
+
+		name, _ := scanTagName(rest[2:])
+		if end := strings.IndexByte(rest, '>'); end == -1 {
+			p.pos = len(p.s)
+		} else {
+			p.pos += end + 1
+		}
+
+		p.endElement(name)
+		return true
+	case len(rest) > 1 && isASCIILetter(rest[1]):
+		name, n := scanTagName(rest[1:])
+		attrs, selfClosing, consumed := scanAttributes(rest[1+n:])
+		p.pos += 1 + n + consumed
+
+		el := &HTMLNode{Type: HTMLElementNode, Data: name, Attr: attrs}
+		p.startElement(el)
+
+		if htmlVoidElements[name] || selfClosing {
+			p.stack = p.stack[:len(p.stack)-1]
+		} else if htmlRawTextElements[name] || htmlRCDataElements[name] {
+			p.parseRawText(el)
+		}
+		return true
+	}
+
+	return false
+}
+
+// parseRawText consumes the content of el up to its end tag
+func (p *htmlParser) parseRawText(el *HTMLNode) {
+	rest := p.s[p.pos:]
+	end := indexFold(rest, "</"+el.Data)
+	if end == -1 {
+		end = len(rest)
+	}
+
+	text := rest[:end]
+	if htmlRCDataElements[el.Data] {
+		text = html.UnescapeString(text)
+	}
+
+	if text != "" {
+		p.addText(text)
+	}
+
+	p.pos += end
+	if end < len(rest) {
+		if gt := strings.IndexByte(rest[end:], '>'); gt != -1 {
+			p.pos += gt + 1
+		} else {
+			p.pos = len(p.s)
+		}
+	}
+
+	p.endElement(el.Data)
+}
+
+func (p *htmlParser) startElement(el *HTMLNode) {
+	if htmlClosesParagraph[el.Data] && p.current().Data == "p" && p.current().Type == HTMLElementNode {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+
+	if ends, ok := htmlImpliedEnds[el.Data]; ok {
+		p.closeImplied(ends.closes, ends.scope)
+	}
+
+	p.addChild(el)
+	p.stack = append(p.stack, el)
+}
+
+// closeImplied pops the nearest open element listed in closes along with its descendants,
+// unless one of the scope elements is reached first
+func (p *htmlParser) closeImplied(closes, scope []string) {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		name := p.stack[i].Data
+		if containsString(scope, name) {
+			return
+		}
+
+		if containsString(closes, name) {
+			p.stack = p.stack[:i]
+			return
+		}
+	}
+}
+
+func (p *htmlParser) endElement(name string) {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].Data == name {
+			p.stack = p.stack[:i]
+			return
+		}
+	}
+}
+
+func (p *htmlParser) addText(text string) {
+	if last := p.current().LastChild; last != nil && last.Type == HTMLTextNode {
+		last.Data += text
+		return
+	}
+
+	p.addChild(&HTMLNode{Type: HTMLTextNode, Data: text})
+}
+
+func (p *htmlParser) addChild(n *HTMLNode) {
+	p.current().AppendChild(n)
+}
+
+func scanTagName(s string) (string, int) {
+	n := 0
+	for n < len(s) && !isHTMLSpace(s[n]) && s[n] != '/' && s[n] != '>' {
+		n++
+	}
+
+	return strings.ToLower(s[:n]), n
+}
+
+// scanAttributes parses attributes up to and including the closing ">" of a start tag
+func scanAttributes(s string) (attrs []HTMLAttribute, selfClosing bool, n int) {
+	for n < len(s) {
+		for n < len(s) && (isHTMLSpace(s[n]) || s[n] == '/') {
+			selfClosing = s[n] == '/'
+			n++
+		}
+
+		if n >= len(s) {
+			break
+		}
+
+		if s[n] == '>' {
+			return attrs, selfClosing, n + 1
+		}
+
+		selfClosing = false
+		start := n
+		for n < len(s) && !isHTMLSpace(s[n]) && s[n] != '=' && s[n] != '>' && s[n] != '/' {
+			n++
+		}
+		if n == start {
+			// a stray "=" without a name
+			n++
+			continue
+		}
+		key := strings.ToLower(s[start:n])
+
+		for n < len(s) && isHTMLSpace(s[n]) {
+			n++
+		}
+
+		val := ""
+		if n < len(s) && s[n] == '=' {
+			n++
+			for n < len(s) && isHTMLSpace(s[n]) {
+				n++
+			}
+
+			if n < len(s) && (s[n] == '"' || s[n] == '\'') {
+				q := s[n]
+				if end := strings.IndexByte(s[n+1:], q); end == -1 {
+					val = s[n+1:]
+					n = len(s)
+				} else {
+					val = s[n+1 : n+1+end]
+					n += end + 2
+				}
+			} else {
+				start := n
+				for n < len(s) && !isHTMLSpace(s[n]) && s[n] != '>' {
+					n++
+				}
+				val = s[start:n]
+			}
+		}
+
+		attrs = append(attrs, HTMLAttribute{Key: key, Val: html.UnescapeString(val)})
+	}
+
+	return attrs, selfClosing, n
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// indexFold is strings.Index ignoring ASCII case
+func indexFold(s, substr string) int {
+	return strings.Index(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// AppendChild adds c as the last child of n, c must not have a parent
+func (n *HTMLNode) AppendChild(c *HTMLNode) {
+	c.Parent = n
+	c.PrevSibling = n.LastChild
+	if n.LastChild != nil {
+		n.LastChild.NextSibling = c
+	} else {
+		n.FirstChild = c
+	}
+	n.LastChild = c
+}
+
+// InsertBefore inserts c as a child of n before oldChild, or as the last child if oldChild is nil
+func (n *HTMLNode) InsertBefore(c, oldChild *HTMLNode) {
+	if oldChild == nil {
+		n.AppendChild(c)
+		return
+	}
+
+	c.Parent = n
+	c.NextSibling = oldChild
+	c.PrevSibling = oldChild.PrevSibling
+	if oldChild.PrevSibling != nil {
+		oldChild.PrevSibling.NextSibling = c
+	} else {
+		n.FirstChild = c
+	}
+	oldChild.PrevSibling = c
+}
+
+// RemoveChild detaches the child c from n
+func (n *HTMLNode) RemoveChild(c *HTMLNode) {
+	if c.Parent != n {
+		return
+	}
+
+	if c.PrevSibling != nil {
+		c.PrevSibling.NextSibling = c.NextSibling
+	} else {
+		n.FirstChild = c.NextSibling
+	}
+
+	if c.NextSibling != nil {
+		c.NextSibling.PrevSibling = c.PrevSibling
+	} else {
+		n.LastChild = c.PrevSibling
+	}
+
+	c.Parent, c.PrevSibling, c.NextSibling = nil, nil, nil
+}
+
+// Attribute returns the value of the attribute key
+func (n *HTMLNode) Attribute(key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+
+	return "", false
+}
+
+// SetAttribute sets the attribute key to val, adding it if needed
+func (n *HTMLNode) SetAttribute(key, val string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+
+	n.Attr = append(n.Attr, HTMLAttribute{Key: key, Val: val})
+}
+
+// RemoveAttribute removes the attribute key
+func (n *HTMLNode) RemoveAttribute(key string) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		if a.Key != key {
+			attrs = append(attrs, a)
+		}
+	}
+	n.Attr = attrs
+}
+
+// Walk calls fn for n and its descendants in document order. When fn returns
+// false the children of that node are skipped. fn may remove the node it is
+// called with from the tree.
+func (n *HTMLNode) Walk(fn func(*HTMLNode) bool) {
+	if !fn(n) {
+		return
+	}
+
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		c.Walk(fn)
+		c = next
+	}
+}
+
+// Text returns the concatenated text content of n and its descendants
+func (n *HTMLNode) Text() string {
+	var sb strings.Builder
+	n.Walk(func(c *HTMLNode) bool {
+		if c.Type == HTMLTextNode {
+			sb.WriteString(c.Data)
+		}
+
+		return c.Type != HTMLElementNode || !htmlRawTextElements[c.Data]
+	})
+
+	return sb.String()
+}
+
+// Render writes n and its descendants to w as HTML
+func (n *HTMLNode) Render(w io.Writer) error {
+	var buf bytes.Buffer
+	n.render(&buf)
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+// String renders n and its descendants as HTML
+func (n *HTMLNode) String() string {
+	var buf bytes.Buffer
+	n.render(&buf)
+
+	return buf.String()
+}
+
+func (n *HTMLNode) render(buf *bytes.Buffer) {
+	switch n.Type {
+	case HTMLTextNode:
+		if p := n.Parent; p != nil && p.Type == HTMLElementNode && htmlRawTextElements[p.Data] {
+			buf.WriteString(n.Data)
+		} else {
+			buf.WriteString(escapeHTMLText(n.Data))
+		}
+		return
+	case HTMLCommentNode:
+		buf.WriteString("<!--" + n.Data + "-->")
+		return
+	case HTMLDoctypeNode:
+		buf.WriteString("<!DOCTYPE " + n.Data + ">")
+		return
+	case HTMLElementNode:
+		buf.WriteString("<" + n.Data)
+		for _, a := range n.Attr {
+			buf.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+		}
+		buf.WriteString(">")
+
+		if htmlVoidElements[n.Data] {
+			return
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		c.render(buf)
+	}
+
+	if n.Type == HTMLElementNode {
+		buf.WriteString("</" + n.Data + ">")
+	}
+}
+
+var htmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\u00a0", "&nbsp;")
+
+func escapeHTMLText(s string) string {
+	return htmlTextEscaper.Replace(s)
+}
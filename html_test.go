@@ -0,0 +1,98 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHTML(t *testing.T) {
+	var testData = map[int]struct {
+		html     string
+		rendered string
+		text     string
+	}{
+		1: {
+			html:     `<html>data<img src="part2.9599C449.04E5EC81@develhell.com"/></html>`,
+			rendered: `<html>data<img src="part2.9599C449.04E5EC81@develhell.com"></html>`,
+			text:     "data",
+		},
+		2: {
+			html:     `<!DOCTYPE html><div dir=ltr class='a b'>Fish &amp; chips<br></div>`,
+			rendered: `<!DOCTYPE html><div dir="ltr" class="a b">Fish &amp; chips<br></div>`,
+			text:     "Fish & chips",
+		},
+		3: {
+			html:     `<ul><li>one<li>two</ul><p>first<p>second<div>block</div>`,
+			rendered: `<ul><li>one</li><li>two</li></ul><p>first</p><p>second</p><div>block</div>`,
+			text:     "onetwofirstsecondblock",
+		},
+		4: {
+			html:     `<script>if (a < b && c) { x = "</b>" }</script><!-- note --><b>bold</i></b>`,
+			rendered: `<script>if (a < b && c) { x = "</b>" }</script><!-- note --><b>bold</b>`,
+			text:     "bold",
+		},
+		5: {
+			html:     `<table><tr><td>a<td>b<tr><td>c</table>1 < 2`,
+			rendered: `<table><tr><td>a</td><td>b</td></tr><tr><td>c</td></tr></table>1 &lt; 2`,
+			text:     "abc1 < 2",
+		},
+		6: {
+			html:     `<A HREF="http://example.com/?a=1&amp;b=2" onClick=evil()>link</A>`,
+			rendered: `<a href="http://example.com/?a=1&amp;b=2" onclick="evil()">link</a>`,
+			text:     "link",
+		},
+		7: {
+			html:     `<title>A &amp; B</title><p>unterminated <b>tags`,
+			rendered: `<title>A &amp; B</title><p>unterminated <b>tags</b></p>`,
+			text:     "A & Bunterminated tags",
+		},
+	}
+
+	for index, td := range testData {
+		doc := ParseHTML(td.html)
+
+		if rendered := doc.String(); rendered != td.rendered {
+			t.Errorf("[Test Case %v] Wrong rendering. Expected: '%s', Got: '%s'", index, td.rendered, rendered)
+		}
+
+		if text := doc.Text(); text != td.text {
+			t.Errorf("[Test Case %v] Wrong text. Expected: '%s', Got: '%s'", index, td.text, text)
+		}
+	}
+}
+
+func TestHTMLNodeManipulation(t *testing.T) {
+	doc := ParseHTML(`<div><img src="cid:a"><script>x()</script><a href="http://example.com">x</a></div>`)
+
+	doc.Walk(func(n *HTMLNode) bool {
+		if n.Type != HTMLElementNode {
+			return true
+		}
+
+		switch n.Data {
+		case "script":
+			n.Parent.RemoveChild(n)
+			return false
+		case "img":
+			if src, _ := n.Attribute("src"); strings.HasPrefix(src, "cid:") {
+				n.SetAttribute("src", "data:image/png;base64,AA==")
+				n.SetAttribute("alt", "inline")
+			}
+		case "a":
+			n.RemoveAttribute("href")
+			n.Parent.InsertBefore(&HTMLNode{Type: HTMLTextNode, Data: "[link] "}, n)
+		}
+
+		return true
+	})
+
+	expected := `<div><img src="data:image/png;base64,AA==" alt="inline">[link] <a>x</a></div>`
+	if rendered := doc.String(); rendered != expected {
+		t.Errorf("Wrong rendering. Expected: '%s', Got: '%s'", expected, rendered)
+	}
+
+	e := Email{HTMLBody: "<p>hello</p>"}
+	if text := e.HTMLDocument().Text(); text != "hello" {
+		t.Errorf("Wrong document text: '%s'", text)
+	}
+}
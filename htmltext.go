@@ -0,0 +1,230 @@
+package parsemail
+
+import (
+	"strconv"
+	"strings"
+)
+
+// htmlTextSkipped are elements whose content isn't rendered by HTMLToText
+var htmlTextSkipped = map[string]bool{
+	"head": true, "script": true, "style": true, "template": true, "title": true, "noscript": true,
+}
+
+// htmlTextParagraphs are block elements separated from their surroundings by a blank line
+var htmlTextParagraphs = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"table": true, "pre": true, "dl": true, "figure": true, "form": true, "fieldset": true,
+}
+
+// htmlTextLines are block elements that start on a new line
+var htmlTextLines = map[string]bool{
+	"div": true, "tr": true, "dt": true, "dd": true, "section": true, "article": true, "header": true,
+	"footer": true, "nav": true, "aside": true, "main": true, "address": true, "center": true,
+	"caption": true, "figcaption": true, "tbody": true, "thead": true, "tfoot": true,
+}
+
+// HTMLToText renders the HTML in s as readable plain text: block elements are
+// separated by line breaks, lists are bulleted or numbered, quotes are
+// prefixed with "> " and link targets are kept in parentheses after the link
+// text. Scripts, styles and the document head are dropped.
+func HTMLToText(s string) string {
+	w := &htmlTextWriter{}
+	w.render(ParseHTML(s))
+
+	return w.String()
+}
+
+// htmlTextWriter collapses whitespace the way browsers do and emits the line
+// breaks requested by block elements only once text follows them
+type htmlTextWriter struct {
+	sb     strings.Builder
+	breaks int
+	space  bool
+	lists  []int
+}
+
+func (w *htmlTextWriter) String() string {
+	return w.sb.String()
+}
+
+// blockBreak requests at least n line breaks before the next text
+func (w *htmlTextWriter) blockBreak(n int) {
+	if w.sb.Len() > 0 && n > w.breaks {
+		w.breaks = n
+	}
+	w.space = false
+}
+
+// flush writes the pending line breaks or space
+func (w *htmlTextWriter) flush() {
+	if w.breaks > 0 {
+		w.sb.WriteString(strings.Repeat("\n", w.breaks))
+	} else if w.space {
+		w.sb.WriteByte(' ')
+	}
+
+	w.breaks, w.space = 0, false
+}
+
+// text writes s collapsing white space, non-breaking spaces are kept as spaces
+func (w *htmlTextWriter) text(s string) {
+	for _, r := range s {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f':
+			if w.sb.Len() > 0 && w.breaks == 0 {
+				w.space = true
+			}
+		case '\u00a0':
+			w.flush()
+			w.sb.WriteByte(' ')
+		default:
+			w.flush()
+			w.sb.WriteRune(r)
+		}
+	}
+}
+
+// raw writes s with its white space preserved
+func (w *htmlTextWriter) raw(s string) {
+	if s == "" {
+		return
+	}
+
+	w.flush()
+	w.sb.WriteString(s)
+}
+
+func (w *htmlTextWriter) render(n *HTMLNode) {
+	switch n.Type {
+	case HTMLTextNode:
+		w.text(n.Data)
+		return
+	case HTMLCommentNode, HTMLDoctypeNode:
+		return
+	case HTMLElementNode:
+		if htmlTextSkipped[n.Data] {
+			return
+		}
+	}
+
+	switch name := n.Data; {
+	case n.Type != HTMLElementNode:
+		w.renderChildren(n)
+	case name == "br":
+		w.space = false
+		if w.sb.Len() > 0 && w.breaks < 2 {
+			w.breaks++
+		}
+	case name == "hr":
+		w.blockBreak(2)
+		w.raw("----")
+		w.blockBreak(2)
+	case name == "img":
+		if alt, _ := n.Attribute("alt"); strings.TrimSpace(alt) != "" {
+			w.text(alt)
+		}
+	case name == "pre":
+		w.blockBreak(2)
+		w.raw(strings.TrimRight(strings.TrimPrefix(n.Text(), "\n"), "\n"))
+		w.blockBreak(2)
+	case name == "blockquote":
+		quoted := &htmlTextWriter{}
+		quoted.renderChildren(n)
+		if quoted.sb.Len() == 0 {
+			return
+		}
+
+		lines := strings.Split(quoted.String(), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+
+		w.blockBreak(2)
+		w.raw(strings.Join(lines, "\n"))
+		w.blockBreak(2)
+	case name == "ul" || name == "ol":
+		if len(w.lists) == 0 {
+			w.blockBreak(2)
+		} else {
+			w.blockBreak(1)
+		}
+
+		counter := -1
+		if name == "ol" {
+			counter = 0
+		}
+
+		w.lists = append(w.lists, counter)
+		w.renderChildren(n)
+		w.lists = w.lists[:len(w.lists)-1]
+
+		if len(w.lists) == 0 {
+			w.blockBreak(2)
+		} else {
+			w.blockBreak(1)
+		}
+	case name == "li":
+		w.blockBreak(1)
+		marker := "- "
+		if depth := len(w.lists); depth > 0 {
+			if w.lists[depth-1] >= 0 {
+				w.lists[depth-1]++
+				marker = strconv.Itoa(w.lists[depth-1]) + ". "
+			}
+			marker = strings.Repeat("  ", depth-1) + marker
+		}
+
+		w.raw(marker)
+		w.renderChildren(n)
+		w.blockBreak(1)
+	case name == "td" || name == "th":
+		if w.sb.Len() > 0 && w.breaks == 0 {
+			w.space = true
+		}
+		w.renderChildren(n)
+		if w.sb.Len() > 0 && w.breaks == 0 {
+			w.space = true
+		}
+	case name == "a":
+		w.renderChildren(n)
+		href, _ := n.Attribute("href")
+		if target := htmlTextLinkTarget(href, n.Text()); target != "" {
+			w.text(" (" + target + ")")
+		}
+	case htmlTextParagraphs[name]:
+		w.blockBreak(2)
+		w.renderChildren(n)
+		w.blockBreak(2)
+	case htmlTextLines[name]:
+		w.blockBreak(1)
+		w.renderChildren(n)
+		w.blockBreak(1)
+	default:
+		w.renderChildren(n)
+	}
+}
+
+func (w *htmlTextWriter) renderChildren(n *HTMLNode) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.render(c)
+	}
+}
+
+// htmlTextLinkTarget returns the target of a link worth showing next to its text,
+// which is none for fragments, scripts and links whose text is the target itself
+func htmlTextLinkTarget(href, text string) string {
+	href = strings.TrimSpace(href)
+	text = strings.Join(strings.Fields(text), " ")
+	lower := strings.ToLower(href)
+
+	switch {
+	case href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(lower, "javascript:"):
+		return ""
+	case href == text || strings.TrimSuffix(href, "/") == text:
+		return ""
+	case strings.HasPrefix(lower, "mailto:") && strings.EqualFold(href[len("mailto:"):], text):
+		return ""
+	}
+
+	return href
+}
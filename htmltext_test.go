@@ -0,0 +1,70 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToText(t *testing.T) {
+	var testData = map[int]struct {
+		html string
+		text string
+	}{
+		1: {
+			html: "<html><head><title>Hi</title><style>p{color:red}</style></head><body><p>Hello   <b>world</b>!</p><p>Second\nparagraph</p></body></html>",
+			text: "Hello world!\n\nSecond paragraph",
+		},
+		2: {
+			html: `<div>Visit <a href="https://example.com/offer">our offer</a> or <a href="https://example.com">https://example.com</a></div><div>Mail <a href="mailto:jane@example.org">jane@example.org</a></div>`,
+			text: "Visit our offer (https://example.com/offer) or https://example.com\nMail jane@example.org",
+		},
+		3: {
+			html: "<p>Items:</p><ul><li>One</li><li>Two<ol><li>A</li><li>B</li></ol></li></ul><p>End</p>",
+			text: "Items:\n\n- One\n- Two\n  1. A\n  2. B\n\nEnd",
+		},
+		4: {
+			html: "Line one<br>Line two<br><br>Line four<hr><blockquote><p>Quoted</p><p>Text</p></blockquote>",
+			text: "Line one\nLine two\n\nLine four\n\n----\n\n> Quoted\n>\n> Text",
+		},
+		5: {
+			html: "<table><tr><th>Name</th><th>Qty</th></tr><tr><td>Apples</td><td>3</td></tr></table><pre>  a  b\n c</pre><img src=x alt=\"Logo\">",
+			text: "Name Qty\nApples 3\n\n  a  b\n c\n\nLogo",
+		},
+		6: {
+			html: "<p>a&nbsp;&nbsp;b &amp; c<script>alert(1)</script></p>",
+			text: "a  b & c",
+		},
+	}
+
+	for index, td := range testData {
+		if got := HTMLToText(td.html); got != td.text {
+			t.Errorf("[Test Case %v] Wrong text. Expected: %q, Got: %q", index, td.text, got)
+		}
+	}
+}
+
+func TestHTMLToTextFallback(t *testing.T) {
+	mailData := "From: John Doe <jdoe@machine.example>\nContent-Type: text/html\n\n<p>Hello <a href=\"https://example.com/x\">there</a></p><p>Bye</p>\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.TextBody != "" {
+		t.Errorf("Expected no text body without the option, Got: %q", e.TextBody)
+	}
+
+	e, err = ParseWithOptions(strings.NewReader(mailData), WithHTMLToTextFallback())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "Hello there (https://example.com/x)\n\nBye"; e.TextBody != expected {
+		t.Errorf("Wrong text body. Expected: %q, Got: %q", expected, e.TextBody)
+	}
+
+	if e.TextAsHTML != "" {
+		t.Errorf("Expected no TextAsHTML for a message with a HTML body, Got: %q", e.TextAsHTML)
+	}
+}
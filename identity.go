@@ -0,0 +1,106 @@
+package parsemail
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var displayNameAddressPattern = regexp.MustCompile(`[^\s<>()"',;:]+@[^\s<>()"',;:]+\.[a-zA-Z]{2,}`)
+
+// SenderIdentity consolidates everything a message claims about its sender,
+// with flags for the mismatches most commonly seen in phishing:
+//
+//	ReplyToDiffers       a Reply-To address is in another domain than From
+//	SenderDiffers        the Sender is in another domain than From
+//	ReturnPathMisaligned the Return-Path (envelope sender) domain isn't aligned with From
+//	DKIMMisaligned       no DKIM signature domain is aligned with From, or there is no signature
+//	DisplayNameMismatch  the display name contains an address other than the From address
+//
+// Domains are aligned when equal or when one is a subdomain of the other.
+type SenderIdentity struct {
+	From        *mail.Address
+	DisplayName string
+	FromDomain  string
+	Sender      *mail.Address
+	ReplyTo     []*mail.Address
+	ReturnPath  string
+	DKIMDomains []string
+
+	ReplyToDiffers       bool
+	SenderDiffers        bool
+	ReturnPathMisaligned bool
+	DKIMMisaligned       bool
+	DisplayNameMismatch  bool
+}
+
+// SenderIdentity returns the consolidated sender identity of the email
+func (e Email) SenderIdentity() (si SenderIdentity) {
+	if len(e.From) > 0 {
+		si.From = e.From[0]
+		si.DisplayName = si.From.Name
+		si.FromDomain = addressDomain(si.From.Address)
+	}
+
+	si.Sender = e.Sender
+	si.ReplyTo = e.ReplyTo
+	si.ReturnPath = strings.Trim(strings.TrimSpace(e.Header.Get("Return-Path")), "<>")
+
+	for _, sig := range e.Header["Dkim-Signature"] {
+		if d := strings.ToLower(parseTagList(sig)["d"]); d != "" {
+			si.DKIMDomains = append(si.DKIMDomains, d)
+		}
+	}
+
+	if si.FromDomain == "" {
+		return
+	}
+
+	for _, a := range si.ReplyTo {
+		if addressDomain(a.Address) != si.FromDomain {
+			si.ReplyToDiffers = true
+		}
+	}
+
+	if si.Sender != nil && addressDomain(si.Sender.Address) != si.FromDomain {
+		si.SenderDiffers = true
+	}
+
+	if si.ReturnPath != "" && !domainsAligned(addressDomain(si.ReturnPath), si.FromDomain) {
+		si.ReturnPathMisaligned = true
+	}
+
+	si.DKIMMisaligned = true
+	for _, d := range si.DKIMDomains {
+		if domainsAligned(d, si.FromDomain) {
+			si.DKIMMisaligned = false
+		}
+	}
+
+	for _, a := range displayNameAddressPattern.FindAllString(si.DisplayName, -1) {
+		if !strings.EqualFold(a, si.From.Address) {
+			si.DisplayNameMismatch = true
+		}
+	}
+
+	return
+}
+
+// addressDomain returns the lower case domain of an address, or "" if it has none
+func addressDomain(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at == -1 {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSuffix(address[at+1:], "."))
+}
+
+// domainsAligned reports whether a and b are the same domain or one is a subdomain of the other
+func domainsAligned(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	return a == b || strings.HasSuffix(a, "."+b) || strings.HasSuffix(b, "."+a)
+}
@@ -0,0 +1,77 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSenderIdentity(t *testing.T) {
+	var testData = map[int]struct {
+		mailData            string
+		fromDomain          string
+		returnPath          string
+		dkimDomains         []string
+		replyToDiffers      bool
+		senderDiffers       bool
+		returnPathMisalign  bool
+		dkimMisaligned      bool
+		displayNameMismatch bool
+	}{
+		1: {
+			mailData: `Return-Path: <bounces@mail.example.com>
+DKIM-Signature: v=1; a=rsa-sha256; d=example.com; s=sel;
+ h=from:to; bh=abc; b=def
+From: Example Support <support@example.com>
+Reply-To: help@example.com
+To: mary@example.net
+
+Hi.
+`,
+			fromDomain:  "example.com",
+			returnPath:  "bounces@mail.example.com",
+			dkimDomains: []string{"example.com"},
+		},
+		2: {
+			mailData: `Return-Path: <x@bulk.test>
+DKIM-Signature: v=1; a=rsa-sha256; d=bulk.test; s=sel; b=def
+From: "support@bank.example" <attacker@evil.test>
+Sender: relay@bulk.test
+Reply-To: collect@other.test
+To: mary@example.net
+
+Hi.
+`,
+			fromDomain:          "evil.test",
+			returnPath:          "x@bulk.test",
+			dkimDomains:         []string{"bulk.test"},
+			replyToDiffers:      true,
+			senderDiffers:       true,
+			returnPathMisalign:  true,
+			dkimMisaligned:      true,
+			displayNameMismatch: true,
+		},
+		3: {
+			mailData:       rfc5322exampleA11,
+			fromDomain:     "machine.example",
+			dkimMisaligned: true,
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Fatalf("[Test Case %v] %v", index, err)
+		}
+
+		si := e.SenderIdentity()
+		if si.FromDomain != td.fromDomain || si.ReturnPath != td.returnPath || !assertSliceEq(si.DKIMDomains, td.dkimDomains) {
+			t.Errorf("[Test Case %v] Wrong identity: %+v", index, si)
+		}
+
+		if si.ReplyToDiffers != td.replyToDiffers || si.SenderDiffers != td.senderDiffers ||
+			si.ReturnPathMisaligned != td.returnPathMisalign || si.DKIMMisaligned != td.dkimMisaligned ||
+			si.DisplayNameMismatch != td.displayNameMismatch {
+			t.Errorf("[Test Case %v] Wrong mismatch flags: %+v", index, si)
+		}
+	}
+}
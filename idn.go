@@ -0,0 +1,272 @@
+package parsemail
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	acePrefix = "xn--"
+
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+// UnicodeDomain returns domain with all punycode (xn--) labels decoded to Unicode.
+// Labels which are not valid punycode are left as they are.
+func UnicodeDomain(domain string) string {
+	labels := strings.Split(domain, ".")
+	for i, l := range labels {
+		if !strings.HasPrefix(strings.ToLower(l), acePrefix) {
+			continue
+		}
+
+		if decoded, err := punycodeDecode(l[len(acePrefix):]); err == nil {
+			labels[i] = decoded
+		}
+	}
+
+	return strings.Join(labels, ".")
+}
+
+// ASCIIDomain returns domain with all non-ASCII labels encoded as punycode (xn--) labels.
+// Labels are lowercased; no further IDNA mapping is applied.
+func ASCIIDomain(domain string) (string, error) {
+	labels := strings.Split(domain, ".")
+	for i, l := range labels {
+		if isASCII(l) {
+			continue
+		}
+
+		encoded, err := punycodeEncode(strings.ToLower(l))
+		if err != nil {
+			return "", err
+		}
+
+		labels[i] = acePrefix + encoded
+	}
+
+	return strings.Join(labels, "."), nil
+}
+
+// UnicodeAddress returns the address with its domain decoded to Unicode, for display
+func UnicodeAddress(a *mail.Address) string {
+	local, domain := splitAddress(a.Address)
+	if domain == "" {
+		return a.Address
+	}
+
+	return local + "@" + UnicodeDomain(domain)
+}
+
+// ASCIIAddress returns the address with its domain encoded as punycode, as used on the wire.
+// A non-ASCII local part is left as it is, such addresses can only be used with SMTPUTF8.
+func ASCIIAddress(a *mail.Address) (string, error) {
+	local, domain := splitAddress(a.Address)
+	if domain == "" {
+		return a.Address, nil
+	}
+
+	ascii, err := ASCIIDomain(domain)
+	if err != nil {
+		return "", err
+	}
+
+	return local + "@" + ascii, nil
+}
+
+// requiresSMTPUTF8 reports whether the raw header has UTF-8 encoded fields (RFC 6532),
+// like non-ASCII addresses, which can only be transported with SMTPUTF8 (RFC 6531)
+func requiresSMTPUTF8(rawHeader []byte) bool {
+	return !isASCII(string(rawHeader)) && utf8.Valid(rawHeader)
+}
+
+func splitAddress(address string) (local, domain string) {
+	i := strings.LastIndex(address, "@")
+	if i == -1 {
+		return address, ""
+	}
+
+	return address[:i], address[i+1:]
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// punycodeAdapt is the bias adaptation function of RFC 3492 section 6.1
+func punycodeAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+func punycodeThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punyTMin
+	case k >= bias+punyTMax:
+		return punyTMax
+	}
+
+	return k - bias
+}
+
+func punycodeDigit(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 26, true
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A'), true
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a'), true
+	}
+
+	return 0, false
+}
+
+// punycodeDecode decodes a punycode label without its xn-- prefix (RFC 3492 section 6.2)
+func punycodeDecode(s string) (string, error) {
+	var output []rune
+	if i := strings.LastIndex(s, "-"); i != -1 {
+		for _, r := range s[:i] {
+			if r >= utf8.RuneSelf {
+				return "", fmt.Errorf("Invalid punycode: %s", s)
+			}
+			output = append(output, r)
+		}
+		s = s[i+1:]
+	}
+
+	n, bias, i := punyInitialN, punyInitialBias, 0
+	for pos := 0; pos < len(s); {
+		oldi, w := i, 1
+		for k := punyBase; ; k += punyBase {
+			if pos >= len(s) {
+				return "", fmt.Errorf("Invalid punycode: %s", s)
+			}
+
+			digit, ok := punycodeDigit(s[pos])
+			pos++
+			if !ok || digit > (1<<31-1-i)/w {
+				return "", fmt.Errorf("Invalid punycode: %s", s)
+			}
+
+			i += digit * w
+			t := punycodeThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			w *= punyBase - t
+		}
+
+		bias = punycodeAdapt(i-oldi, len(output)+1, oldi == 0)
+		n += i / (len(output) + 1)
+		i %= len(output) + 1
+		if n > utf8.MaxRune {
+			return "", fmt.Errorf("Invalid punycode: %s", s)
+		}
+
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = rune(n)
+		i++
+	}
+
+	return string(output), nil
+}
+
+// punycodeEncode encodes a label as punycode without the xn-- prefix (RFC 3492 section 6.3)
+func punycodeEncode(s string) (string, error) {
+	input := []rune(s)
+	var sb strings.Builder
+	for _, r := range input {
+		if r < utf8.RuneSelf {
+			sb.WriteRune(r)
+		}
+	}
+
+	basic := sb.Len()
+	handled := basic
+	if basic > 0 {
+		sb.WriteByte('-')
+	}
+
+	n, delta, bias := punyInitialN, 0, punyInitialBias
+	for handled < len(input) {
+		m := int(utf8.MaxRune) + 1
+		for _, r := range input {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+
+		if (m-n)*(handled+1) > 1<<31-1-delta {
+			return "", fmt.Errorf("Punycode overflow: %s", s)
+		}
+
+		delta += (m - n) * (handled + 1)
+		n = m
+		for _, r := range input {
+			if int(r) < n {
+				delta++
+			}
+
+			if int(r) != n {
+				continue
+			}
+
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := punycodeThreshold(k, bias)
+				if q < t {
+					break
+				}
+				sb.WriteByte(punycodeEncodeDigit(t + (q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+
+			sb.WriteByte(punycodeEncodeDigit(q))
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+
+		delta++
+		n++
+	}
+
+	return sb.String(), nil
+}
+
+func punycodeEncodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+
+	return byte('0' + d - 26)
+}
@@ -0,0 +1,91 @@
+package parsemail
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestIDNDomains(t *testing.T) {
+	var testData = map[int]struct {
+		ascii   string
+		unicode string
+	}{
+		1: {ascii: "example.com", unicode: "example.com"},
+		2: {ascii: "xn--bcher-kva.example", unicode: "bücher.example"},
+		3: {ascii: "mail.xn--mnchen-3ya.de", unicode: "mail.münchen.de"},
+		4: {ascii: "xn--fsqu00a.xn--0zwm56d", unicode: "例子.测试"},
+		5: {ascii: "xn--e1afmkfd.xn--p1ai", unicode: "пример.рф"},
+	}
+
+	for index, td := range testData {
+		if u := UnicodeDomain(td.ascii); u != td.unicode {
+			t.Errorf("[Test Case %v] Wrong unicode domain. Expected: '%s', Got: '%s'", index, td.unicode, u)
+		}
+
+		a, err := ASCIIDomain(td.unicode)
+		if err != nil || a != td.ascii {
+			t.Errorf("[Test Case %v] Wrong ascii domain. Expected: '%s', Got: '%s' (%v)", index, td.ascii, a, err)
+		}
+	}
+
+	if u := UnicodeDomain("xn--!!.example"); u != "xn--!!.example" {
+		t.Errorf("Invalid punycode label should be left as is, got '%s'", u)
+	}
+}
+
+func TestIDNAddresses(t *testing.T) {
+	a := &mail.Address{Name: "Jane", Address: "jane@xn--bcher-kva.example"}
+	if u := UnicodeAddress(a); u != "jane@bücher.example" {
+		t.Errorf("Wrong unicode address. Expected: 'jane@bücher.example', Got: '%s'", u)
+	}
+
+	a = &mail.Address{Address: "jane@bücher.example"}
+	if s, err := ASCIIAddress(a); err != nil || s != "jane@xn--bcher-kva.example" {
+		t.Errorf("Wrong ascii address. Expected: 'jane@xn--bcher-kva.example', Got: '%s' (%v)", s, err)
+	}
+}
+
+func TestUTF8Addresses(t *testing.T) {
+	var testData = map[int]struct {
+		mailData         string
+		from             string
+		fromName         string
+		requiresSMTPUTF8 bool
+	}{
+		1: {
+			mailData: "From: Jöhn Dœ <jöhn@exämple.com>\nTo: a@example.com\n\nbody\n",
+			from:     "jöhn@exämple.com",
+			fromName: "Jöhn Dœ",
+
+			requiresSMTPUTF8: true,
+		},
+		2: {
+			mailData: "From: 用户@例子.广告\n\nbody\n",
+			from:     "用户@例子.广告",
+
+			requiresSMTPUTF8: true,
+		},
+		3: {
+			mailData: "From: =?utf-8?q?J=C3=B6hn?= <john@example.com>\n\nbody\n",
+			from:     "john@example.com",
+			fromName: "Jöhn",
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if len(e.From) != 1 || e.From[0].Address != td.from || e.From[0].Name != td.fromName {
+			t.Errorf("[Test Case %v] Wrong from. Expected: '%s <%s>', Got: %v", index, td.fromName, td.from, e.From)
+		}
+
+		if e.RequiresSMTPUTF8 != td.requiresSMTPUTF8 {
+			t.Errorf("[Test Case %v] Wrong RequiresSMTPUTF8. Expected: %v, Got: %v", index, td.requiresSMTPUTF8, e.RequiresSMTPUTF8)
+		}
+	}
+}
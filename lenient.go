@@ -0,0 +1,103 @@
+package parsemail
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// salvageAddressList makes a best effort to recover addresses from a header
+// value mail.ParseAddressList rejected: bare display names, missing angle
+// brackets, trailing separators and duplicated angle addresses are tolerated
+func salvageAddressList(s string) (list []*mail.Address) {
+	for _, piece := range splitAddressList(s) {
+		if a := salvageAddress(piece); a != nil {
+			list = append(list, a)
+		}
+	}
+
+	return
+}
+
+// salvageAddress recovers a single address, returning nil if nothing is left of it
+func salvageAddress(s string) *mail.Address {
+	s = strings.Trim(strings.TrimSpace(s), ",;")
+	if s == "" {
+		return nil
+	}
+
+	if a, err := mail.ParseAddress(s); err == nil {
+		return a
+	}
+
+	// Name <a@b>, also Name <a@b> <a@b> and Name <a@b
+	if lt := strings.IndexByte(s, '<'); lt != -1 {
+		addr := s[lt+1:]
+		if gt := strings.IndexByte(addr, '>'); gt != -1 {
+			addr = addr[:gt]
+		}
+
+		return &mail.Address{
+			Name:    unquoteDisplayName(s[:lt]),
+			Address: strings.TrimSpace(addr),
+		}
+	}
+
+	// Name a@b or a@b (Name)
+	fields := strings.Fields(s)
+	for i, f := range fields {
+		if strings.Contains(f, "@") {
+			name := append(append([]string{}, fields[:i]...), fields[i+1:]...)
+			return &mail.Address{
+				Name:    unquoteDisplayName(strings.Join(name, " ")),
+				Address: strings.Trim(f, "<>()\"',;"),
+			}
+		}
+	}
+
+	// a bare display name without an address
+	return &mail.Address{Name: unquoteDisplayName(s)}
+}
+
+// splitAddressList splits on commas and semicolons outside of quotes, comments and angle brackets
+func splitAddressList(s string) []string {
+	return splitAddressListOn(s, ",;")
+}
+
+// splitAddressListOn splits on the separators outside of quotes, comments and angle brackets
+func splitAddressListOn(s string, separators string) (pieces []string) {
+	quoted, angle, comment := false, false, 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			comment++
+		case c == ')' && comment > 0:
+			comment--
+		case comment > 0:
+		case c == '<':
+			angle = true
+		case c == '>':
+			angle = false
+		case strings.IndexByte(separators, c) != -1 && !angle:
+			pieces = append(pieces, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(pieces, s[start:])
+}
+
+func unquoteDisplayName(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSpace(strings.Trim(s, "()"))
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = strings.Replace(s[1:len(s)-1], `\"`, `"`, -1)
+	}
+
+	return decodeMimeSentence(s)
+}
@@ -0,0 +1,68 @@
+package parsemail
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestLenientAddresses(t *testing.T) {
+	var testData = map[int]struct {
+		from     string
+		expected []mail.Address
+	}{
+		1: {
+			from:     "John Doe",
+			expected: []mail.Address{{Name: "John Doe"}},
+		},
+		2: {
+			from:     "John Doe jdoe@machine.example",
+			expected: []mail.Address{{Name: "John Doe", Address: "jdoe@machine.example"}},
+		},
+		3: {
+			from:     "John Doe <jdoe@machine.example>;",
+			expected: []mail.Address{{Name: "John Doe", Address: "jdoe@machine.example"}},
+		},
+		4: {
+			from:     "John Doe <jdoe@machine.example> <jdoe@machine.example>",
+			expected: []mail.Address{{Name: "John Doe", Address: "jdoe@machine.example"}},
+		},
+		5: {
+			from: `"Doe, John" <jdoe@machine.example>; Mary Smith <mary@example.net`,
+			expected: []mail.Address{
+				{Name: "Doe, John", Address: "jdoe@machine.example"},
+				{Name: "Mary Smith", Address: "mary@example.net"},
+			},
+		},
+	}
+
+	for index, td := range testData {
+		message := "From: " + td.from + "\nTo: Mary Smith <mary@example.net>\n\nHi.\n"
+
+		e, err := Parse(strings.NewReader(message))
+		if err != nil {
+			t.Fatalf("[Test Case %v] %v", index, err)
+		}
+
+		if len(e.From) != 0 || len(e.Warnings) != 1 || !strings.HasPrefix(e.Warnings[0], "Dropped") {
+			t.Errorf("[Test Case %v] Expected malformed From to be dropped with a warning, got %v %v", index, e.From, e.Warnings)
+		}
+
+		e, err = ParseWithOptions(strings.NewReader(message), WithLenientAddresses())
+		if err != nil {
+			t.Fatalf("[Test Case %v] %v", index, err)
+		}
+
+		if d := dereferenceAddressList(e.From); !assertAddressListEq(td.expected, d) {
+			t.Errorf("[Test Case %v] Wrong salvaged from. Expected: %v, Got: %v", index, td.expected, d)
+		}
+
+		if len(e.Warnings) != 1 || !strings.HasPrefix(e.Warnings[0], "Salvaged") {
+			t.Errorf("[Test Case %v] Expected a salvage warning, got %v", index, e.Warnings)
+		}
+
+		if len(e.To) != 1 || e.To[0].Address != "mary@example.net" {
+			t.Errorf("[Test Case %v] Valid To header affected: %v", index, e.To)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package parsemail
+
+import (
+	"fmt"
+	"io"
+)
+
+// Limit is a limit on the content of a message, see WithMaxAttachments,
+// WithMaxAttachmentSize, WithMaxTotalAttachmentSize, WithMaxDecodedSize and
+// WithMaxNestingDepth
+type Limit int
+
+const (
+	// LimitAttachmentCount limits the number of attachments and embedded files
+	LimitAttachmentCount Limit = iota
+	// LimitAttachmentSize limits the decoded size of each attachment and embedded file
+	LimitAttachmentSize
+	// LimitTotalAttachmentSize limits the decoded size of all attachments and embedded files
+	LimitTotalAttachmentSize
+	// LimitDecodedSize limits the decoded size of all body parts, attachments and embedded files
+	LimitDecodedSize
+	// LimitNestingDepth limits how deeply multipart entities and messages nest
+	LimitNestingDepth
+)
+
+func (l Limit) String() string {
+	switch l {
+	case LimitAttachmentCount:
+		return "Attachment count"
+	case LimitAttachmentSize:
+		return "Attachment size"
+	case LimitTotalAttachmentSize:
+		return "Total attachment size"
+	case LimitDecodedSize:
+		return "Decoded size"
+	case LimitNestingDepth:
+		return "Nesting depth"
+	}
+
+	return fmt.Sprintf("Limit(%d)", int(l))
+}
+
+// LimitError is returned parsing a message that exceeds a limit on its content
+type LimitError struct {
+	Limit Limit
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v exceeds the limit of %d", e.Limit, e.Max)
+}
+
+func isLimitError(err error) bool {
+	_, ok := err.(*LimitError)
+	return ok
+}
+
+// defaultMaxNestingDepth is the nesting depth of multipart entities and
+// messages parsed by default, far beyond that of legitimate mail
+const defaultMaxNestingDepth = 16
+
+// parseBudget tracks the limits of a parse. It is shared by a message and the
+// messages nested in it, so the limits hold for the whole parse tree.
+type parseBudget struct {
+	o               *options
+	depth           int
+	attachments     int
+	attachmentBytes int64
+	decodedBytes    int64
+}
+
+// enter descends into a nested multipart entity or message
+func (b *parseBudget) enter() error {
+	if b.o.maxNestingDepth > 0 && b.depth >= b.o.maxNestingDepth {
+		return &LimitError{Limit: LimitNestingDepth, Max: int64(b.o.maxNestingDepth)}
+	}
+
+	b.depth++
+	return nil
+}
+
+func (b *parseBudget) leave() {
+	b.depth--
+}
+
+// addAttachment counts another attachment, failing when there are too many
+func (b *parseBudget) addAttachment() error {
+	if b.o.maxAttachments > 0 && b.attachments >= b.o.maxAttachments {
+		return &LimitError{Limit: LimitAttachmentCount, Max: int64(b.o.maxAttachments)}
+	}
+
+	b.attachments++
+	return nil
+}
+
+// addDecoded counts n more decoded bytes of a part sized size so far
+func (b *parseBudget) addDecoded(n, size int64, attachment bool) error {
+	b.decodedBytes += n
+	if attachment {
+		b.attachmentBytes += n
+	}
+
+	switch {
+	case attachment && b.o.maxAttachmentSize > 0 && size > b.o.maxAttachmentSize:
+		return &LimitError{Limit: LimitAttachmentSize, Max: b.o.maxAttachmentSize}
+	case attachment && b.o.maxTotalAttachmentSize > 0 && b.attachmentBytes > b.o.maxTotalAttachmentSize:
+		return &LimitError{Limit: LimitTotalAttachmentSize, Max: b.o.maxTotalAttachmentSize}
+	case b.o.maxDecodedSize > 0 && b.decodedBytes > b.o.maxDecodedSize:
+		return &LimitError{Limit: LimitDecodedSize, Max: b.o.maxDecodedSize}
+	}
+
+	return nil
+}
+
+// sizeLimited reports whether any decoded size is limited
+func (b *parseBudget) sizeLimited(attachment bool) bool {
+	return b.o.maxDecodedSize > 0 || (attachment && (b.o.maxAttachmentSize > 0 || b.o.maxTotalAttachmentSize > 0))
+}
+
+// partLimiter counts the decoded data of a part written to it against the
+// budget, failing writes beyond the size limits. The first error sticks.
+type partLimiter struct {
+	b          *parseBudget
+	attachment bool
+	size       int64
+	err        error
+}
+
+// newPartLimiter returns a limiter for the next part decoded with the budget of e, or nil without size limits
+func newPartLimiter(e *Email, attachment bool) *partLimiter {
+	if !e.budget.sizeLimited(attachment) {
+		return nil
+	}
+
+	return &partLimiter{b: e.budget, attachment: attachment}
+}
+
+func (l *partLimiter) Write(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	l.size += int64(len(p))
+	if l.err = l.b.addDecoded(int64(len(p)), l.size, l.attachment); l.err != nil {
+		return 0, l.err
+	}
+
+	return len(p), nil
+}
+
+// limitBody returns r failing reads once the body data decoded from it exceeds the budget of e
+func limitBody(e *Email, r io.Reader) io.Reader {
+	if l := newPartLimiter(e, false); l != nil {
+		return io.TeeReader(r, l)
+	}
+
+	return r
+}
@@ -0,0 +1,142 @@
+package parsemail
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestAttachmentLimits(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\n\nbody\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"b.txt\"\nContent-Transfer-Encoding: base64\n\nd29ybGQh\n--m--\n"
+
+	stream := WithAttachmentStreaming(func(a Attachment) error {
+		ioutil.ReadAll(a.Data)
+		return nil
+	})
+
+	var testData = map[int]struct {
+		options []Option
+		limit   *LimitError
+	}{
+		1: {
+			options: []Option{WithMaxAttachments(2), WithMaxAttachmentSize(6), WithMaxTotalAttachmentSize(11)},
+		},
+		2: {
+			options: []Option{WithMaxAttachments(1)},
+			limit:   &LimitError{Limit: LimitAttachmentCount, Max: 1},
+		},
+		3: {
+			options: []Option{WithMaxAttachmentSize(5)},
+			limit:   &LimitError{Limit: LimitAttachmentSize, Max: 5},
+		},
+		4: {
+			options: []Option{WithMaxTotalAttachmentSize(10)},
+			limit:   &LimitError{Limit: LimitTotalAttachmentSize, Max: 10},
+		},
+		5: {
+			options: []Option{WithMaxAttachmentSize(5), stream},
+			limit:   &LimitError{Limit: LimitAttachmentSize, Max: 5},
+		},
+		6: {
+			options: []Option{WithMaxTotalAttachmentSize(10), WithSpooling(1, "")},
+			limit:   &LimitError{Limit: LimitTotalAttachmentSize, Max: 10},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := ParseWithOptions(strings.NewReader(mailData), td.options...)
+		if td.limit == nil {
+			if err != nil {
+				t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			} else if len(e.Attachments) != 2 {
+				t.Errorf("[Test Case %v] Wrong number of attachments. Expected: 2, Got: %v", index, len(e.Attachments))
+			}
+			e.Close()
+			continue
+		}
+
+		limitErr, ok := err.(*LimitError)
+		if !ok {
+			t.Errorf("[Test Case %v] Expected a limit error, Got: %v", index, err)
+			continue
+		}
+
+		if *limitErr != *td.limit {
+			t.Errorf("[Test Case %v] Wrong limit error. Expected: %v, Got: %v", index, td.limit, limitErr)
+		}
+	}
+}
+
+func TestDecodedSizeLimit(t *testing.T) {
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write(bytes.Repeat([]byte("a"), 1000))
+	gw.Close()
+	compressed := "Content-Type: text/plain\nContent-Transfer-Encoding: base64\nContent-Encoding: gzip\n\n" +
+		base64.StdEncoding.EncodeToString(gz.Bytes()) + "\n"
+
+	single := "From: jdoe@machine.example\n" + compressed
+	nested := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\n\nbody\n" +
+		"--m\nContent-Type: message/rfc822\nContent-Disposition: attachment; filename=\"fwd.eml\"\n\n" +
+		"Subject: Forwarded\n" + compressed + "--m--\n"
+	embedded := "From: jdoe@machine.example\nContent-Type: multipart/related; boundary=r\n\n" +
+		"--r\nContent-Type: text/html\n\n<img src=\"cid:logo@x\">\n" +
+		"--r\nContent-Type: image/png\nContent-ID: <logo@x>\nContent-Transfer-Encoding: base64\n\n" +
+		base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0}, 600)) + "\n--r--\n"
+	attachment := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\n\nbody\n" +
+		"--m\nContent-Type: application/octet-stream\nContent-Disposition: attachment; filename=\"a.bin\"\nContent-Transfer-Encoding: base64\n\n" +
+		base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0}, 600)) + "\n--m--\n"
+	both := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: image/png\nContent-ID: <logo@x>\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+		"--m\nContent-Type: application/octet-stream\nContent-Disposition: attachment; filename=\"a.bin\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	var testData = map[int]struct {
+		mailData string
+		options  []Option
+		limit    *LimitError
+	}{
+		1:  {mailData: single, options: []Option{WithMaxDecodedSize(1000)}},
+		2:  {mailData: single, options: []Option{WithMaxDecodedSize(999)}, limit: &LimitError{Limit: LimitDecodedSize, Max: 999}},
+		3:  {mailData: single, options: []Option{WithMaxDecodedSize(999), WithCompatLevel(CompatV1)}, limit: &LimitError{Limit: LimitDecodedSize, Max: 999}},
+		4:  {mailData: nested, options: []Option{WithMaxDecodedSize(10000)}},
+		5:  {mailData: nested, options: []Option{WithMaxDecodedSize(500)}, limit: &LimitError{Limit: LimitDecodedSize, Max: 500}},
+		6:  {mailData: attachment, options: []Option{WithMaxDecodedSize(1000)}},
+		7:  {mailData: attachment, options: []Option{WithMaxDecodedSize(500)}, limit: &LimitError{Limit: LimitDecodedSize, Max: 500}},
+		8:  {mailData: embedded, options: []Option{WithMaxDecodedSize(1000), WithMaxAttachmentSize(1000), WithMaxTotalAttachmentSize(1000), WithMaxAttachments(1)}},
+		9:  {mailData: embedded, options: []Option{WithMaxDecodedSize(500)}, limit: &LimitError{Limit: LimitDecodedSize, Max: 500}},
+		10: {mailData: embedded, options: []Option{WithMaxAttachmentSize(500)}, limit: &LimitError{Limit: LimitAttachmentSize, Max: 500}},
+		11: {mailData: embedded, options: []Option{WithMaxTotalAttachmentSize(500), WithSpooling(1, "")}, limit: &LimitError{Limit: LimitTotalAttachmentSize, Max: 500}},
+		12: {mailData: both, options: []Option{WithMaxAttachments(2)}},
+		13: {mailData: both, options: []Option{WithMaxAttachments(1)}, limit: &LimitError{Limit: LimitAttachmentCount, Max: 1}},
+	}
+
+	for index, td := range testData {
+		e, err := ParseWithOptions(strings.NewReader(td.mailData), td.options...)
+		if td.limit == nil {
+			if err != nil {
+				t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			}
+			e.Close()
+			continue
+		}
+
+		if limitErr, ok := err.(*LimitError); !ok || *limitErr != *td.limit {
+			t.Errorf("[Test Case %v] Wrong error. Expected: %v, Got: %v", index, td.limit, err)
+		}
+	}
+}
+
+func TestLimitErrorMessage(t *testing.T) {
+	err := &LimitError{Limit: LimitTotalAttachmentSize, Max: 1024}
+	if err.Error() != "Total attachment size exceeds the limit of 1024" {
+		t.Errorf("Wrong message: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package parsemail
+
+import (
+	"encoding/base64"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxUnwrapDepth bounds how many nested link protection layers UnwrapURL peels off
+const maxUnwrapDepth = 5
+
+// proofpointRunLengths maps the run marker of a Proofpoint v3 "**X" token to the number of replaced characters
+const proofpointRunLengths = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// LinkSource is the body a Link was found in
+type LinkSource int
+
+// Link sources
+const (
+	LinkSourceText LinkSource = iota
+	LinkSourceHTML
+)
+
+// Link is a URL found in a body. For HTML anchors Text is the display text and
+// Mismatch is set when the text shows a different host than the link points
+// to, a common phishing trick. Destination is the URL with link protection
+// rewrites undone, see UnwrapURL.
+type Link struct {
+	Source      LinkSource
+	URL         string
+	Destination string
+	Text        string
+	Mismatch    bool
+}
+
+// linkTextHostPattern matches display text that looks like a URL or a bare host name
+var linkTextHostPattern = regexp.MustCompile(`(?i)^(?:[a-z][a-z0-9+.-]*://)?(?:www\.)?[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}(?:[:/?#]\S*)?$`)
+
+// ExtractLinks returns the URLs found in a plain text and a HTML body, in
+// order: bare URLs of the text, then the targets of HTML anchors and bare URLs
+// in the HTML text. Fragment, mailto:, tel: and cid: links are skipped.
+func ExtractLinks(text, html string) (links []Link) {
+	links = appendTextLinks(links, LinkSourceText, text)
+	if html == "" {
+		return
+	}
+
+	ParseHTML(html).Walk(func(n *HTMLNode) bool {
+		switch {
+		case n.Type == HTMLTextNode:
+			links = appendTextLinks(links, LinkSourceHTML, n.Data)
+		case n.Type != HTMLElementNode:
+		case htmlRawTextElements[n.Data]:
+			return false
+		case n.Data == "a" || n.Data == "area":
+			href, _ := n.Attribute("href")
+			href = strings.TrimSpace(href)
+			if !isExtractedLink(href) {
+				return true
+			}
+
+			l := newLink(LinkSourceHTML, href)
+			l.Text = strings.Join(strings.Fields(n.Text()), " ")
+			l.Mismatch = linkTextMismatch(l.Text, l.Destination)
+			links = append(links, l)
+
+			// the URL in the display text is the anchor itself
+			return false
+		}
+
+		return true
+	})
+
+	return
+}
+
+// Links returns the URLs of the text and HTML bodies, see ExtractLinks
+func (e Email) Links() []Link {
+	return ExtractLinks(e.TextBody, e.HTMLBody)
+}
+
+func appendTextLinks(links []Link, source LinkSource, text string) []Link {
+	for _, u := range textURLPattern.FindAllString(text, -1) {
+		u = strings.TrimRight(u, ".,;:!?)]}'")
+		links = append(links, newLink(source, u))
+	}
+
+	return links
+}
+
+func newLink(source LinkSource, u string) Link {
+	dest, _ := UnwrapURL(u)
+	return Link{Source: source, URL: u, Destination: dest}
+}
+
+func isExtractedLink(href string) bool {
+	lower := strings.ToLower(href)
+	for _, prefix := range []string{"#", "mailto:", "tel:", "cid:"} {
+		if strings.HasPrefix(lower, prefix) {
+			return false
+		}
+	}
+
+	return href != ""
+}
+
+// linkTextMismatch reports whether text looks like a URL or host name other than the host of dest
+func linkTextMismatch(text, dest string) bool {
+	if !linkTextHostPattern.MatchString(text) {
+		return false
+	}
+
+	shown := linkHost(text)
+	actual := linkHost(dest)
+
+	return shown != "" && shown != actual
+}
+
+// linkHost returns the lower case host of s without a leading "www.", s may lack a scheme
+func linkHost(s string) string {
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// UnwrapURL returns the real destination of a URL rewritten by a link protection
+// service (Proofpoint URL Defense, Microsoft SafeLinks, Mimecast, Barracuda).
+// Nested rewrites are unwrapped as well. The second return value is false when
+// the URL is not wrapped or the destination can't be recovered from it.
+func UnwrapURL(s string) (string, bool) {
+	unwrapped := false
+	for i := 0; i < maxUnwrapDepth; i++ {
+		dest, ok := unwrapOnce(s)
+		if !ok {
+			break
+		}
+
+		s = dest
+		unwrapped = true
+	}
+
+	return s, unwrapped
+}
+
+func unwrapOnce(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "urldefense.proofpoint.com" || host == "urldefense.com":
+		return unwrapProofpoint(u, s)
+	case strings.HasSuffix(host, ".safelinks.protection.outlook.com"):
+		return queryDestination(u, "url")
+	case host == "mimecast.com" || strings.HasSuffix(host, ".mimecast.com"):
+		return queryDestination(u, "url", "u")
+	case host == "linkprotect.cudasvc.com":
+		return queryDestination(u, "a")
+	}
+
+	return "", false
+}
+
+func queryDestination(u *url.URL, params ...string) (string, bool) {
+	q := u.Query()
+	for _, p := range params {
+		if dest := q.Get(p); dest != "" {
+			return dest, true
+		}
+	}
+
+	return "", false
+}
+
+func unwrapProofpoint(u *url.URL, raw string) (string, bool) {
+	switch {
+	case strings.HasPrefix(u.Path, "/v1/"):
+		return queryDestination(u, "u")
+	case strings.HasPrefix(u.Path, "/v2/"):
+		enc := u.Query().Get("u")
+		if enc == "" {
+			return "", false
+		}
+
+		dest, err := url.PathUnescape(strings.NewReplacer("-", "%", "_", "/").Replace(enc))
+		if err != nil {
+			return "", false
+		}
+
+		return dest, true
+	case strings.HasPrefix(u.EscapedPath(), "/v3/__"):
+		return unwrapProofpointV3(raw)
+	}
+
+	return "", false
+}
+
+// unwrapProofpointV3 decodes https://urldefense.com/v3/__<url>__;<replaced characters>!!<token>
+// where each "*" (or "**X" run) in <url> stands for characters stored base64 encoded after the ";"
+func unwrapProofpointV3(s string) (string, bool) {
+	start := strings.Index(s, "/v3/__")
+	end := strings.LastIndex(s, "__;")
+	if start == -1 || end == -1 || end < start+len("/v3/__") {
+		return "", false
+	}
+
+	wrapped := s[start+len("/v3/__") : end]
+	encoded := s[end+len("__;"):]
+	if i := strings.Index(encoded, "!"); i != -1 {
+		encoded = encoded[:i]
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return "", false
+	}
+
+	replacements := []rune(string(decoded))
+	var sb strings.Builder
+	marker := 0
+	for i := 0; i < len(wrapped); i++ {
+		if wrapped[i] != '*' {
+			sb.WriteByte(wrapped[i])
+			continue
+		}
+
+		n := 1
+		if i+2 < len(wrapped) && wrapped[i+1] == '*' {
+			n = strings.IndexByte(proofpointRunLengths, wrapped[i+2]) + 2
+			if n < 2 {
+				return "", false
+			}
+			i += 2
+		}
+
+		if marker+n > len(replacements) {
+			return "", false
+		}
+
+		sb.WriteString(string(replacements[marker : marker+n]))
+		marker += n
+	}
+
+	dest, err := url.PathUnescape(sb.String())
+	if err != nil {
+		return "", false
+	}
+
+	return dest, true
+}
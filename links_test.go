@@ -0,0 +1,111 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnwrapURL(t *testing.T) {
+	var testData = map[int]struct {
+		wrapped   string
+		expected  string
+		unwrapped bool
+	}{
+		1: {
+			wrapped:   "https://urldefense.proofpoint.com/v1/url?u=http://www.example.com/path%3Fa%3Db&k=abc",
+			expected:  "http://www.example.com/path?a=b",
+			unwrapped: true,
+		},
+		2: {
+			wrapped:   "https://urldefense.proofpoint.com/v2/url?u=https-3A__www.example.com_path-3Fa-3Db&d=DwMFaQ&c=abc",
+			expected:  "https://www.example.com/path?a=b",
+			unwrapped: true,
+		},
+		3: {
+			wrapped:   "https://urldefense.com/v3/__https://www.example.com/path?a=b*c__;Jg!!token$",
+			expected:  "https://www.example.com/path?a=b&c",
+			unwrapped: true,
+		},
+		4: {
+			wrapped:   "https://urldefense.com/v3/__https://www.example.com/**Bpath__;Pz0m!!token$",
+			expected:  "https://www.example.com/?=&path",
+			unwrapped: true,
+		},
+		5: {
+			wrapped:   "https://nam02.safelinks.protection.outlook.com/?url=https%3A%2F%2Fwww.example.com%2F&data=02%7C01&reserved=0",
+			expected:  "https://www.example.com/",
+			unwrapped: true,
+		},
+		6: {
+			wrapped:   "https://linkprotect.cudasvc.com/url?a=https%3a%2f%2fwww.example.com%2fdoc&c=E,1,abc&typo=1",
+			expected:  "https://www.example.com/doc",
+			unwrapped: true,
+		},
+		7: {
+			wrapped:   "https://protect-eu.mimecast.com/s/abcdEFgh?url=https%3A%2F%2Fwww.example.com%2F",
+			expected:  "https://www.example.com/",
+			unwrapped: true,
+		},
+		8: {
+			wrapped:   "https://protect-eu.mimecast.com/s/abcdEFgh?domain=example.com",
+			expected:  "https://protect-eu.mimecast.com/s/abcdEFgh?domain=example.com",
+			unwrapped: false,
+		},
+		9: {
+			wrapped:   "https://nam02.safelinks.protection.outlook.com/?url=https%3A%2F%2Furldefense.proofpoint.com%2Fv2%2Furl%3Fu%3Dhttps-3A__www.example.com_%26d%3DDwMFaQ&data=02",
+			expected:  "https://www.example.com/",
+			unwrapped: true,
+		},
+		10: {
+			wrapped:   "https://www.example.com/?url=https%3A%2F%2Fother.example",
+			expected:  "https://www.example.com/?url=https%3A%2F%2Fother.example",
+			unwrapped: false,
+		},
+	}
+
+	for index, td := range testData {
+		got, ok := UnwrapURL(td.wrapped)
+		if ok != td.unwrapped {
+			t.Errorf("[Test Case %v] Wrong unwrapped flag. Expected: %v, Got: %v", index, td.unwrapped, ok)
+		}
+
+		if got != td.expected {
+			t.Errorf("[Test Case %v] Wrong destination. Expected: '%s', Got: '%s'", index, td.expected, got)
+		}
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	mailData := "From: John Doe <jdoe@machine.example>\nContent-Type: multipart/alternative; boundary=b\n\n" +
+		"--b\nContent-Type: text/plain\n\nLog in at https://www.example.com/login. Or www.example.org!\n" +
+		"--b\nContent-Type: text/html\n\n" +
+		`<p>Log in at <a href="https://evil.example.net/login">https://www.example.com/login</a>, ` +
+		`<a href="https://nam02.safelinks.protection.outlook.com/?url=https%3A%2F%2Fexample.com%2Fa&amp;data=x">example.com</a> ` +
+		`<a href="#top">top</a> <a href="mailto:jdoe@machine.example">mail</a> <a href="https://example.com/help">Help</a></p>` +
+		"<p>See https://example.com/docs</p><style>a{background:url(https://example.com/bg)}</style>\n--b--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Link{
+		{Source: LinkSourceText, URL: "https://www.example.com/login", Destination: "https://www.example.com/login"},
+		{Source: LinkSourceText, URL: "www.example.org", Destination: "www.example.org"},
+		{Source: LinkSourceHTML, URL: "https://evil.example.net/login", Destination: "https://evil.example.net/login", Text: "https://www.example.com/login", Mismatch: true},
+		{Source: LinkSourceHTML, URL: "https://nam02.safelinks.protection.outlook.com/?url=https%3A%2F%2Fexample.com%2Fa&data=x", Destination: "https://example.com/a", Text: "example.com"},
+		{Source: LinkSourceHTML, URL: "https://example.com/help", Destination: "https://example.com/help", Text: "Help"},
+		{Source: LinkSourceHTML, URL: "https://example.com/docs", Destination: "https://example.com/docs"},
+	}
+
+	links := e.Links()
+	if len(links) != len(expected) {
+		t.Fatalf("Wrong number of links. Expected: %+v, Got: %+v", expected, links)
+	}
+
+	for i := range expected {
+		if links[i] != expected[i] {
+			t.Errorf("[Test Case %v] Wrong link. Expected: %+v, Got: %+v", i+1, expected[i], links[i])
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package parsemail
+
+import (
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+const (
+	headerContentLocation = "Content-Location"
+	headerContentBase     = "Content-Base"
+)
+
+// partContentLocation returns the Content-Location URL of part, without the
+// white space of folding (RFC 2557 section 4.1)
+func partContentLocation(part *multipart.Part) string {
+	return unfoldURL(part.Header.Get(headerContentLocation))
+}
+
+func unfoldURL(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// contentBase returns the absolute base URL of a body part: its
+// Content-Location when absolute, else its Content-Base with a relative
+// Content-Location resolved against it (RFC 2557 section 4.2)
+func contentBase(h textproto.MIMEHeader) string {
+	location, err := url.Parse(unfoldURL(h.Get(headerContentLocation)))
+	if err == nil && location.IsAbs() {
+		return location.String()
+	}
+
+	base, baseErr := url.Parse(unfoldURL(h.Get(headerContentBase)))
+	if baseErr != nil || !base.IsAbs() {
+		return ""
+	}
+
+	if err == nil {
+		return base.ResolveReference(location).String()
+	}
+
+	return base.String()
+}
+
+// ResolveEmbeddedFile returns the embedded file a resource URL of the HTML
+// body refers to: cid: URLs by the content id of the file, other URLs by its
+// Content-Location. Relative URLs and locations are resolved against the
+// HTMLBodyBase; without one, they only match each other literally. Empty and
+// fragment only URLs refer to the HTML body itself and match nothing.
+func (e Email) ResolveEmbeddedFile(ref string) (EmbeddedFile, bool) {
+	ref = strings.TrimSpace(ref)
+	if len(ref) > 4 && strings.EqualFold(ref[:4], "cid:") {
+		cid := normalizeCID(ref[4:])
+		for _, ef := range e.EmbeddedFiles {
+			if ef.CID == cid {
+				return ef, true
+			}
+		}
+
+		return EmbeddedFile{}, false
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil || ref == "" || strings.HasPrefix(ref, "#") {
+		return EmbeddedFile{}, false
+	}
+
+	base, err := url.Parse(e.HTMLBodyBase)
+	if err != nil || !base.IsAbs() {
+		base = nil
+	}
+
+	if base != nil {
+		refURL = base.ResolveReference(refURL)
+	}
+
+	for _, ef := range e.EmbeddedFiles {
+		location, err := url.Parse(ef.ContentLocation)
+		if err != nil || ef.ContentLocation == "" {
+			continue
+		}
+
+		if base != nil {
+			location = base.ResolveReference(location)
+		}
+
+		if location.IsAbs() == refURL.IsAbs() && sameURL(location, refURL) {
+			return ef, true
+		}
+	}
+
+	return EmbeddedFile{}, false
+}
+
+// sameURL compares URLs without their fragments, ignoring the case of their
+// scheme and host
+func sameURL(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host) &&
+		a.EscapedPath() == b.EscapedPath() && a.RawQuery == b.RawQuery
+}
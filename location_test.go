@@ -0,0 +1,119 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveEmbeddedFile(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/related; boundary=r\n\n" +
+		"--r\nContent-Type: text/html\nContent-Location: http://example.com/news/index.html\n\n<link href=\"style.css\"><img src=\"logo.png\"><img src=\"cid:photo@x\">\n" +
+		"--r\nContent-Type: text/css\nContent-Location: http://example.com/news/\n style.css\n\nbody {}\n" +
+		"--r\nContent-Type: image/png\nContent-Location: logo.png\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+		"--r\nContent-Type: image/jpeg\nContent-ID: <photo@x>\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--r--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(e.EmbeddedFiles) != 3 {
+		t.Fatalf("Wrong number of embedded files. Expected: 3, Got: %v", len(e.EmbeddedFiles))
+	}
+
+	if e.EmbeddedFiles[0].ContentLocation != "http://example.com/news/style.css" {
+		t.Errorf("Wrong content location. Expected: http://example.com/news/style.css, Got: %v", e.EmbeddedFiles[0].ContentLocation)
+	}
+
+	var testData = map[int]struct {
+		ref         string
+		contentType string
+	}{
+		1:  {ref: "style.css", contentType: "text/css"},
+		2:  {ref: "HTTP://Example.com/news/style.css", contentType: "text/css"},
+		3:  {ref: "../news/style.css", contentType: "text/css"},
+		4:  {ref: "logo.png", contentType: "image/png"},
+		5:  {ref: "cid:photo@x", contentType: "image/jpeg"},
+		6:  {ref: "CID:%3Cphoto@x%3E", contentType: "image/jpeg"},
+		7:  {ref: "other.css"},
+		8:  {ref: "cid:unknown@x"},
+		9:  {ref: ""},
+		10: {ref: "#top"},
+		11: {ref: "logo.png#top", contentType: "image/png"},
+	}
+
+	for index, td := range testData {
+		ef, ok := e.ResolveEmbeddedFile(td.ref)
+		if ok != (td.contentType != "") {
+			t.Errorf("[Test Case %v] Wrong resolution of %s. Expected found: %v, Got: %v", index, td.ref, td.contentType != "", ok)
+			continue
+		}
+
+		if ef.ContentType != td.contentType {
+			t.Errorf("[Test Case %v] Wrong embedded file. Expected: %s, Got: %s", index, td.contentType, ef.ContentType)
+		}
+	}
+}
+
+func TestResolveEmbeddedFileBase(t *testing.T) {
+	related := func(htmlHeader, location string) string {
+		return "From: jdoe@machine.example\nContent-Type: multipart/related; boundary=r\n\n" +
+			"--r\nContent-Type: text/html\n" + htmlHeader + "\n<img src=\"image.png\">\n" +
+			"--r\nContent-Type: image/png\nContent-Location: " + location + "\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--r--\n"
+	}
+
+	var testData = map[int]struct {
+		mailData string
+		ref      string
+		base     string
+		found    bool
+	}{
+		1: {
+			mailData: related("", "http://a.example/img/image.png"),
+			ref:      "image.png",
+		},
+		2: {
+			mailData: related("Content-Base: http://a.example/img/\n", "http://a.example/img/image.png"),
+			ref:      "image.png",
+			base:     "http://a.example/img/",
+			found:    true,
+		},
+		3: {
+			mailData: related("Content-Base: http://a.example/img/\n", "http://b.example/img/image.png"),
+			ref:      "image.png",
+			base:     "http://a.example/img/",
+		},
+		4: {
+			mailData: related("Content-Base: http://a.example/\nContent-Location: news/index.html\n", "http://a.example/news/image.png"),
+			ref:      "image.png",
+			base:     "http://a.example/news/index.html",
+			found:    true,
+		},
+		5: {
+			mailData: related("Content-Location: http://a.example/news/index.html\n", "http://a.example/other/image.png"),
+			ref:      "image.png",
+			base:     "http://a.example/news/index.html",
+		},
+		6: {
+			mailData: related("", "image.png"),
+			ref:      "image.png",
+			found:    true,
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if e.HTMLBodyBase != td.base {
+			t.Errorf("[Test Case %v] Wrong HTML body base. Expected: %q, Got: %q", index, td.base, e.HTMLBodyBase)
+		}
+
+		if _, ok := e.ResolveEmbeddedFile(td.ref); ok != td.found {
+			t.Errorf("[Test Case %v] Wrong resolution of %s. Expected found: %v, Got: %v", index, td.ref, td.found, ok)
+		}
+	}
+}
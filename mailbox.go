@@ -0,0 +1,135 @@
+package parsemail
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// MboxReader splits an mbox stream into the raw messages it contains. Both the
+// mboxo and mboxrd quoting of body lines starting with "From " are undone.
+type MboxReader struct {
+	r    *bufio.Reader
+	next []byte
+	err  error
+}
+
+// NewMboxReader returns a MboxReader reading the mbox stream from r
+func NewMboxReader(r io.Reader) *MboxReader {
+	return &MboxReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next raw message, or io.EOF when there are no more messages.
+// A "From " line only separates messages at the start of the stream or after
+// a blank line, and a trailing message with nothing but whitespace is skipped.
+func (mr *MboxReader) Next() ([]byte, error) {
+	if mr.err != nil {
+		return nil, mr.err
+	}
+
+	// skip everything up to the first separator line
+	for blank := true; mr.next == nil; {
+		line, err := mr.r.ReadBytes('\n')
+		if blank && isMboxSeparator(line) {
+			mr.next = line
+		} else if err != nil {
+			mr.err = err
+			return nil, err
+		}
+
+		blank = isBlankLine(line)
+	}
+
+	var msg bytes.Buffer
+	for blank := false; ; {
+		line, err := mr.r.ReadBytes('\n')
+		if blank && isMboxSeparator(line) {
+			mr.next = line
+			break
+		}
+
+		msg.Write(unquoteMboxLine(line))
+		if err != nil {
+			mr.err = err
+			if err != io.EOF {
+				return nil, err
+			}
+
+			if len(bytes.TrimSpace(msg.Bytes())) == 0 {
+				return nil, io.EOF
+			}
+
+			break
+		}
+
+		blank = isBlankLine(line)
+	}
+
+	return msg.Bytes(), nil
+}
+
+func isMboxSeparator(line []byte) bool {
+	return bytes.HasPrefix(line, []byte("From "))
+}
+
+func isBlankLine(line []byte) bool {
+	return len(bytes.TrimRight(line, "\r\n")) == 0
+}
+
+func unquoteMboxLine(line []byte) []byte {
+	unquoted := bytes.TrimLeft(line, ">")
+	if len(unquoted) < len(line) && bytes.HasPrefix(unquoted, []byte("From ")) {
+		return line[1:]
+	}
+
+	return line
+}
+
+// MaildirMessages returns the paths of all messages in the new and cur
+// directories of the Maildir at dir, sorted by name
+func MaildirMessages(dir string) ([]string, error) {
+	var paths []string
+	for _, sub := range []string{"new", "cur"} {
+		entries, err := ioutil.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			if e.Mode().IsRegular() {
+				paths = append(paths, filepath.Join(dir, sub, e.Name()))
+			}
+		}
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// ReadMaildir calls fn with every raw message in the Maildir at dir
+func ReadMaildir(dir string, fn func(raw []byte) error) error {
+	paths, err := MaildirMessages(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		raw, err := ioutil.ReadFile(p)
+		if os.IsNotExist(err) {
+			// moved from new to cur by a concurrent client
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err := fn(raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
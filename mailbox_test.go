@@ -0,0 +1,125 @@
+package parsemail
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMboxReader(t *testing.T) {
+	mbox := "From jdoe@machine.example Fri Nov 21 09:55:06 1997\n" +
+		"Subject: one\n\n" +
+		"first\n" +
+		">From the start\n" +
+		">>From quoted twice\n" +
+		"\n" +
+		"From mary@example.net Fri Nov 21 10:01:10 1997\n" +
+		"Subject: two\n\n" +
+		"second\n"
+
+	expected := []string{
+		"Subject: one\n\nfirst\nFrom the start\n>From quoted twice\n\n",
+		"Subject: two\n\nsecond\n",
+	}
+
+	mr := NewMboxReader(strings.NewReader(mbox))
+	for i, exp := range expected {
+		raw, err := mr.Next()
+		if err != nil {
+			t.Fatalf("[Message %v] Unexpected error: %v", i, err)
+		}
+
+		if string(raw) != exp {
+			t.Errorf("[Message %v] Wrong message. Expected: '%s', Got: '%s'", i, exp, raw)
+		}
+	}
+
+	if _, err := mr.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF after last message, got %v", err)
+	}
+}
+
+func TestMboxReaderSeparators(t *testing.T) {
+	var testData = map[int]struct {
+		mbox     string
+		expected []string
+	}{
+		1: {
+			mbox:     "From a@example.com Fri Nov 21 09:55:06 1997\nSubject: one\n\nhello\nFrom here on, unquoted\n",
+			expected: []string{"Subject: one\n\nhello\nFrom here on, unquoted\n"},
+		},
+		2: {
+			mbox:     "From a@example.com Fri Nov 21 09:55:06 1997\nSubject: one\n\nfirst\n\nFrom b@example.com Fri Nov 21 10:01:10 1997\nSubject: two\n\nsecond\n\nFrom c@example.com Fri Nov 21 10:02:10 1997\n\n",
+			expected: []string{"Subject: one\n\nfirst\n\n", "Subject: two\n\nsecond\n\n"},
+		},
+		3: {
+			mbox:     "From a@example.com Fri Nov 21 09:55:06 1997\r\nSubject: one\r\n\r\nfirst\r\n\r\nFrom b@example.com Fri Nov 21 10:01:10 1997\r\nSubject: two\r\n\r\nsecond\r\n",
+			expected: []string{"Subject: one\r\n\r\nfirst\r\n\r\n", "Subject: two\r\n\r\nsecond\r\n"},
+		},
+		4: {
+			mbox:     "From a@example.com Fri Nov 21 09:55:06 1997\n\n\n",
+			expected: nil,
+		},
+	}
+
+	for index, td := range testData {
+		var messages []string
+		mr := NewMboxReader(strings.NewReader(td.mbox))
+		for {
+			raw, err := mr.Next()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+				break
+			}
+
+			messages = append(messages, string(raw))
+		}
+
+		if !reflect.DeepEqual(td.expected, messages) {
+			t.Errorf("[Test Case %v] Wrong messages. Expected: %q, Got: %q", index, td.expected, messages)
+		}
+	}
+}
+
+func TestAnalyzeMbox(t *testing.T) {
+	var mbox strings.Builder
+	for _, m := range []string{rfc5322exampleA11, rfc5322exampleA3, data1, data2, "not a message"} {
+		mbox.WriteString("From - Mon Jan  1 00:00:00 2018\n")
+		mbox.WriteString(strings.TrimRight(m, "\n"))
+		mbox.WriteString("\n\n")
+	}
+
+	stats, err := AnalyzeMbox(strings.NewReader(mbox.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Messages != 5 {
+		t.Errorf("Wrong message count. Expected: 5, Got: %v", stats.Messages)
+	}
+
+	if stats.ParseErrors != 1 {
+		t.Errorf("Wrong parse error count. Expected: 1, Got: %v", stats.ParseErrors)
+	}
+
+	if len(stats.TopSenders) != 3 || stats.TopSenders[0] != (SenderCount{"jdoe@machine.example", 2}) {
+		t.Errorf("Wrong top senders: %v", stats.TopSenders)
+	}
+
+	if stats.AttachmentTypes["application/pdf"] != 1 {
+		t.Errorf("Wrong attachment types: %v", stats.AttachmentTypes)
+	}
+
+	if stats.MessagesPerDay["1997-11-21"] != 2 || stats.MessagesPerDay["2017-04-07"] != 2 {
+		t.Errorf("Wrong messages per day: %v", stats.MessagesPerDay)
+	}
+
+	if stats.SizeDistribution[0].Messages != 5 {
+		t.Errorf("Wrong size distribution: %v", stats.SizeDistribution)
+	}
+}
@@ -0,0 +1,43 @@
+package parsemail
+
+import (
+	"fmt"
+	"io"
+)
+
+// isMessage reports whether contentType is that of an encapsulated message
+func isMessage(contentType string) bool {
+	return contentType == contentTypeMessageRFC822 || contentType == contentTypeMessageGlobal
+}
+
+// parseAttachedMessage parses the message attached as at into at.Message with
+// the options and within the limits of the enclosing message. Messages that
+// can't be parsed are left as attachment data only, exceeding a limit fails.
+func parseAttachedMessage(e *Email, at *Attachment, o *options) error {
+	sra, ok := at.Data.(sizedReaderAt)
+	if !ok {
+		return nil
+	}
+
+	origin := int64(-1)
+	if e.locator != nil && identityEncoded(at.Header) {
+		origin = at.Offsets.BodyStart
+	}
+
+	msg, err := parseNestedMessage(e, io.NewSectionReader(sra, 0, sra.Size()), origin, o)
+	if isFatalNestedError(e, err) {
+		return err
+	} else if err != nil {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("Can't parse attached message %s: %v", at.Filename, err))
+		return nil
+	}
+
+	at.Message = &msg
+	return nil
+}
+
+// isFatalNestedError reports whether err parsing a message nested in e fails
+// the parse of e too: limits hold for the whole parse tree, as does the context
+func isFatalNestedError(e *Email, err error) bool {
+	return isLimitError(err) || (err != nil && e.contextErr() != nil)
+}
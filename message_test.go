@@ -0,0 +1,165 @@
+package parsemail
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestAttachedMessages(t *testing.T) {
+	forwarded := "From: Mary Smith <mary@example.net>\nSubject: Original\nContent-Type: multipart/mixed; boundary=inner\n\n" +
+		"--inner\nContent-Type: text/plain\n\nOriginal text\n" +
+		"--inner\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"notes.txt\"\nContent-Transfer-Encoding: base64\n\nbm90ZXM=\n--inner--\n"
+
+	var testData = map[int]struct {
+		part     string
+		options  []Option
+		subject  string
+		textBody string
+		warnings int
+	}{
+		1: {
+			part:     "Content-Type: message/rfc822\nContent-Disposition: attachment; filename=\"fwd.eml\"\n\n" + forwarded,
+			subject:  "Original",
+			textBody: "Original text",
+		},
+		2: {
+			part:     "Content-Type: message/rfc822\nContent-Disposition: inline\n\n" + forwarded,
+			subject:  "Original",
+			textBody: "Original text",
+		},
+		3: {
+			part:     "Content-Type: message/rfc822\nContent-Disposition: attachment; filename=\"fwd.eml\"\n\nSubject: Broken\nContent-Type: image/png\n\n",
+			warnings: 1,
+		},
+		4: {
+			part:    "Content-Type: message/rfc822\nContent-Disposition: attachment; filename=\"fwd.eml\"\n\n" + forwarded,
+			options: []Option{WithAttachmentStreaming(func(Attachment) error { return nil })},
+		},
+	}
+
+	for index, td := range testData {
+		mailData := "From: jdoe@machine.example\nSubject: Fwd: Original\nContent-Type: multipart/mixed; boundary=outer\n\n" +
+			"--outer\nContent-Type: text/plain\n\nSee below\n--outer\n" + td.part + "\n--outer--\n"
+
+		e, err := ParseWithOptions(strings.NewReader(mailData), td.options...)
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if len(e.Attachments) != 1 {
+			t.Errorf("[Test Case %v] Wrong number of attachments. Expected: 1, Got: %v", index, len(e.Attachments))
+			continue
+		}
+
+		if len(e.Warnings) != td.warnings {
+			t.Errorf("[Test Case %v] Wrong warnings. Expected: %v, Got: %v", index, td.warnings, e.Warnings)
+		}
+
+		msg := e.Attachments[0].Message
+		if td.subject == "" {
+			if msg != nil {
+				t.Errorf("[Test Case %v] Unexpected message: %+v", index, msg)
+			}
+			continue
+		}
+
+		if msg == nil {
+			t.Errorf("[Test Case %v] Attached message not parsed", index)
+			continue
+		}
+
+		if msg.Subject != td.subject {
+			t.Errorf("[Test Case %v] Wrong subject. Expected: %s, Got: %s", index, td.subject, msg.Subject)
+		}
+
+		if msg.TextBody != td.textBody {
+			t.Errorf("[Test Case %v] Wrong text body. Expected: %q, Got: %q", index, td.textBody, msg.TextBody)
+		}
+
+		if len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "notes.txt" {
+			t.Errorf("[Test Case %v] Wrong nested attachments: %+v", index, msg.Attachments)
+		}
+
+		raw, _ := ioutil.ReadAll(e.Attachments[0].Data)
+		if !strings.HasPrefix(string(raw), "From: Mary Smith") {
+			t.Errorf("[Test Case %v] Attachment data isn't the raw message: %q", index, raw)
+		}
+	}
+}
+
+// nestedMessage returns a message with depth levels of attached messages, each with one text attachment
+func nestedMessage(depth int) string {
+	msg := "Subject: Level 0\nContent-Type: text/plain\n\ninnermost\n"
+	for i := 1; i <= depth; i++ {
+		boundary := fmt.Sprintf("b%d", i)
+		msg = fmt.Sprintf("Subject: Level %d\nContent-Type: multipart/mixed; boundary=%s\n\n", i, boundary) +
+			"--" + boundary + "\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+			"--" + boundary + "\nContent-Type: message/rfc822\nContent-Disposition: attachment; filename=\"fwd.eml\"\n\n" + msg + "\n--" + boundary + "--\n"
+	}
+
+	return msg
+}
+
+func TestAttachedMessageLimits(t *testing.T) {
+	var testData = map[int]struct {
+		depth   int
+		options []Option
+		limit   *LimitError
+	}{
+		1: {depth: 3},
+		2: {depth: 20, limit: &LimitError{Limit: LimitNestingDepth, Max: 16}},
+		3: {depth: 3, options: []Option{WithMaxNestingDepth(5)}, limit: &LimitError{Limit: LimitNestingDepth, Max: 5}},
+		4: {depth: 20, options: []Option{WithMaxNestingDepth(0)}},
+		5: {depth: 3, options: []Option{WithMaxAttachments(5)}, limit: &LimitError{Limit: LimitAttachmentCount, Max: 5}},
+		6: {depth: 3, options: []Option{WithMaxAttachments(6)}},
+		7: {depth: 3, options: []Option{WithMaxTotalAttachmentSize(14)}, limit: &LimitError{Limit: LimitTotalAttachmentSize, Max: 14}},
+	}
+
+	for index, td := range testData {
+		e, err := ParseWithOptions(strings.NewReader(nestedMessage(td.depth)), td.options...)
+		if td.limit == nil {
+			if err != nil {
+				t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+				continue
+			}
+
+			levels := 0
+			for m := &e; len(m.Attachments) == 2 && m.Attachments[1].Message != nil; m = m.Attachments[1].Message {
+				levels++
+			}
+
+			if levels != td.depth {
+				t.Errorf("[Test Case %v] Wrong nesting. Expected: %v, Got: %v", index, td.depth, levels)
+			}
+			continue
+		}
+
+		if limitErr, ok := err.(*LimitError); !ok || *limitErr != *td.limit {
+			t.Errorf("[Test Case %v] Wrong error. Expected: %v, Got: %v", index, td.limit, err)
+		}
+	}
+}
+
+func TestReturnedMessageNestingLimit(t *testing.T) {
+	mailData := "From: MAILER-DAEMON@example.com\nContent-Type: multipart/report; report-type=delivery-status; boundary=r\n\n" +
+		"--r\nContent-Type: text/plain\n\nUndeliverable\n" +
+		"--r\nContent-Type: message/rfc822\n\n" + nestedMessage(3) + "\n--r--\n"
+
+	if _, err := ParseWithOptions(strings.NewReader(mailData), WithMaxNestingDepth(4)); err == nil {
+		t.Errorf("Expected a nesting depth error")
+	} else if limitErr, ok := err.(*LimitError); !ok || limitErr.Limit != LimitNestingDepth {
+		t.Errorf("Wrong error: %v", err)
+	}
+
+	e, err := ParseWithOptions(strings.NewReader(mailData), WithMaxNestingDepth(8))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.OriginalMessage == nil || e.OriginalMessage.Subject != "Level 3" {
+		t.Errorf("Returned message not parsed: %+v", e.OriginalMessage)
+	}
+}
@@ -0,0 +1,72 @@
+package parsemail
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// msgIDEncoding encodes the random part of generated message IDs with characters valid in a dot-atom
+var msgIDEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// GenerateMessageID returns a new Message-ID field value for domain, like
+// <jt4n2l0.4fbm7ybq6u5fyyq3gdcm4bbrm4@example.com>, angle brackets included.
+// It combines the current time with 128 random bits, so IDs don't collide
+// across hosts generating them for the same domain. Internationalized
+// domains are encoded as punycode.
+func GenerateMessageID(domain string) (string, error) {
+	domain = strings.TrimSpace(domain)
+	if domain == "" || strings.ContainsAny(domain, "@<>\"()[]\\,;: \t") {
+		return "", fmt.Errorf("Invalid message id domain: %q", domain)
+	}
+
+	domain, err := ASCIIDomain(domain)
+	if err != nil {
+		return "", err
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 36)
+
+	return "<" + timestamp + "." + msgIDEncoding.EncodeToString(random) + "@" + domain + ">", nil
+}
+
+// splitMessageIDs returns the message IDs of a msg-id list without angle
+// brackets. IDs may be separated by any whitespace, commas or nothing at all,
+// comments are dropped and IDs missing their angle brackets are kept.
+func splitMessageIDs(s string) (ids []string) {
+	s, _ = splitComments(s)
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '<':
+			end := strings.IndexByte(s[i:], '>')
+			if end == -1 {
+				end = len(s) - i
+			}
+
+			if id := strings.TrimSpace(s[i+1 : i+end]); id != "" {
+				ids = append(ids, id)
+			}
+			i += end + 1
+		case c == ',' || c == '>' || c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		default:
+			end := strings.IndexAny(s[i:], "<>, \t\r\n")
+			if end == -1 {
+				end = len(s) - i
+			}
+
+			ids = append(ids, s[i:i+end])
+			i += end
+		}
+	}
+
+	return
+}
@@ -0,0 +1,72 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateMessageID(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id, err := GenerateMessageID("example.com")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !msgIDPattern.MatchString(id) || !strings.HasSuffix(id, "@example.com>") {
+			t.Errorf("Invalid message id: %s", id)
+		}
+
+		if seen[id] {
+			t.Errorf("Duplicate message id: %s", id)
+		}
+		seen[id] = true
+	}
+
+	if id, err := GenerateMessageID("bücher.example"); err != nil || !strings.HasSuffix(id, "@xn--bcher-kva.example>") {
+		t.Errorf("Wrong internationalized message id: %s %v", id, err)
+	}
+
+	for _, domain := range []string{"", "exa mple.com", "a@example.com", "<example.com>"} {
+		if _, err := GenerateMessageID(domain); err == nil {
+			t.Errorf("Expected error for domain %q", domain)
+		}
+	}
+}
+
+func TestMessageIDLists(t *testing.T) {
+	var testData = map[int]struct {
+		references string
+		expected   []string
+	}{
+		1: {references: "<a@example.com> <b@example.com>", expected: []string{"a@example.com", "b@example.com"}},
+		2: {references: "<a@example.com>\n <b@example.com>\t<c@example.com>", expected: []string{"a@example.com", "b@example.com", "c@example.com"}},
+		3: {references: "<a@example.com> (the original) <b@example.com>", expected: []string{"a@example.com", "b@example.com"}},
+		4: {references: "<a@example.com><b@example.com>,<c@example.com>", expected: []string{"a@example.com", "b@example.com", "c@example.com"}},
+		5: {references: "a@example.com  b@example.com", expected: []string{"a@example.com", "b@example.com"}},
+		6: {references: "<a@example.com", expected: []string{"a@example.com"}},
+		7: {references: "", expected: nil},
+	}
+
+	for index, td := range testData {
+		ids := splitMessageIDs(td.references)
+		if strings.Join(ids, "|") != strings.Join(td.expected, "|") {
+			t.Errorf("[Test Case %v] Wrong ids. Expected: %v, Got: %v", index, td.expected, ids)
+		}
+	}
+
+	message := "From: a@example.com\nMessage-ID: <id@example.com> (generated)\nReferences: <a@example.com>\n\t<b@example.com> (second)\n\nbody\n"
+	e, err := Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.MessageID != "id@example.com" || strings.Join(e.References, "|") != "a@example.com|b@example.com" {
+		t.Errorf("Wrong message ids: %s %v", e.MessageID, e.References)
+	}
+
+	e, _ = ParseWithOptions(strings.NewReader(message), WithCompatLevel(CompatV2))
+	if len(e.References) != 3 {
+		t.Errorf("Expected CompatV2 to keep splitting on spaces only, got %v", e.References)
+	}
+}
@@ -0,0 +1,107 @@
+package parsemail
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// NormalizeAddress returns the canonical form of an address: comments,
+// whitespace and angle brackets are removed and the domain is lower cased.
+// With providerRules the local part is also canonicalized the way the
+// address' provider treats it, like ignoring dots and case for Gmail, see
+// SubaddressConvention. Sub-address tags are kept, use SplitSubaddress to
+// remove them.
+func NormalizeAddress(address string, providerRules bool) string {
+	return normalizeAddress(address, providerRules, nil)
+}
+
+// NormalizeAddress normalizes address like the package level NormalizeAddress,
+// with the conventions registered for the Parser, see WithSubaddressConvention
+func (p *Parser) NormalizeAddress(address string, providerRules bool) string {
+	return normalizeAddress(address, providerRules, &p.o.registry)
+}
+
+func normalizeAddress(address string, providerRules bool, reg *registry) string {
+	address = strings.Trim(stripAddressComments(address), "<>")
+	local, domain := splitAddress(address)
+	if domain == "" {
+		return address
+	}
+
+	domain = strings.ToLower(domain)
+	if providerRules && !strings.HasPrefix(local, "\"") {
+		c, _ := reg.subaddressConvention(domain)
+		if c.IgnoreDots {
+			local = strings.Replace(local, ".", "", -1)
+		}
+
+		if c.FoldCase {
+			local = strings.ToLower(local)
+		}
+	}
+
+	return local + "@" + domain
+}
+
+// stripAddressComments removes comments and whitespace outside of quoted strings
+func stripAddressComments(s string) string {
+	s, _ = splitComments(s)
+
+	var sb strings.Builder
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && quoted && i+1 < len(s):
+			sb.WriteByte(c)
+			sb.WriteByte(s[i+1])
+			i++
+		case c == '"':
+			quoted = !quoted
+			sb.WriteByte(c)
+		case !quoted && (c == ' ' || c == '\t' || c == '\r' || c == '\n'):
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// DedupeAddresses merges the address lists, keeping the first occurrence of
+// every normalized address (see NormalizeAddress) in order. Entries without
+// an address are dropped.
+func DedupeAddresses(providerRules bool, lists ...[]*mail.Address) []*mail.Address {
+	return dedupeAddresses(providerRules, nil, lists)
+}
+
+// DedupeAddresses merges the address lists like the package level
+// DedupeAddresses, with the conventions registered for the Parser
+func (p *Parser) DedupeAddresses(providerRules bool, lists ...[]*mail.Address) []*mail.Address {
+	return dedupeAddresses(providerRules, &p.o.registry, lists)
+}
+
+func dedupeAddresses(providerRules bool, reg *registry, lists [][]*mail.Address) (result []*mail.Address) {
+	seen := map[string]bool{}
+	for _, list := range lists {
+		for _, a := range list {
+			if a == nil || a.Address == "" {
+				continue
+			}
+
+			key := normalizeAddress(a.Address, providerRules, reg)
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			result = append(result, a)
+		}
+	}
+
+	return
+}
+
+// Recipients returns the To, Cc and Bcc recipients of the email without duplicates
+func (e Email) Recipients() []*mail.Address {
+	return DedupeAddresses(false, e.To, e.Cc, e.Bcc)
+}
@@ -0,0 +1,55 @@
+package parsemail
+
+import (
+	"net/mail"
+	"testing"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	var testData = map[int]struct {
+		address       string
+		providerRules bool
+		normalized    string
+	}{
+		1: {address: "Jane@Example.COM", normalized: "Jane@example.com"},
+		2: {address: " <jane (work) @ example.com> ", normalized: "jane@example.com"},
+		3: {address: "jane(a (nested) comment)@example.com", normalized: "jane@example.com"},
+		4: {address: `"jane (not a comment)"@Example.com`, normalized: `"jane (not a comment)"@example.com`},
+		5: {address: "J.Doe@GMail.com", normalized: "J.Doe@gmail.com"},
+		6: {address: "J.Doe@GMail.com", providerRules: true, normalized: "jdoe@gmail.com"},
+		7: {address: "J.Doe@example.com", providerRules: true, normalized: "J.Doe@example.com"},
+		8: {address: "undisclosed-recipients", normalized: "undisclosed-recipients"},
+	}
+
+	for index, td := range testData {
+		if n := NormalizeAddress(td.address, td.providerRules); n != td.normalized {
+			t.Errorf("[Test Case %v] Wrong normalized address. Expected: '%s', Got: '%s'", index, td.normalized, n)
+		}
+	}
+}
+
+func TestDedupeAddresses(t *testing.T) {
+	to := []*mail.Address{{Name: "Jane", Address: "jane@example.com"}, {Address: "j.doe@gmail.com"}}
+	cc := []*mail.Address{{Address: "jane@EXAMPLE.com"}, {Address: "jdoe@gmail.com"}, {Name: "nobody"}}
+	bcc := []*mail.Address{{Address: "john@example.com"}}
+
+	e := Email{To: to, Cc: cc, Bcc: bcc}
+	expected := []string{"jane@example.com", "j.doe@gmail.com", "jdoe@gmail.com", "john@example.com"}
+	assertAddressList(t, "Recipients", e.Recipients(), expected)
+
+	expected = []string{"jane@example.com", "j.doe@gmail.com", "john@example.com"}
+	assertAddressList(t, "provider rules", DedupeAddresses(true, to, cc, bcc), expected)
+}
+
+func assertAddressList(t *testing.T, name string, list []*mail.Address, expected []string) {
+	if len(list) != len(expected) {
+		t.Errorf("%s: Wrong number of addresses. Expected: %v, Got: %v", name, expected, list)
+		return
+	}
+
+	for i, a := range list {
+		if a.Address != expected[i] {
+			t.Errorf("%s: Wrong address %v. Expected: '%s', Got: '%s'", name, i, expected[i], a.Address)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package parsemail
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// parseObsoleteAddressList parses address lists using the obsolete syntax of
+// RFC 5322 section 4.4 which mail.ParseAddressList rejects: routes in angle
+// addresses (<@relay:user@host>) and comments within addresses. Comments in
+// front of an angle address are dropped, the last comment after a plain
+// address is used as its display name.
+func parseObsoleteAddressList(s string) (list []*mail.Address, err error) {
+	for _, piece := range splitAddressListOn(s, ",") {
+		if strings.TrimSpace(piece) == "" {
+			continue
+		}
+
+		a, err := parseObsoleteAddress(piece)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, a)
+	}
+
+	if len(list) == 0 {
+		return nil, fmt.Errorf("No address in %q", s)
+	}
+
+	return
+}
+
+func parseObsoleteAddress(s string) (*mail.Address, error) {
+	withoutComments, comments := splitComments(s)
+
+	a := &mail.Address{}
+	if lt := strings.IndexByte(withoutComments, '<'); lt != -1 {
+		gt := strings.LastIndexByte(withoutComments, '>')
+		if gt < lt {
+			return nil, fmt.Errorf("Unclosed angle address in %q", s)
+		}
+
+		if strings.TrimSpace(withoutComments[gt+1:]) != "" {
+			return nil, fmt.Errorf("Unexpected text after angle address in %q", s)
+		}
+
+		a.Name = unquoteDisplayName(withoutComments[:lt])
+		a.Address = stripObsoleteRoute(joinObsoleteWords(withoutComments[lt+1 : gt]))
+	} else {
+		a.Address = joinObsoleteWords(withoutComments)
+		if len(comments) > 0 {
+			a.Name = decodeMimeSentence(strings.TrimSpace(comments[len(comments)-1]))
+		}
+	}
+
+	// validate the cleaned up address with the strict parser
+	parsed, err := mail.ParseAddress("<" + a.Address + ">")
+	if err != nil {
+		return nil, err
+	}
+
+	a.Address = parsed.Address
+
+	return a, nil
+}
+
+// joinObsoleteWords removes the whitespace the obsolete syntax allows around
+// the "@" and "." of an address. Other whitespace is kept, so the strict parser
+// rejects the result.
+func joinObsoleteWords(s string) string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return ""
+	}
+
+	joined := words[0]
+	for _, w := range words[1:] {
+		if strings.HasSuffix(joined, "@") || strings.HasSuffix(joined, ".") || strings.HasPrefix(w, "@") || strings.HasPrefix(w, ".") {
+			joined += w
+		} else {
+			joined += " " + w
+		}
+	}
+
+	return joined
+}
+
+// stripObsoleteRoute removes the source route (@relay1,@relay2:) of an obs-angle-addr
+func stripObsoleteRoute(addr string) string {
+	if !strings.HasPrefix(addr, "@") {
+		return addr
+	}
+
+	if colon := strings.IndexByte(addr, ':'); colon != -1 {
+		return addr[colon+1:]
+	}
+
+	return addr
+}
+
+// splitComments returns s without the comments outside of quoted strings, and
+// the text of the top level comments
+func splitComments(s string) (string, []string) {
+	var sb, comment strings.Builder
+	var comments []string
+	quoted, depth := false, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && (quoted || depth > 0):
+			if quoted {
+				sb.WriteByte(c)
+				sb.WriteByte(s[i+1])
+			} else {
+				comment.WriteByte(s[i+1])
+			}
+			i++
+		case c == '"' && depth == 0:
+			quoted = !quoted
+			sb.WriteByte(c)
+		case quoted:
+			sb.WriteByte(c)
+		case c == '(':
+			if depth > 0 {
+				comment.WriteByte(c)
+			}
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+			if depth > 0 {
+				comment.WriteByte(c)
+			} else {
+				comments = append(comments, comment.String())
+				comment.Reset()
+				sb.WriteByte(' ')
+			}
+		case depth > 0:
+			comment.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), comments
+}
@@ -0,0 +1,63 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestObsoleteAddresses(t *testing.T) {
+	var testData = map[int]struct {
+		header    string
+		addresses []string
+		names     []string
+	}{
+		1: {
+			header:    "Jane <@relay.example:jane@example.com>",
+			addresses: []string{"jane@example.com"},
+			names:     []string{"Jane"},
+		},
+		2: {
+			header:    "<@a.example,@b.example:jane@example.com>, john@example.com",
+			addresses: []string{"jane@example.com", "john@example.com"},
+			names:     []string{"", ""},
+		},
+		3: {
+			header:    `Pete(A nice \) chap) <pete(his account)@silly.test(his host)>`,
+			addresses: []string{"pete@silly.test"},
+			names:     []string{"Pete"},
+		},
+		4: {
+			header:    "jane(work) @ example . com (Jane Doe)",
+			addresses: []string{"jane@example.com"},
+			names:     []string{"Jane Doe"},
+		},
+		5: {
+			header:    "Jane Doe jane@example.com",
+			addresses: []string{},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader("From: a@example.com\nTo: " + td.header + "\n\nbody\n"))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if len(e.To) != len(td.addresses) {
+			t.Errorf("[Test Case %v] Wrong number of addresses. Expected: %v, Got: %v", index, td.addresses, e.To)
+			continue
+		}
+
+		for i, a := range e.To {
+			if a.Address != td.addresses[i] || a.Name != td.names[i] {
+				t.Errorf("[Test Case %v] Wrong address %v. Expected: '%s <%s>', Got: '%s <%s>'", index, i, td.names[i], td.addresses[i], a.Name, a.Address)
+			}
+		}
+	}
+
+	e, _ := Parse(strings.NewReader("From: a@example.com\nSender: <@relay.example:jane@example.com>\n\nbody\n"))
+	if e.Sender == nil || e.Sender.Address != "jane@example.com" {
+		t.Errorf("Wrong sender with obsolete route: %v", e.Sender)
+	}
+}
@@ -0,0 +1,160 @@
+package parsemail
+
+import (
+	"bytes"
+	"net/textproto"
+	"strings"
+)
+
+// PartOffsets locate a part in the source message, as byte offsets from its start
+type PartOffsets struct {
+	// Start is the offset of the part header
+	Start int64
+	// BodyStart is the offset of the transfer encoded content, after the blank line ending the header
+	BodyStart int64
+	// End is the offset just past the content, before the line break preceding the next boundary
+	End int64
+}
+
+// partLocator finds the parts of the multipart levels being parsed in the
+// message body read so far. Its methods do nothing on a nil partLocator.
+// origin is the offset of the message in the source, non zero for nested
+// messages, and base the length of its header.
+type partLocator struct {
+	raw    *bytes.Buffer
+	origin int64
+	base   int64
+	levels []*partLevel
+}
+
+// identityEncoded reports whether the part header h leaves the content as it
+// is in the source, so offsets into the decoded content are source offsets too
+func identityEncoded(h textproto.MIMEHeader) bool {
+	switch strings.ToLower(strings.TrimSpace(h.Get(headerContentEncoding))) {
+	case encoding7bit, encoding8Bit, encodingBinary, encodingEmpty:
+		return h.Get(headerCompression) == ""
+	}
+
+	return false
+}
+
+// partLevel is a multipart entity being parsed, its offsets relative to raw
+type partLevel struct {
+	delimiter []byte
+	cursor    int
+	current   PartOffsets
+}
+
+// enter starts a multipart level with boundary, within the current part of the enclosing level
+func (l *partLocator) enter(boundary string) {
+	if l == nil {
+		return
+	}
+
+	level := &partLevel{delimiter: []byte("--" + boundary)}
+	if n := len(l.levels); n > 0 {
+		level.cursor = int(l.levels[n-1].current.BodyStart)
+	}
+
+	l.levels = append(l.levels, level)
+}
+
+// leave ends the innermost multipart level
+func (l *partLocator) leave() {
+	if l == nil {
+		return
+	}
+
+	l.levels = l.levels[:len(l.levels)-1]
+}
+
+// next locates the header and content start of the part just read at the innermost level
+func (l *partLocator) next() {
+	if l == nil {
+		return
+	}
+
+	level := l.levels[len(l.levels)-1]
+	data := l.raw.Bytes()
+	i := findDelimiter(data, level.delimiter, level.cursor)
+	if i == -1 {
+		return
+	}
+
+	start := lineEnd(data, i)
+	body := start
+	for body < len(data) {
+		end := lineEnd(data, body)
+		if len(bytes.TrimRight(data[body:end], "\r\n")) == 0 {
+			body = end
+			break
+		}
+		body = end
+	}
+
+	level.current = PartOffsets{Start: int64(start), BodyStart: int64(body), End: int64(body)}
+	level.cursor = body
+}
+
+// end returns the offsets of the part just read at the innermost level in
+// the source, its content must have been read to the end. Outside of a
+// multipart level the part is the whole message.
+func (l *partLocator) end() PartOffsets {
+	if l == nil {
+		return PartOffsets{}
+	}
+
+	if len(l.levels) == 0 {
+		return PartOffsets{Start: l.origin, BodyStart: l.origin + l.base, End: l.origin + l.base + int64(l.raw.Len())}
+	}
+
+	level := l.levels[len(l.levels)-1]
+	data := l.raw.Bytes()
+	if i := findDelimiter(data, level.delimiter, int(level.current.BodyStart)); i != -1 {
+		end := i
+		if end > 0 && data[end-1] == '\n' {
+			end--
+			if end > 0 && data[end-1] == '\r' {
+				end--
+			}
+		}
+
+		if end > int(level.current.BodyStart) {
+			level.current.End = int64(end)
+		}
+		level.cursor = i
+	}
+
+	return PartOffsets{
+		Start:     l.origin + l.base + level.current.Start,
+		BodyStart: l.origin + l.base + level.current.BodyStart,
+		End:       l.origin + l.base + level.current.End,
+	}
+}
+
+// findDelimiter returns the index of the first boundary delimiter line in data from offset, or -1
+func findDelimiter(data, delimiter []byte, offset int) int {
+	for offset <= len(data) {
+		i := bytes.Index(data[offset:], delimiter)
+		if i == -1 {
+			return -1
+		}
+
+		i += offset
+		if i == 0 || data[i-1] == '\n' {
+			return i
+		}
+		offset = i + len(delimiter)
+	}
+
+	return -1
+}
+
+// lineEnd returns the index just past the line of data containing i
+func lineEnd(data []byte, i int) int {
+	if j := bytes.IndexByte(data[i:], '\n'); j != -1 {
+		return i + j + 1
+	}
+
+	return len(data)
+}
@@ -0,0 +1,174 @@
+package parsemail
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestPartOffsets(t *testing.T) {
+	var testData = map[int]struct {
+		mailData string
+		options  []Option
+	}{
+		1: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\npreamble\n" +
+				"--m\nContent-Type: text/plain\n\nbody\n" +
+				"--m\nContent-Type: multipart/related; boundary=r\n\n" +
+				"--r\nContent-Type: text/html\n\n<img src=\"cid:img@x\">\n" +
+				"--r\nContent-Type: image/png\nContent-ID: <img@x>\nContent-Transfer-Encoding: base64\n\niVBORw==\n--r--\n" +
+				"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\"\nContent-Transfer-Encoding: base64\n\naGVs\nbG8=\n--m--\n",
+		},
+		2: {
+			mailData: "From: jdoe@machine.example\r\nContent-Type: multipart/mixed; boundary=m\r\n\r\n" +
+				"--m\r\nContent-Type: text/plain\r\n\r\nbody\r\n" +
+				"--m\r\nContent-Type: image/png\r\nContent-ID: <img@x>\r\nContent-Transfer-Encoding: base64\r\n\r\niVBORw==\r\n" +
+				"--m\r\nContent-Type: text/plain\r\nContent-Disposition: attachment; filename=\"a.txt\"\r\nContent-Transfer-Encoding: base64\r\n\r\naGVs\r\nbG8=\r\n--m--\r\n",
+		},
+		3: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+				"--m\nContent-Type: image/png\nContent-ID: <img@x>\nContent-Transfer-Encoding: base64\n\niVBORw==\n" +
+				"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\"\nContent-Transfer-Encoding: base64\n\naGVs\nbG8=\n--m--\n",
+			options: []Option{WithAttachmentStreaming(func(Attachment) error { return nil })},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := ParseWithOptions(strings.NewReader(td.mailData), append(td.options, WithPartOffsets())...)
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if len(e.Attachments) != 1 || len(e.EmbeddedFiles) != 1 {
+			t.Errorf("[Test Case %v] Wrong parts. Expected: 1 attachment and 1 embedded file, Got: %v and %v", index, len(e.Attachments), len(e.EmbeddedFiles))
+			continue
+		}
+
+		for _, part := range []struct {
+			name    string
+			offsets PartOffsets
+			header  string
+			body    string
+		}{
+			{"attachment", e.Attachments[0].Offsets, "Content-Type: text/plain", "aGVs\nbG8="},
+			{"embedded file", e.EmbeddedFiles[0].Offsets, "Content-Type: image/png", "iVBORw=="},
+		} {
+			header := td.mailData[part.offsets.Start:part.offsets.BodyStart]
+			if !strings.HasPrefix(header, part.header) {
+				t.Errorf("[Test Case %v] Wrong %v header. Expected prefix: %q, Got: %q", index, part.name, part.header, header)
+			}
+
+			body := strings.Replace(td.mailData[part.offsets.BodyStart:part.offsets.End], "\r\n", "\n", -1)
+			if body != part.body {
+				t.Errorf("[Test Case %v] Wrong %v body. Expected: %q, Got: %q", index, part.name, part.body, body)
+			}
+		}
+	}
+}
+
+func TestPartOffsetsDisabled(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.Attachments[0].Offsets != (PartOffsets{}) {
+		t.Errorf("Unexpected offsets: %+v", e.Attachments[0].Offsets)
+	}
+}
+
+func TestBodyPartOffsets(t *testing.T) {
+	nested := "Subject: Forwarded\nContent-Type: multipart/mixed; boundary=n\n\n" +
+		"--n\nContent-Type: text/plain\n\nnested body\n" +
+		"--n\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"b.txt\"\nContent-Transfer-Encoding: base64\n\nd29ybGQ=\n--n--\n"
+
+	var testData = map[int]struct {
+		mailData string
+		text     []string
+		html     []string
+		nested   []string
+	}{
+		1: {
+			mailData: "From: jdoe@machine.example\nContent-Type: text/plain\n\nsingle\n",
+			text:     []string{"single\n"},
+		},
+		2: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/alternative; boundary=a\n\n" +
+				"--a\nContent-Type: text/plain\n\nplain\n--a\nContent-Type: text/html\n\n<p>html</p>\n--a--\n",
+			text: []string{"plain"},
+			html: []string{"<p>html</p>"},
+		},
+		3: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+				"--m\nContent-Type: text/plain\n\nouter body\n" +
+				"--m\nContent-Type: message/rfc822\nContent-Disposition: attachment; filename=\"fwd.eml\"\n\n" + nested + "--m--\n",
+			text:   []string{"outer body"},
+			nested: []string{"nested body", "d29ybGQ="},
+		},
+		4: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+				"--m\nContent-Type: text/plain\n\nouter body\n" +
+				"--m\nContent-Type: message/rfc822\nContent-Disposition: attachment; filename=\"fwd.eml\"\nContent-Transfer-Encoding: base64\n\n" +
+				base64.StdEncoding.EncodeToString([]byte(nested)) + "\n--m--\n",
+			text:   []string{"outer body"},
+			nested: []string{},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := ParseWithOptions(strings.NewReader(td.mailData), WithPartOffsets())
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		source := func(o PartOffsets) string {
+			return td.mailData[o.BodyStart:o.End]
+		}
+
+		if len(e.TextBodyPartOffsets) != len(td.text) || len(e.HTMLBodyPartOffsets) != len(td.html) {
+			t.Errorf("[Test Case %v] Wrong number of body part offsets: %v, %v", index, e.TextBodyPartOffsets, e.HTMLBodyPartOffsets)
+			continue
+		}
+
+		for i, expected := range td.text {
+			if got := source(e.TextBodyPartOffsets[i]); got != expected {
+				t.Errorf("[Test Case %v] Wrong text part %v. Expected: %q, Got: %q", index, i, expected, got)
+			}
+		}
+
+		for i, expected := range td.html {
+			if got := source(e.HTMLBodyPartOffsets[i]); got != expected {
+				t.Errorf("[Test Case %v] Wrong HTML part %v. Expected: %q, Got: %q", index, i, expected, got)
+			}
+		}
+
+		if td.nested == nil {
+			continue
+		}
+
+		m := e.Attachments[0].Message
+		if m == nil {
+			t.Errorf("[Test Case %v] Attached message not parsed", index)
+			continue
+		}
+
+		if len(td.nested) == 0 {
+			if len(m.TextBodyPartOffsets) != 0 || m.Attachments[0].Offsets != (PartOffsets{}) {
+				t.Errorf("[Test Case %v] Offsets recorded for an encoded message: %v, %v", index, m.TextBodyPartOffsets, m.Attachments[0].Offsets)
+			}
+			continue
+		}
+
+		nestedOffsets := append(m.TextBodyPartOffsets, m.Attachments[0].Offsets)
+		for i, expected := range td.nested {
+			if got := source(nestedOffsets[i]); got != expected {
+				t.Errorf("[Test Case %v] Wrong nested part %v. Expected: %q, Got: %q", index, i, expected, got)
+			}
+		}
+	}
+}
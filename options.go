@@ -0,0 +1,489 @@
+package parsemail
+
+import (
+	"net/textproto"
+	"strings"
+)
+
+// Option configures optional parsing behavior, see ParseWithOptions and NewParser
+type Option func(*options)
+
+// CompatLevel pins parsing semantics that were corrected in later releases, so
+// existing pipelines keep their results stable across upgrades. Every level
+// lists the changes it introduces, the default is CompatLatest.
+type CompatLevel int
+
+const (
+	// CompatV1 keeps the semantics of the first releases
+	CompatV1 CompatLevel = iota + 1
+	// CompatV2 returns decode errors of single part bodies instead of ignoring them
+	CompatV2
+	// CompatV3 splits Message-ID lists on any whitespace and drops comments
+	CompatV3
+	// CompatV4 trims a trailing CRLF off body parts as a whole, not just its LF
+	CompatV4
+	// CompatV5 classifies parts by their parsed Content-Disposition, so parts
+	// disposed as attachments are no longer embedded files
+	CompatV5
+
+	// CompatLatest always refers to the most recent level
+	CompatLatest = CompatV5
+)
+
+// BodyTrimming selects what is trimmed off the end of body parts before they
+// are added to the body, see WithBodyTrimming
+type BodyTrimming int
+
+const (
+	// TrimLineEnding trims a single trailing line ending, this is the default
+	TrimLineEnding BodyTrimming = iota
+	// TrimNone keeps body parts as they are
+	TrimNone
+	// TrimTrailingSpace trims all trailing white space including blank lines
+	TrimTrailingSpace
+)
+
+type options struct {
+	compat CompatLevel
+
+	strictMIMEVersion bool
+	lenientAddresses  bool
+
+	octetStreamHeuristics bool
+	htmlToTextFallback    bool
+	textToHTMLFallback    bool
+	textAsHTML            bool
+	detectSignature       bool
+	stripSignature        bool
+	sanitizeHTML          bool
+
+	htmlCharsetPrecedence CharsetPrecedence
+
+	extractDataURIs bool
+	dataURIMinSize  int
+
+	bodySeparator string
+	bodyTrimming  BodyTrimming
+	bodyPartsOnly bool
+	bodyReaders   bool
+	keepRawBody   bool
+
+	dateParser DateParserFunc
+
+	attachmentStream AttachmentStreamFunc
+	attachmentFilter AttachmentFilterFunc
+	scanners         []Scanner
+
+	sniffAttachments bool
+	keepRawParts     bool
+	partOffsets      bool
+	listZipEntries   bool
+
+	maxAttachments         int
+	maxAttachmentSize      int64
+	maxTotalAttachmentSize int64
+	maxDecodedSize         int64
+	maxNestingDepth        int
+
+	spool          bool
+	spoolThreshold int64
+	spoolDir       string
+
+	registry registry
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{compat: CompatLatest, maxNestingDepth: defaultMaxNestingDepth}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithCompatLevel pins the parsing semantics to those of level, see CompatLevel
+func WithCompatLevel(level CompatLevel) Option {
+	return func(o *options) {
+		o.compat = level
+	}
+}
+
+// WithStrictMIMEVersion makes parsing fail for messages with MIME structure (a
+// Content-Type or Content-Transfer-Encoding header) that lack a MIME-Version
+// header or declare a version other than 1.0
+func WithStrictMIMEVersion() Option {
+	return func(o *options) {
+		o.strictMIMEVersion = true
+	}
+}
+
+// WithLenientAddresses salvages best effort addresses out of malformed address
+// headers (bare display names, missing angle brackets, trailing semicolons,
+// duplicated addresses) instead of dropping them. Every salvaged header is
+// reported in Email.Warnings.
+func WithLenientAddresses() Option {
+	return func(o *options) {
+		o.lenientAddresses = true
+	}
+}
+
+// WithOctetStreamHeuristics sniffs single part messages labeled
+// application/octet-stream, as produced by some broken scanners, and recovers
+// them into TextBody or HTMLBody when they contain text or HTML
+func WithOctetStreamHeuristics() Option {
+	return func(o *options) {
+		o.octetStreamHeuristics = true
+	}
+}
+
+// WithDateParser sets a parser for date header fields the built-in parsing and
+// the layouts added by RegisterDateLayout fail on
+func WithDateParser(fn DateParserFunc) Option {
+	return func(o *options) {
+		o.dateParser = fn
+	}
+}
+
+// WithHTMLToTextFallback fills TextBody with a plain text rendering of
+// HTMLBody, see HTMLToText, for messages that have only a HTML body
+func WithHTMLToTextFallback() Option {
+	return func(o *options) {
+		o.htmlToTextFallback = true
+	}
+}
+
+// WithTextToHTMLFallback fills HTMLBody with the escaped and linkified HTML
+// rendering of TextBody (see Email.TextAsHTML) for messages that have only a
+// text body, so every message can be rendered as HTML
+func WithTextToHTMLFallback() Option {
+	return func(o *options) {
+		o.textToHTMLFallback = true
+	}
+}
+
+// WithTextAsHTML sets Email.TextAsHTML to the escaped and linkified HTML
+// rendering of TextBody for messages that have only a text body
+func WithTextAsHTML() Option {
+	return func(o *options) {
+		o.textAsHTML = true
+	}
+}
+
+// WithSignatureDetection sets Email.Signature to the signature block at the
+// end of TextBody, see SplitSignature
+func WithSignatureDetection() Option {
+	return func(o *options) {
+		o.detectSignature = true
+	}
+}
+
+// WithSignatureStripping removes the signature block from TextBody, it is
+// still available in Email.Signature
+func WithSignatureStripping() Option {
+	return func(o *options) {
+		o.stripSignature = true
+	}
+}
+
+// WithHTMLSanitization replaces HTMLBody with a render safe version, see SanitizeHTML
+func WithHTMLSanitization() Option {
+	return func(o *options) {
+		o.sanitizeHTML = true
+	}
+}
+
+// WithDataURIExtraction moves data: URI images of HTMLBody that decode to at
+// least minSize bytes into EmbeddedFiles, see ExtractDataURIs
+func WithDataURIExtraction(minSize int) Option {
+	return func(o *options) {
+		o.extractDataURIs = true
+		o.dataURIMinSize = minSize
+	}
+}
+
+// WithBodySeparator sets the separator inserted between the parts joined into
+// TextBody and HTMLBody, by default they are concatenated without one
+func WithBodySeparator(sep string) Option {
+	return func(o *options) {
+		o.bodySeparator = sep
+	}
+}
+
+// WithoutBodyTrimming keeps the trailing newline of body parts, which is
+// otherwise removed before they are added to the body. It is the same as
+// WithBodyTrimming(TrimNone).
+func WithoutBodyTrimming() Option {
+	return WithBodyTrimming(TrimNone)
+}
+
+// WithBodyTrimming selects what is trimmed off the end of body parts
+func WithBodyTrimming(t BodyTrimming) Option {
+	return func(o *options) {
+		o.bodyTrimming = t
+	}
+}
+
+// WithHTMLCharsetPrecedence decodes HTML bodies to UTF-8, from the charset
+// selected by p when their <meta> charset disagrees with the Content-Type
+// charset, and updates the <meta> charset accordingly. Disagreements are
+// reported in Email.Warnings regardless of this option.
+func WithHTMLCharsetPrecedence(p CharsetPrecedence) Option {
+	return func(o *options) {
+		o.htmlCharsetPrecedence = p
+	}
+}
+
+// WithSeparateBodyParts keeps body parts only in TextBodyParts and
+// HTMLBodyParts, leaving TextBody and HTMLBody empty
+func WithSeparateBodyParts() Option {
+	return func(o *options) {
+		o.bodyPartsOnly = true
+	}
+}
+
+// WithBodyReaders keeps the decoded text and HTML body parts like attachment
+// data, in memory or WithSpooling in temporary files, to be read through
+// Email.TextBodyReader and Email.HTMLBodyReader. TextBody, HTMLBody and their
+// parts stay empty, so the options working on them don't apply, and HTML body
+// parts are kept in the charset they were sent in.
+func WithBodyReaders() Option {
+	return func(o *options) {
+		o.bodyReaders = true
+	}
+}
+
+// WithRawBody keeps the body as it was read, before transfer decoding and with
+// its original line endings, in Email.RawBody. It is needed to verify DKIM or
+// ARC signatures and to forward the message unmodified.
+func WithRawBody() Option {
+	return func(o *options) {
+		o.keepRawBody = true
+	}
+}
+
+// WithAttachmentStreaming hands attachments to fn while the message is parsed,
+// with Data decoding the attachment lazily from the message, so large
+// attachments can be copied elsewhere without being buffered in memory. The
+// data fn doesn't read is discarded and the attachments of the returned Email
+// can't be read anymore. An error returned by fn aborts the parse.
+func WithAttachmentStreaming(fn AttachmentStreamFunc) Option {
+	return func(o *options) {
+		o.attachmentStream = fn
+	}
+}
+
+// WithSpooling keeps the decoded data of attachments and embedded files larger
+// than threshold bytes in temporary files in dir, or the default temporary
+// directory when dir is empty, instead of memory. Email.Close removes them.
+func WithSpooling(threshold int64, dir string) Option {
+	return func(o *options) {
+		o.spool = true
+		o.spoolThreshold = threshold
+		o.spoolDir = dir
+	}
+}
+
+// WithAttachmentSniffing sets Attachment.DetectedContentType for attachments
+// typed application/octet-stream or not typed at all, sniffed from their first
+// bytes with http.DetectContentType and the signatures of office, archive and
+// executable formats
+func WithAttachmentSniffing() Option {
+	return func(o *options) {
+		o.sniffAttachments = true
+	}
+}
+
+// WithZipListing lists the files contained in zip attachments, with their
+// sizes, in Attachment.ZipEntries. Only the central directory is read, the
+// files aren't extracted. Archives that can't be listed are reported in
+// Email.Warnings.
+func WithZipListing() Option {
+	return func(o *options) {
+		o.listZipEntries = true
+	}
+}
+
+// WithAttachmentFilter calls fn with the MIME header of every attachment as it
+// is encountered, deciding whether it is streamed, buffered or skipped without
+// being decoded, so disallowed attachments can be dropped early
+func WithAttachmentFilter(fn AttachmentFilterFunc) Option {
+	return func(o *options) {
+		o.attachmentFilter = fn
+	}
+}
+
+// WithScanner adds s to the scanners every attachment is streamed to while it
+// is decoded, their verdicts are stored in Attachment.Verdicts. Scan errors are
+// reported in Email.Warnings.
+func WithScanner(s Scanner) Option {
+	return func(o *options) {
+		o.scanners = append(o.scanners, s)
+	}
+}
+
+// WithRawParts keeps the content of attachments and embedded files as it was
+// sent, before transfer decoding, in their RawData, so they can be forwarded
+// verbatim or signatures over them verified. It is held in memory, also when
+// the decoded data is spooled.
+func WithRawParts() Option {
+	return func(o *options) {
+		o.keepRawParts = true
+	}
+}
+
+// WithPartOffsets records the Offsets of attachments and embedded files in the
+// source message, so a single part can later be read from the stored message
+// without parsing all of it. The message body is held in memory while parsing.
+func WithPartOffsets() Option {
+	return func(o *options) {
+		o.partOffsets = true
+	}
+}
+
+// WithMaxAttachments fails parsing with a LimitError when a message, with the
+// messages attached to it, has more than n attachments. Embedded files count
+// as attachments for this and the other attachment limits.
+func WithMaxAttachments(n int) Option {
+	return func(o *options) {
+		o.maxAttachments = n
+	}
+}
+
+// WithMaxAttachmentSize fails parsing with a LimitError as soon as an
+// attachment or embedded file decodes to more than n bytes
+func WithMaxAttachmentSize(n int64) Option {
+	return func(o *options) {
+		o.maxAttachmentSize = n
+	}
+}
+
+// WithMaxTotalAttachmentSize fails parsing with a LimitError as soon as the
+// attachments and embedded files of a message, with those of the messages
+// attached to it, decode to more than n bytes together
+func WithMaxTotalAttachmentSize(n int64) Option {
+	return func(o *options) {
+		o.maxTotalAttachmentSize = n
+	}
+}
+
+// WithMaxDecodedSize fails parsing with a LimitError as soon as the body parts,
+// attachments and embedded files of a message, with those of nested messages,
+// decode to more than n bytes together. Unlike the raw message size it also
+// bounds what compressed parts expand to.
+func WithMaxDecodedSize(n int64) Option {
+	return func(o *options) {
+		o.maxDecodedSize = n
+	}
+}
+
+// WithMaxNestingDepth fails parsing with a LimitError when multipart entities
+// and attached or returned messages nest more than n levels deep. The default
+// is 16, n <= 0 removes the limit.
+func WithMaxNestingDepth(n int) Option {
+	return func(o *options) {
+		o.maxNestingDepth = n
+	}
+}
+
+// WithCharsetDecoder registers fn for HTML bodies in charset for the parser
+// only, taking precedence over RegisterCharsetDecoder. A nil fn removes the
+// decoder.
+func WithCharsetDecoder(charset string, fn CharsetDecoderFunc) Option {
+	return func(o *options) {
+		if o.registry.charsetDecoders == nil {
+			o.registry.charsetDecoders = map[string]CharsetDecoderFunc{}
+		}
+		o.registry.charsetDecoders[normalizeCharset(charset)] = fn
+	}
+}
+
+// WithDecompressor registers fn for the Content-Encoding encoding for the
+// parser only, taking precedence over RegisterDecompressor. A nil fn removes
+// the decompressor.
+func WithDecompressor(encoding string, fn DecompressorFunc) Option {
+	return func(o *options) {
+		if o.registry.decompressors == nil {
+			o.registry.decompressors = map[string]DecompressorFunc{}
+		}
+		o.registry.decompressors[strings.ToLower(encoding)] = fn
+	}
+}
+
+// WithDateLayout adds a time.Parse layout for the parser only, tried before
+// those of RegisterDateLayout
+func WithDateLayout(layout string) Option {
+	return func(o *options) {
+		o.registry.dateLayouts = append(o.registry.dateLayouts, layout)
+	}
+}
+
+// WithHeaderParser registers fn as the parser of the header field name for the
+// parser only, taking precedence over RegisterHeaderParser. A nil fn removes
+// the field parser.
+func WithHeaderParser(name string, fn HeaderParserFunc) Option {
+	return func(o *options) {
+		if o.registry.headerParsers == nil {
+			o.registry.headerParsers = map[string]registeredHeaderParser{}
+		}
+		o.registry.headerParsers[textproto.CanonicalMIMEHeaderKey(name)] = registeredHeaderParser{name: name, parse: fn}
+	}
+}
+
+// WithContentExtractor registers x for attachments of contentType for the
+// parser only, taking precedence over RegisterContentExtractor. A nil x
+// removes the extractor.
+func WithContentExtractor(contentType string, x ContentExtractor) Option {
+	return func(o *options) {
+		if o.registry.contentExtractors == nil {
+			o.registry.contentExtractors = map[string]ContentExtractor{}
+		}
+		o.registry.contentExtractors[strings.ToLower(contentType)] = x
+	}
+}
+
+// WithExtension maps the filename extension ext to contentType for the parser
+// only, taking precedence over RegisterExtension. An empty contentType removes
+// the mapping of ext.
+func WithExtension(ext, contentType string) Option {
+	return func(o *options) {
+		if o.registry.extensionTypes == nil {
+			o.registry.extensionTypes = map[string]string{}
+			o.registry.typeExtensions = map[string]string{}
+		}
+
+		ext = normalizeExtension(ext)
+		if previous := o.registry.extensionTypes[ext]; o.registry.typeExtensions[previous] == ext {
+			delete(o.registry.typeExtensions, previous)
+		}
+
+		contentType = strings.ToLower(contentType)
+		o.registry.extensionTypes[ext] = contentType
+		if _, ok := o.registry.typeExtensions[contentType]; contentType != "" && !ok {
+			o.registry.typeExtensions[contentType] = ext
+		}
+	}
+}
+
+// WithSubaddressConvention sets the convention for addresses in domain for the
+// parser only, taking precedence over RegisterSubaddressConvention. The zero
+// value removes the convention. See Parser.SplitSubaddress.
+func WithSubaddressConvention(domain string, c SubaddressConvention) Option {
+	return func(o *options) {
+		if o.registry.subaddressConventions == nil {
+			o.registry.subaddressConventions = map[string]SubaddressConvention{}
+		}
+		o.registry.subaddressConventions[strings.ToLower(domain)] = c
+	}
+}
+
+// WithTrackingDomain adds domain, and its subdomains, to the tracking domains
+// of the parser, in addition to those of RegisterTrackingDomain. See
+// Parser.FindTrackers and Email.Trackers.
+func WithTrackingDomain(domain string) Option {
+	return func(o *options) {
+		if o.registry.trackingDomains == nil {
+			o.registry.trackingDomains = map[string]bool{}
+		}
+		o.registry.trackingDomains[strings.ToLower(strings.TrimSuffix(domain, "."))] = true
+	}
+}
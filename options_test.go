@@ -0,0 +1,270 @@
+package parsemail
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStrictMIMEVersion(t *testing.T) {
+	var testData = map[int]struct {
+		mailData    string
+		mimeVersion string
+		strictFails bool
+	}{
+		1: {
+			mailData:    data2,
+			mimeVersion: "1.0",
+		},
+		2: {
+			mailData: rfc5322exampleA11,
+		},
+		3: {
+			mailData:    data1,
+			strictFails: true,
+		},
+		4: {
+			mailData:    "MIME-Version: 1.0 (produced by metasend V1.0)\nContent-Type: text/plain\n\nHi.\n",
+			mimeVersion: "1.0",
+		},
+		5: {
+			mailData:    "MIME-Version: 2.0\nContent-Type: text/plain\n\nHi.\n",
+			mimeVersion: "2.0",
+			strictFails: true,
+		},
+	}
+
+	for index, td := range testData {
+		e, err := Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Fatalf("[Test Case %v] %v", index, err)
+		}
+
+		if e.MIMEVersion != td.mimeVersion {
+			t.Errorf("[Test Case %v] Wrong MIME version. Expected: '%s', Got: '%s'", index, td.mimeVersion, e.MIMEVersion)
+		}
+
+		_, err = ParseWithOptions(strings.NewReader(td.mailData), WithStrictMIMEVersion())
+		if (err != nil) != td.strictFails {
+			t.Errorf("[Test Case %v] Wrong strict result. Expected failure: %v, Got: %v", index, td.strictFails, err)
+		}
+	}
+}
+
+func TestOctetStreamHeuristics(t *testing.T) {
+	var testData = map[int]struct {
+		mailData string
+		htmlBody string
+		textBody string
+		fails    bool
+	}{
+		1: {
+			mailData: "From: jdoe@machine.example\nContent-Type: application/octet-stream\n\n<html><body><p>Hello</p></body></html>\n",
+			htmlBody: "<html><body><p>Hello</p></body></html>",
+		},
+		2: {
+			mailData: "From: jdoe@machine.example\nContent-Type: application/octet-stream\nContent-Transfer-Encoding: base64\n\nSGVsbG8sIHBsYWluIHRleHQu\n",
+			textBody: "Hello, plain text.",
+		},
+		3: {
+			mailData: "From: jdoe@machine.example\nContent-Type: application/octet-stream\nContent-Transfer-Encoding: base64\n\nJVBERi0xLjQNCiW1tbW1DQo=\n",
+			fails:    true,
+		},
+	}
+
+	for index, td := range testData {
+		if _, err := Parse(strings.NewReader(td.mailData)); err == nil {
+			t.Errorf("[Test Case %v] Expected octet-stream body to fail without heuristics", index)
+		}
+
+		e, err := ParseWithOptions(strings.NewReader(td.mailData), WithOctetStreamHeuristics())
+		if (err != nil) != td.fails {
+			t.Errorf("[Test Case %v] Wrong result. Expected failure: %v, Got: %v", index, td.fails, err)
+			continue
+		}
+
+		if e.HTMLBody != td.htmlBody || e.TextBody != td.textBody {
+			t.Errorf("[Test Case %v] Wrong bodies. Expected: '%s' '%s', Got: '%s' '%s'", index, td.htmlBody, td.textBody, e.HTMLBody, e.TextBody)
+		}
+
+		if !td.fails && len(e.Warnings) != 1 {
+			t.Errorf("[Test Case %v] Expected a recovery warning, got %v", index, e.Warnings)
+		}
+	}
+}
+
+func TestCompatLevel(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: text/plain\nContent-Transfer-Encoding: base64\n\nSGVsbG8s!!!\n"
+
+	if _, err := Parse(strings.NewReader(mailData)); err == nil {
+		t.Errorf("Expected decode error with the default compat level")
+	}
+
+	if _, err := ParseWithOptions(strings.NewReader(mailData), WithCompatLevel(CompatLatest)); err == nil {
+		t.Errorf("Expected decode error with CompatLatest")
+	}
+
+	e, err := ParseWithOptions(strings.NewReader(mailData), WithCompatLevel(CompatV1))
+	if err != nil {
+		t.Fatalf("Expected decode error to be ignored with CompatV1, got: %v", err)
+	}
+
+	if e.TextBody != "Hello," {
+		t.Errorf("Wrong text body. Expected: 'Hello,', Got: '%s'", e.TextBody)
+	}
+}
+
+func TestBodyJoining(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/related; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\n\nFirst\n\n--m\nContent-Type: text/plain\n\nSecond\n--m--\n"
+
+	var testData = map[int]struct {
+		options []Option
+		body    string
+		parts   []string
+	}{
+		1: {
+			body:  "FirstSecond",
+			parts: []string{"First", "Second"},
+		},
+		2: {
+			options: []Option{WithBodySeparator("\n-----\n")},
+			body:    "First\n-----\nSecond",
+			parts:   []string{"First", "Second"},
+		},
+		3: {
+			options: []Option{WithoutBodyTrimming()},
+			body:    "First\nSecond",
+			parts:   []string{"First\n", "Second"},
+		},
+		4: {
+			options: []Option{WithSeparateBodyParts()},
+			parts:   []string{"First", "Second"},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := ParseWithOptions(strings.NewReader(mailData), td.options...)
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if e.TextBody != td.body {
+			t.Errorf("[Test Case %v] Wrong text body. Expected: %q, Got: %q", index, td.body, e.TextBody)
+		}
+
+		if !assertSliceEq(td.parts, e.TextBodyParts) {
+			t.Errorf("[Test Case %v] Wrong text body parts. Expected: %q, Got: %q", index, td.parts, e.TextBodyParts)
+		}
+	}
+}
+
+func TestBodyTrimming(t *testing.T) {
+	var testData = map[int]struct {
+		body     string
+		options  []Option
+		expected string
+	}{
+		1: {
+			body:     "Hello\n\n",
+			expected: "Hello\n",
+		},
+		2: {
+			body:     "Hello\r\n\r\n",
+			expected: "Hello\r\n",
+		},
+		3: {
+			body:     "Hello\r\n",
+			options:  []Option{WithCompatLevel(CompatV3)},
+			expected: "Hello\r",
+		},
+		4: {
+			body:     "Hello\r\n\r\n \t",
+			options:  []Option{WithBodyTrimming(TrimTrailingSpace)},
+			expected: "Hello",
+		},
+		5: {
+			body:     "Hello\r\n",
+			options:  []Option{WithBodyTrimming(TrimNone)},
+			expected: "Hello\r\n",
+		},
+	}
+
+	for index, td := range testData {
+		mailData := "From: jdoe@machine.example\nContent-Type: text/plain\n\n" + td.body
+		e, err := ParseWithOptions(strings.NewReader(mailData), td.options...)
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if e.TextBody != td.expected {
+			t.Errorf("[Test Case %v] Wrong text body. Expected: %q, Got: %q", index, td.expected, e.TextBody)
+		}
+	}
+}
+
+func TestRawBody(t *testing.T) {
+	body := "--m\r\nContent-Type: text/html\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n<p>Caf=C3=A9</p>\r\n--m--\r\nEpilogue\r\n"
+	mailData := "From: jdoe@machine.example\r\nContent-Type: multipart/related; boundary=m\r\n\r\n" + body
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.RawBody != nil {
+		t.Errorf("Expected no raw body by default, got: %q", e.RawBody)
+	}
+
+	e, err = ParseWithOptions(strings.NewReader(mailData), WithRawBody())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(e.RawBody) != body {
+		t.Errorf("Wrong raw body. Expected: %q, Got: %q", body, e.RawBody)
+	}
+
+	if e.HTMLBody != "<p>Café</p>" {
+		t.Errorf("Wrong html body. Expected: %q, Got: %q", "<p>Café</p>", e.HTMLBody)
+	}
+}
+
+func TestParser(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\n\nbody\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"b.txt\"\nContent-Transfer-Encoding: base64\n\nd29ybGQh\n--m--\n"
+
+	var testData = map[int]struct {
+		parser      *Parser
+		attachments int
+		limited     bool
+	}{
+		1: {parser: NewParser(), attachments: 2},
+		2: {parser: NewParser(WithMaxAttachments(2)), attachments: 2},
+		3: {parser: NewParser(WithMaxAttachments(1)), limited: true},
+	}
+
+	for index, td := range testData {
+		errs := make(chan error, 4)
+		for i := 0; i < cap(errs); i++ {
+			go func() {
+				e, err := td.parser.Parse(strings.NewReader(mailData))
+				if err == nil && len(e.Attachments) != td.attachments {
+					err = fmt.Errorf("Wrong number of attachments. Expected: %v, Got: %v", td.attachments, len(e.Attachments))
+				}
+				errs <- err
+			}()
+		}
+
+		for i := 0; i < cap(errs); i++ {
+			err := <-errs
+			if _, limited := err.(*LimitError); limited != td.limited || (err != nil && !limited) {
+				t.Errorf("[Test Case %v] Unexpected result: %v", index, err)
+			}
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package parsemail
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -10,6 +12,7 @@ import (
 	"mime/multipart"
 	"mime/quotedprintable"
 	"net/mail"
+	"net/textproto"
 	"strings"
 	"time"
 )
@@ -20,6 +23,7 @@ const (
 	contentTypeMultipartRelated     = "multipart/related"
 	contentTypeTextHtml             = "text/html"
 	contentTypeTextPlain            = "text/plain"
+	contentTypeTextWatchHTML        = "text/watch-html"
 
 	encoding7bit            = "7bit"
 	encoding8Bit            = "8bit"
@@ -30,85 +34,330 @@ const (
 
 	headerContentType     = "Content-Type"
 	headerContentEncoding = "Content-Transfer-Encoding"
+	headerContentLanguage = "Content-Language"
+	headerCompression     = "Content-Encoding"
 )
 
-func decodeBodyPart(part io.Reader, encoding string) (string, error) {
-	switch encoding {
+func decodeBodyPart(e *Email, part io.Reader, encoding string, compression string) (string, error) {
+	var decoder io.Reader
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
 	case encodingBase64:
-		pbytes, err := ioutil.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
-		return string(pbytes), err
+		decoder = base64.NewDecoder(base64.StdEncoding, part)
 	case encodingQuotedPrintable:
-		d, err := ioutil.ReadAll(quotedprintable.NewReader(part))
-		return string(d), err
+		decoder = quotedprintable.NewReader(part)
 	case encoding7bit, encoding8Bit, encodingBinary, encodingEmpty:
-		pbytes, err := ioutil.ReadAll(part)
-		return string(pbytes), err
+		decoder = part
 	default:
 		return "", fmt.Errorf("Unrecognized content encoding")
 	}
+
+	decoder, err := e.registry.decompressPart(decoder, compression)
+	if err != nil {
+		return "", err
+	}
+
+	pbytes, err := ioutil.ReadAll(limitBody(e, decoder))
+	return string(pbytes), err
+}
+
+func addToTextBody(e *Email, decoded string, h textproto.MIMEHeader, o *options) {
+	e.TextBodyParts = append(e.TextBodyParts, trimBodyPart(decoded, o))
+	recordTextBodyPart(e, int64(len(decoded)), h)
+}
+
+func addToHTMLBody(e *Email, decoded string, h textproto.MIMEHeader, o *options) {
+	e.HTMLBodyParts = append(e.HTMLBodyParts, trimBodyPart(decoded, o))
+	recordHTMLBodyPart(e, int64(len(decoded)), h)
+}
+
+// recordTextBodyPart records the size, origin, languages and offsets of a text body part
+func recordTextBodyPart(e *Email, size int64, h textproto.MIMEHeader) {
+	e.Accounting.TextBodyBytes = append(e.Accounting.TextBodyBytes, size)
+	e.TextBodyPartOrigins = append(e.TextBodyPartOrigins, BodyPartOrigin{})
+	e.TextBodyPartLanguages = append(e.TextBodyPartLanguages, parseLanguageList(h.Get(headerContentLanguage)))
+	if e.locator != nil {
+		e.TextBodyPartOffsets = append(e.TextBodyPartOffsets, e.locator.end())
+	}
+}
+
+// recordHTMLBodyPart is recordTextBodyPart for HTML body parts, the first one also sets HTMLBodyBase
+func recordHTMLBodyPart(e *Email, size int64, h textproto.MIMEHeader) {
+	e.Accounting.HTMLBodyBytes = append(e.Accounting.HTMLBodyBytes, size)
+	e.HTMLBodyPartOrigins = append(e.HTMLBodyPartOrigins, BodyPartOrigin{})
+	e.HTMLBodyPartLanguages = append(e.HTMLBodyPartLanguages, parseLanguageList(h.Get(headerContentLanguage)))
+	if len(e.HTMLBodyPartOrigins) == 1 {
+		e.HTMLBodyBase = contentBase(h)
+	}
+	if e.locator != nil {
+		e.HTMLBodyPartOffsets = append(e.HTMLBodyPartOffsets, e.locator.end())
+	}
+}
+
+// addTextBodyPart decodes the text body part r with the header h and adds it
+// to the text body, or to the body data read by TextBodyReader when parsed
+// WithBodyReaders
+func addTextBodyPart(e *Email, r io.Reader, h textproto.MIMEHeader, o *options) error {
+	if o.bodyReaders {
+		return addBodyData(e, r, h, false, o)
+	}
+
+	decoded, err := decodeBodyPart(e, r, h.Get(headerContentEncoding), h.Get(headerCompression))
+	if err != nil {
+		return err
+	}
+
+	addToTextBody(e, decoded, h, o)
+	return nil
 }
 
-func addToTextBody(e *Email, decoded string) {
-	trimmed := strings.TrimSuffix(string(decoded[:]), "\n")
-	e.TextBody += trimmed
-	e.TextBodyParts = append(e.TextBodyParts, trimmed)
+// addHTMLBodyPart is addTextBodyPart for HTML body parts declared in charset
+func addHTMLBodyPart(e *Email, r io.Reader, h textproto.MIMEHeader, charset string, o *options) error {
+	if o.bodyReaders {
+		return addBodyData(e, r, h, true, o)
+	}
+
+	decoded, err := decodeBodyPart(e, r, h.Get(headerContentEncoding), h.Get(headerCompression))
+	if err != nil {
+		return err
+	}
+
+	addToHTMLBody(e, reconcileHTMLCharset(e, decoded, charset, o), h, o)
+	return nil
+}
+
+func trimBodyPart(decoded string, o *options) string {
+	switch o.bodyTrimming {
+	case TrimNone:
+		return decoded
+	case TrimTrailingSpace:
+		return strings.TrimRight(decoded, " \t\r\n")
+	}
+
+	if o.compat >= CompatV4 && strings.HasSuffix(decoded, "\r\n") {
+		return strings.TrimSuffix(decoded, "\r\n")
+	}
+
+	return strings.TrimSuffix(decoded, "\n")
 }
 
-func addToHTMLBody(e *Email, decoded string) {
-	trimmed := strings.TrimSuffix(string(decoded[:]), "\n")
-	e.HTMLBody += trimmed
-	e.HTMLBodyParts = append(e.HTMLBodyParts, trimmed)
+// joinBodies sets TextBody and HTMLBody to their joined parts. Parts are
+// collected while parsing and joined once, as appending every part to the
+// body string is quadratic in the number of parts.
+func joinBodies(e *Email, o *options) {
+	if o.bodyPartsOnly {
+		return
+	}
+
+	e.TextBody = strings.Join(e.TextBodyParts, o.bodySeparator)
+	e.HTMLBody = strings.Join(e.HTMLBodyParts, o.bodySeparator)
 }
 
 // Parse an email message read from io.Reader into parsemail.Email struct
 func Parse(r io.Reader) (email Email, err error) {
-	msg, err := mail.ReadMessage(r)
+	return ParseWithOptions(r)
+}
+
+// ParseWithOptions parses an email message like Parse, with optional behavior configured by opts
+func ParseWithOptions(r io.Reader, opts ...Option) (email Email, err error) {
+	return parseWithOptions(context.Background(), r, newOptions(opts))
+}
+
+// Parser parses email messages with the optional behavior it was created
+// with. The options are applied once, so a Parser can be kept and shared by
+// concurrent goroutines, as long as the funcs and scanners it was configured
+// with are safe for concurrent use.
+type Parser struct {
+	o *options
+}
+
+// NewParser returns a Parser with the optional behavior configured by opts
+func NewParser(opts ...Option) *Parser {
+	return &Parser{o: newOptions(opts)}
+}
+
+// Parse parses an email message like ParseWithOptions with the options of the Parser
+func (p *Parser) Parse(r io.Reader) (email Email, err error) {
+	return parseWithOptions(context.Background(), r, p.o)
+}
+
+func parseWithOptions(ctx context.Context, r io.Reader, o *options) (email Email, err error) {
+	return parseMessage(ctx, r, o, &parseBudget{o: o}, 0)
+}
+
+// parseNestedMessage parses a message attached to or returned in e, within the
+// budget of e. The message starts at origin in the source, a negative origin
+// means its offsets are unknown.
+func parseNestedMessage(e *Email, r io.Reader, origin int64, o *options) (Email, error) {
+	if err := e.budget.enter(); err != nil {
+		return Email{}, err
+	}
+	defer e.budget.leave()
+
+	return parseMessage(e.ctx, r, o, e.budget, origin)
+}
+
+func parseMessage(ctx context.Context, r io.Reader, o *options, budget *parseBudget, origin int64) (email Email, err error) {
+	br := bufio.NewReader(withContext(ctx, r))
+	rawHeader, err := readRawHeader(br)
+	if err != nil {
+		return
+	}
+
+	msg, err := mail.ReadMessage(io.MultiReader(bytes.NewReader(rawHeader), br))
 	if err != nil {
 		return
 	}
 
-	email, err = createEmailFromHeader(msg.Header)
+	email, err = createEmailFromHeader(msg.Header, parseHeaderFields(rawHeader), o)
 	if err != nil {
 		return
 	}
 
+	email.ctx = ctx
+	email.budget = budget
+	defer func() {
+		// reads fail with the context error wrapped by the multipart reader
+		if err != nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		email.ctx = nil
+		email.budget = nil
+	}()
+
+	email.RequiresSMTPUTF8 = requiresSMTPUTF8(rawHeader)
+	email.bodySeparator = o.bodySeparator
+	email.RawHeader = rawHeader
+	email.registry = &o.registry
+
+	if o.strictMIMEVersion {
+		if err = checkMIMEVersion(msg.Header, email.MIMEVersion); err != nil {
+			return
+		}
+	}
+
 	contentType, params, err := parseContentType(msg.Header.Get(headerContentType))
 	if err != nil {
 		return
 	}
 
+	var rawBody *bytes.Buffer
+	if o.keepRawBody || o.partOffsets {
+		rawBody = &bytes.Buffer{}
+		msg.Body = io.TeeReader(msg.Body, rawBody)
+	}
+
+	if o.partOffsets && origin >= 0 {
+		email.locator = &partLocator{raw: rawBody, origin: origin, base: int64(len(rawHeader))}
+	}
+
 	switch contentType {
 	case contentTypeMultipartMixed:
-		err = parseMultipartMixed(&email, msg.Body, params["boundary"])
+		err = parseMultipartMixed(&email, msg.Body, params["boundary"], o)
 	case contentTypeMultipartRelated:
-		err = parseMultipartRelated(&email, msg.Body, params["boundary"])
+		err = parseMultipartRelated(&email, msg.Body, params["boundary"], o)
 	case contentTypeMultipartAlternative:
-		err = parseMultipartAlternative(&email, msg.Body, params["boundary"])
+		err = parseMultipartAlternative(&email, msg.Body, params["boundary"], o)
+	case contentTypeMultipartReport:
+		err = parseMultipartReport(&email, msg.Body, params["boundary"], o)
 	case contentTypeTextPlain:
-		message, decodeErr := decodeBodyPart(msg.Body, msg.Header.Get(headerContentEncoding))
-		if err != nil {
+		if o.bodyReaders {
+			err = addBodyData(&email, msg.Body, textproto.MIMEHeader(msg.Header), false, o)
+			break
+		}
+
+		message, decodeErr := decodeBodyPart(&email, msg.Body, msg.Header.Get(headerContentEncoding), msg.Header.Get(headerCompression))
+		if decodeErr != nil && (o.compat >= CompatV2 || isLimitError(decodeErr)) {
 			err = decodeErr
 			return
 		}
-		addToTextBody(&email, message)
+		addToTextBody(&email, message, textproto.MIMEHeader(msg.Header), o)
 	case contentTypeTextHtml:
-		message, decodeErr := decodeBodyPart(msg.Body, msg.Header.Get(headerContentEncoding))
-		if err != nil {
+		if o.bodyReaders {
+			err = addBodyData(&email, msg.Body, textproto.MIMEHeader(msg.Header), true, o)
+			break
+		}
+
+		message, decodeErr := decodeBodyPart(&email, msg.Body, msg.Header.Get(headerContentEncoding), msg.Header.Get(headerCompression))
+		if decodeErr != nil && (o.compat >= CompatV2 || isLimitError(decodeErr)) {
 			err = decodeErr
 			return
 		}
-		addToHTMLBody(&email, message)
+		addToHTMLBody(&email, reconcileHTMLCharset(&email, message, params["charset"], o), textproto.MIMEHeader(msg.Header), o)
+	case contentTypeTextCalendar:
+		err = parseCalendarPart(&email, msg.Body, params, msg.Header.Get(headerContentEncoding), msg.Header.Get(headerCompression))
+	case contentTypeOctetStream:
+		if !o.octetStreamHeuristics {
+			err = fmt.Errorf("Unknown top level mime type: %s", contentType)
+			return
+		}
+
+		err = recoverOctetStreamBody(&email, msg.Body, textproto.MIMEHeader(msg.Header), o)
 	default:
 		err = fmt.Errorf("Unknown top level mime type: %s", contentType)
 	}
 
+	email.locator = nil
+
+	if o.keepRawBody {
+		// the parsers stop at the closing boundary, the epilogue is part of the raw body too
+		if _, copyErr := io.Copy(ioutil.Discard, msg.Body); copyErr != nil && err == nil {
+			err = copyErr
+		}
+		email.RawBody = rawBody.Bytes()
+	}
+
+	if err != nil {
+		email.Close()
+	}
+
+	joinBodies(&email, o)
+	finishAccounting(&email)
+
+	if err == nil {
+		extractAttachmentText(&email, o)
+	}
+
+	if err == nil && o.listZipEntries {
+		listZipEntries(&email)
+	}
+
+	if err == nil && o.extractDataURIs && email.HTMLBody != "" {
+		var files []EmbeddedFile
+		email.HTMLBody, files = ExtractDataURIs(email.HTMLBody, o.dataURIMinSize)
+		email.EmbeddedFiles = append(email.EmbeddedFiles, files...)
+	}
+
+	if err == nil && o.sanitizeHTML && email.HTMLBody != "" {
+		email.HTMLBody = SanitizeHTML(email.HTMLBody)
+	}
+
+	if err == nil && o.htmlToTextFallback && email.TextBody == "" && email.HTMLBody != "" {
+		email.TextBody = HTMLToText(email.HTMLBody)
+	}
+
+	if err == nil && (o.detectSignature || o.stripSignature) && email.TextBody != "" {
+		var body string
+		body, email.Signature = SplitSignature(email.TextBody)
+		if o.stripSignature {
+			email.TextBody = body
+		}
+	}
+
+	if err == nil && (o.textAsHTML || o.textToHTMLFallback) && email.HTMLBody == "" && email.TextBody != "" {
+		email.TextAsHTML = textToHTML(email.TextBody)
+		if o.textToHTMLFallback {
+			email.HTMLBody = email.TextAsHTML
+		}
+	}
+
 	return
 }
 
-func createEmailFromHeader(header mail.Header) (email Email, err error) {
-	hp := headerParser{header: &header}
+func createEmailFromHeader(header mail.Header, fields []headerField, o *options) (email Email, err error) {
+	hp := headerParser{header: &header, lenient: o.lenientAddresses, warnings: &email.Warnings, dateParser: o.dateParser, compat: o.compat, registry: &o.registry}
 
 	email.Subject = decodeMimeSentence(header.Get("Subject"))
+	email.MIMEVersion = parseMIMEVersion(header.Get("MIME-Version"))
 	email.From = hp.parseAddressList(header.Get("From"))
 	email.Sender = hp.parseAddress(header.Get("Sender"))
 	email.ReplyTo = hp.parseAddressList(header.Get("Reply-To"))
@@ -116,22 +365,34 @@ func createEmailFromHeader(header mail.Header) (email Email, err error) {
 	email.Cc = hp.parseAddressList(header.Get("Cc"))
 	email.Bcc = hp.parseAddressList(header.Get("Bcc"))
 	email.Date = hp.parseTime(header.Get("Date"))
-	email.ResentFrom = hp.parseAddressList(header.Get("Resent-From"))
-	email.ResentSender = hp.parseAddress(header.Get("Resent-Sender"))
-	email.ResentTo = hp.parseAddressList(header.Get("Resent-To"))
-	email.ResentCc = hp.parseAddressList(header.Get("Resent-Cc"))
-	email.ResentBcc = hp.parseAddressList(header.Get("Resent-Bcc"))
-	email.ResentMessageID = hp.parseMessageId(header.Get("Resent-Message-ID"))
+	email.ResentBlocks = hp.parseResentBlocks(fields)
 	email.MessageID = hp.parseMessageId(header.Get("Message-ID"))
 	email.InReplyTo = hp.parseMessageIdList(header.Get("In-Reply-To"))
 	email.References = hp.parseMessageIdList(header.Get("References"))
-	email.ResentDate = hp.parseTime(header.Get("Resent-Date"))
+	email.Priority = hp.parsePriority()
+	email.OriginalRecipient = parseTypedAddress(header.Get("Original-Recipient"), true)
+	email.Keywords = hp.parseKeywords(header["Keywords"])
+	email.Comments = hp.parseUnstructuredList(header["Comments"])
+	email.Organization = decodeMimeSentence(header.Get("Organization"))
+	email.ContentLanguage = parseLanguageList(header.Get(headerContentLanguage))
+	email.AcceptLanguage = parseLanguageList(header.Get("Accept-Language"))
+	email.ClientInfo = hp.parseClientInfo()
+	email.Spam = hp.parseSpamInfo()
+	email.ThreadTopic = decodeMimeSentence(header.Get("Thread-Topic"))
+	email.ThreadIndex = parseThreadIndex(header.Get("Thread-Index"))
+	email.BIMI = hp.parseBIMI()
+	email.Campaign = hp.parseCampaign()
 
 	if hp.err != nil {
 		err = hp.err
 		return
 	}
 
+	email.Extensions, err = hp.parseExtensions()
+	if err != nil {
+		return
+	}
+
 	//decode whole header for easier access to extra fields
 	//todo: should we decode? aren't only standard fields mime encoded?
 	email.Header, err = decodeHeaderMime(header)
@@ -139,9 +400,47 @@ func createEmailFromHeader(header mail.Header) (email Email, err error) {
 		return
 	}
 
+	detectBcc(&email)
+	detectAutoReply(&email)
+
 	return
 }
 
+// parseMIMEVersion returns the declared MIME version without comments and whitespace
+func parseMIMEVersion(s string) string {
+	var sb strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0 && r != ' ' && r != '\t':
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+// checkMIMEVersion fails for messages with MIME structure and a missing or malformed MIME-Version
+func checkMIMEVersion(header mail.Header, version string) error {
+	if header.Get(headerContentType) == "" && header.Get(headerContentEncoding) == "" {
+		return nil
+	}
+
+	if _, ok := header["Mime-Version"]; !ok {
+		return fmt.Errorf("Missing MIME-Version header")
+	}
+
+	if version != "1.0" {
+		return fmt.Errorf("Malformed MIME-Version: %s", header.Get("MIME-Version"))
+	}
+
+	return nil
+}
+
 func parseContentType(contentTypeHeader string) (contentType string, params map[string]string, err error) {
 	if contentTypeHeader == "" {
 		contentType = contentTypeTextPlain
@@ -151,16 +450,29 @@ func parseContentType(contentTypeHeader string) (contentType string, params map[
 	return mime.ParseMediaType(contentTypeHeader)
 }
 
-func parseMultipartRelated(e *Email, msg io.Reader, boundary string) error {
+func parseMultipartRelated(e *Email, msg io.Reader, boundary string, o *options) error {
+	if err := e.budget.enter(); err != nil {
+		return err
+	}
+	defer e.budget.leave()
+
+	e.locator.enter(boundary)
+	defer e.locator.leave()
+
 	pmr := multipart.NewReader(msg, boundary)
 	for {
-		part, err := pmr.NextPart()
+		part, err := pmr.NextRawPart()
 
 		if err == io.EOF {
 			break
 		} else if err != nil {
 			return err
 		}
+		e.locator.next()
+
+		if err := e.contextErr(); err != nil {
+			return err
+		}
 
 		contentType, params, err := mime.ParseMediaType(part.Header.Get(headerContentType))
 		if err != nil {
@@ -169,31 +481,29 @@ func parseMultipartRelated(e *Email, msg io.Reader, boundary string) error {
 
 		switch contentType {
 		case contentTypeTextPlain:
-			ppContent, err := decodeBodyPart(part, part.Header.Get(headerContentEncoding))
-			if err != nil {
+			if err := addTextBodyPart(e, part, part.Header, o); err != nil {
 				return err
 			}
-
-			addToTextBody(e, ppContent)
 		case contentTypeTextHtml:
-			ppContent, err := decodeBodyPart(part, part.Header.Get(headerContentEncoding))
-			if err != nil {
+			if err := addHTMLBodyPart(e, part, part.Header, params["charset"], o); err != nil {
 				return err
 			}
-
-			addToHTMLBody(e, ppContent)
 		case contentTypeMultipartAlternative:
-			if err := parseMultipartAlternative(e, part, params["boundary"]); err != nil {
+			if err := parseMultipartAlternative(e, part, params["boundary"], o); err != nil {
 				return err
 			}
 		default:
-			if isEmbeddedFile(part) {
-				ef, err := decodeEmbeddedFile(part)
+			if isEmbeddedFile(part, o) {
+				ef, err := decodeEmbeddedFile(e, part, o)
 				if err != nil {
 					return err
 				}
 
 				e.EmbeddedFiles = append(e.EmbeddedFiles, ef)
+			} else if isAttachment(part) {
+				if err := addAttachment(e, part, contentType, params, o); err != nil {
+					return err
+				}
 			} else {
 				return fmt.Errorf("Can't process multipart/related inner mime type: %s", contentType)
 			}
@@ -203,88 +513,157 @@ func parseMultipartRelated(e *Email, msg io.Reader, boundary string) error {
 	return nil
 }
 
-func parseMultipartAlternative(e *Email, msg io.Reader, boundary string) error {
+func parseMultipartAlternative(e *Email, msg io.Reader, boundary string, o *options) error {
+	// only the alternatives of the outermost group are recorded
+	record := e.Alternatives == nil
+	if record {
+		e.Alternatives = []Alternative{}
+	}
+
+	e.alternativeGroups++
+	group := e.alternativeGroups
+
+	if err := e.budget.enter(); err != nil {
+		return err
+	}
+	defer e.budget.leave()
+
+	e.locator.enter(boundary)
+	defer e.locator.leave()
+
 	pmr := multipart.NewReader(msg, boundary)
-	for {
-		part, err := pmr.NextPart()
+	for position := 0; ; position++ {
+		part, err := pmr.NextRawPart()
 
 		if err == io.EOF {
 			break
 		} else if err != nil {
 			return err
 		}
+		e.locator.next()
+
+		if err := e.contextErr(); err != nil {
+			return err
+		}
 
 		contentType, params, err := mime.ParseMediaType(part.Header.Get(headerContentType))
 		if err != nil {
 			return err
 		}
 
+		textStart, htmlStart := len(e.TextBodyPartOrigins), len(e.HTMLBodyPartOrigins)
 		switch contentType {
 		case contentTypeTextPlain:
-			ppContent, err := decodeBodyPart(part, part.Header.Get(headerContentEncoding))
-			if err != nil {
+			if err := addTextBodyPart(e, part, part.Header, o); err != nil {
 				return err
 			}
-
-			addToTextBody(e, ppContent)
 		case contentTypeTextHtml:
-			ppContent, err := decodeBodyPart(part, part.Header.Get(headerContentEncoding))
-			if err != nil {
+			if err := addHTMLBodyPart(e, part, part.Header, params["charset"], o); err != nil {
 				return err
 			}
-
-			addToHTMLBody(e, ppContent)
 		case contentTypeMultipartRelated:
-			if err := parseMultipartRelated(e, part, params["boundary"]); err != nil {
+			if err := parseMultipartRelated(e, part, params["boundary"], o); err != nil {
+				return err
+			}
+		case contentTypeTextCalendar:
+			if err := parseCalendarPart(e, part, params, part.Header.Get(headerContentEncoding), part.Header.Get(headerCompression)); err != nil {
 				return err
 			}
+		case contentTypeTextWatchHTML:
+			ppContent, err := decodeBodyPart(e, part, part.Header.Get(headerContentEncoding), part.Header.Get(headerCompression))
+			if err != nil {
+				return err
+			}
+
+			e.WatchHTMLBody = trimBodyPart(ppContent, o)
 		default:
-			if isEmbeddedFile(part) {
-				ef, err := decodeEmbeddedFile(part)
+			if isEmbeddedFile(part, o) {
+				ef, err := decodeEmbeddedFile(e, part, o)
 				if err != nil {
 					return err
 				}
 
 				e.EmbeddedFiles = append(e.EmbeddedFiles, ef)
+			} else if isAttachment(part) {
+				if err := addAttachment(e, part, contentType, params, o); err != nil {
+					return err
+				}
 			} else {
 				return fmt.Errorf("Can't process multipart/alternative inner mime type: %s", contentType)
 			}
 		}
+
+		setBodyPartOrigins(e.TextBodyPartOrigins[textStart:], group, position)
+		setBodyPartOrigins(e.HTMLBodyPartOrigins[htmlStart:], group, position)
+
+		if record {
+			alternative := Alternative{ContentType: contentType}
+			if !o.bodyReaders {
+				alternative.Text = strings.Join(e.TextBodyParts[textStart:], o.bodySeparator)
+				alternative.HTML = strings.Join(e.HTMLBodyParts[htmlStart:], o.bodySeparator)
+			}
+			e.Alternatives = append(e.Alternatives, alternative)
+		}
 	}
 
 	return nil
 }
 
-func parseMultipartMixed(e *Email, msg io.Reader, boundary string) error {
+func parseMultipartMixed(e *Email, msg io.Reader, boundary string, o *options) error {
+	if err := e.budget.enter(); err != nil {
+		return err
+	}
+	defer e.budget.leave()
+
+	e.locator.enter(boundary)
+	defer e.locator.leave()
+
 	mr := multipart.NewReader(msg, boundary)
 	for {
-		part, err := mr.NextPart()
+		part, err := mr.NextRawPart()
 		if err == io.EOF {
 			break
 		} else if err != nil {
 			return err
 		}
+		e.locator.next()
+
+		if err := e.contextErr(); err != nil {
+			return err
+		}
 
 		contentType, params, err := mime.ParseMediaType(part.Header.Get(headerContentType))
 		if err != nil {
 			return err
 		}
 
-		if contentType == contentTypeMultipartAlternative {
-			if err = parseMultipartAlternative(e, part, params["boundary"]); err != nil {
+		if strings.HasPrefix(contentType, "multipart/") {
+			if err = parseNestedMultipart(e, part, contentType, params["boundary"], o); err != nil {
+				return err
+			}
+		} else if isAttachment(part) || isMessage(contentType) {
+			if err = addAttachment(e, part, contentType, params, o); err != nil {
+				return err
+			}
+		} else if contentType == contentTypeTextPlain {
+			if err := addTextBodyPart(e, part, part.Header, o); err != nil {
+				return err
+			}
+		} else if contentType == contentTypeTextHtml {
+			if err := addHTMLBodyPart(e, part, part.Header, params["charset"], o); err != nil {
 				return err
 			}
-		} else if contentType == contentTypeMultipartRelated {
-			if err = parseMultipartRelated(e, part, params["boundary"]); err != nil {
+		} else if contentType == contentTypeTextCalendar {
+			if err = parseCalendarPart(e, part, params, part.Header.Get(headerContentEncoding), part.Header.Get(headerCompression)); err != nil {
 				return err
 			}
-		} else if isAttachment(part) {
-			at, err := decodeAttachment(part)
+		} else if isEmbeddedFile(part, o) {
+			ef, err := decodeEmbeddedFile(e, part, o)
 			if err != nil {
 				return err
 			}
 
-			e.Attachments = append(e.Attachments, at)
+			e.EmbeddedFiles = append(e.EmbeddedFiles, ef)
 		} else {
 			return fmt.Errorf("Unknown multipart/mixed nested mime type: %s", contentType)
 		}
@@ -293,6 +672,63 @@ func parseMultipartMixed(e *Email, msg io.Reader, boundary string) error {
 	return nil
 }
 
+// parseNestedMultipart parses a multipart entity nested in multipart/mixed by
+// its subtype. Unknown subtypes, like multipart/signed or another
+// multipart/mixed, are parsed as multipart/mixed (RFC 2046 section 5.1.7).
+func parseNestedMultipart(e *Email, msg io.Reader, contentType string, boundary string, o *options) error {
+	switch contentType {
+	case contentTypeMultipartAlternative:
+		return parseMultipartAlternative(e, msg, boundary, o)
+	case contentTypeMultipartRelated:
+		return parseMultipartRelated(e, msg, boundary, o)
+	case contentTypeMultipartReport:
+		return parseMultipartReport(e, msg, boundary, o)
+	}
+
+	return parseMultipartMixed(e, msg, boundary, o)
+}
+
+// addAttachment decodes part into an attachment of e, unless the attachment
+// filter skips it. Calendar replies are also parsed into e.CalendarReply and
+// attached messages into the Message of the attachment.
+func addAttachment(e *Email, part *multipart.Part, contentType string, params map[string]string, o *options) error {
+	decision := StreamAttachment
+	if o.attachmentFilter != nil {
+		decision = o.attachmentFilter(part.Header)
+	}
+
+	if decision == SkipAttachment {
+		return nil
+	}
+
+	if err := e.budget.addAttachment(); err != nil {
+		return err
+	}
+
+	at, err := decodeAttachment(e, part, decision == StreamAttachment && o.attachmentStream != nil, o)
+	if err != nil {
+		return err
+	}
+
+	if sra, ok := at.Data.(sizedReaderAt); ok && contentType == contentTypeTextCalendar {
+		data, _ := ioutil.ReadAll(io.NewSectionReader(sra, 0, sra.Size()))
+		if reply := parseCalendarReply(string(data), params["method"]); reply != nil {
+			e.CalendarReply = reply
+		}
+	}
+
+	if isMessage(contentType) {
+		if err := parseAttachedMessage(e, &at, o); err != nil {
+			// kept so closing the failed email releases its data
+			e.Attachments = append(e.Attachments, at)
+			return err
+		}
+	}
+
+	e.Attachments = append(e.Attachments, at)
+	return nil
+}
+
 func decodeMimeSentence(s string) string {
 	result := []string{}
 	ss := strings.Split(s, " ")
@@ -330,62 +766,218 @@ func decodeHeaderMime(header mail.Header) (mail.Header, error) {
 	return mail.Header(parsedHeader), nil
 }
 
-func decodePartData(part *multipart.Part) (io.Reader, error) {
-	encoding := part.Header.Get(headerContentEncoding)
+// partDataReader returns a reader decoding r by the transfer encoding and compression of the part header h
+func partDataReader(r io.Reader, h textproto.MIMEHeader, o *options) (io.Reader, error) {
+	encoding := h.Get(headerContentEncoding)
 
-	if strings.EqualFold(encoding, "base64") {
-		dr := base64.NewDecoder(base64.StdEncoding, part)
-		dd, err := ioutil.ReadAll(dr)
-		if err != nil {
-			return nil, err
-		}
+	var decoder io.Reader
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case encodingBase64:
+		decoder = base64.NewDecoder(base64.StdEncoding, r)
+	case encodingQuotedPrintable:
+		decoder = quotedprintable.NewReader(r)
+	case encoding7bit, encoding8Bit, encodingBinary, encodingEmpty:
+		decoder = r
+	default:
+		return nil, fmt.Errorf("Unknown encoding: %s", encoding)
+	}
+
+	return o.registry.decompressPart(decoder, h.Get(headerCompression))
+}
+
+// readPartData decodes the part data r with the header h, also writing the
+// decoded data to w when it isn't nil. The data is returned as a sizedReaderAt held in memory or, above the
+// spooling threshold, in a temporary file.
+func readPartData(r io.Reader, h textproto.MIMEHeader, w io.Writer, o *options) (io.Reader, error) {
+	dr, err := partDataReader(r, h, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if w != nil {
+		dr = io.TeeReader(dr, w)
+	}
 
-		return bytes.NewReader(dd), nil
+	if o.spool {
+		return spoolData(dr, o.spoolThreshold, o.spoolDir)
 	}
 
-	return nil, fmt.Errorf("Unknown encoding: %s", encoding)
+	data, err := ioutil.ReadAll(dr)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(data), nil
 }
 
-func isEmbeddedFile(part *multipart.Part) bool {
-	return strings.Contains(part.Header.Get("Content-Disposition"), "attachment") ||
-		strings.HasPrefix(part.Header.Get("Content-Type"), "image/")
+// isEmbeddedFile reports whether part is a resource shown along with the body:
+// a part referenced by its content id or location or an image, unless it is
+// disposed as an attachment
+func isEmbeddedFile(part *multipart.Part, o *options) bool {
+	if o.compat < CompatV5 {
+		return strings.Contains(part.Header.Get(headerContentDisposition), "attachment") ||
+			strings.HasPrefix(part.Header.Get(headerContentType), "image/")
+	}
+
+	if parseContentDisposition(part.Header.Get(headerContentDisposition), &o.registry).Type == "attachment" {
+		return false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(part.Header.Get(headerContentType))
+	return partContentID(part) != "" || partContentLocation(part) != "" || strings.HasPrefix(mediaType, "image/")
 }
 
-func decodeEmbeddedFile(part *multipart.Part) (ef EmbeddedFile, err error) {
-	cid := decodeMimeSentence(part.Header.Get("Content-Id"))
-	decoded, err := decodePartData(part)
+// decodeEmbeddedFile decodes part into an embedded file of e. Embedded files
+// count as attachments against the limits of the parse.
+func decodeEmbeddedFile(e *Email, part *multipart.Part, o *options) (ef EmbeddedFile, err error) {
+	if err = e.budget.addAttachment(); err != nil {
+		return
+	}
+
+	src, raw := rawPartSource(part, o)
+	stats := &dataStats{}
+	var w io.Writer = stats
+	limiter := newPartLimiter(e, true)
+	if limiter != nil {
+		w = io.MultiWriter(limiter, stats)
+	}
+
+	data, err := readPartData(src, part.Header, w, o)
 	if err != nil {
 		return
 	}
 
-	ef.CID = strings.Trim(cid, "<>")
-	ef.Data = decoded
+	ef.Header = part.Header
+	ef.RawData = raw.bytes()
+	ef.Offsets = e.locator.end()
+	ef.CID = partContentID(part)
+	ef.ContentLocation = partContentLocation(part)
+	ef.Filename = decodeMimeSentence(partFilename(part))
+	ef.Data = data
+	ef.Size = stats.n
+	ef.SHA256 = stats.sha256()
 	ef.ContentType = part.Header.Get(headerContentType)
+	ef.ContentLanguage = parseLanguageList(part.Header.Get(headerContentLanguage))
+	ef.Description = decodeMimeSentence(part.Header.Get(headerContentDescription))
+	ef.Disposition = parseContentDisposition(part.Header.Get(headerContentDisposition), &o.registry)
 
 	return
 }
 
+// isAttachment reports whether part is disposed as an attachment or has a filename
 func isAttachment(part *multipart.Part) bool {
-	return part.FileName() != ""
+	return parseContentDisposition(part.Header.Get(headerContentDisposition), nil).Type == "attachment" || partFilename(part) != ""
 }
 
-func decodeAttachment(part *multipart.Part) (at Attachment, err error) {
-	filename := decodeMimeSentence(part.FileName())
-	decoded, err := decodePartData(part)
-	if err != nil {
+func decodeAttachment(e *Email, part *multipart.Part, stream bool, o *options) (at Attachment, err error) {
+	at.Filename = decodeMimeSentence(partFilename(part))
+	at.ContentID = partContentID(part)
+	at.ContentType = strings.Split(part.Header.Get(headerContentType), ";")[0]
+	at.ContentLanguage = parseLanguageList(part.Header.Get(headerContentLanguage))
+	at.Description = decodeMimeSentence(part.Header.Get(headerContentDescription))
+	at.Disposition = parseContentDisposition(part.Header.Get(headerContentDisposition), &o.registry)
+	at.Header = part.Header
+
+	src, raw := rawPartSource(part, o)
+	defer func() {
+		at.RawData = raw.bytes()
+	}()
+
+	stats := &dataStats{}
+	var w io.Writer = stats
+	if scans := startScans(o.scanners, at); scans != nil {
+		w = io.MultiWriter(stats, scans)
+		defer func() {
+			scans.finish(e, &at, err)
+		}()
+	}
+
+	limiter := newPartLimiter(e, true)
+	if limiter != nil {
+		w = io.MultiWriter(limiter, w)
+	}
+
+	if stream {
+		if err = streamAttachment(src, part.Header, at, w, o); err != nil {
+			return
+		}
+		at.Data = streamedData{}
+	} else if at.Data, err = readPartData(src, part.Header, w, o); err != nil {
 		return
 	}
 
-	at.Filename = filename
-	at.Data = decoded
-	at.ContentType = strings.Split(part.Header.Get(headerContentType), ";")[0]
+	if limiter != nil && limiter.err != nil {
+		// the error may have been swallowed by the stream func
+		err = limiter.err
+		return
+	}
+
+	at.Offsets = e.locator.end()
+
+	setAttachmentMetadata(&at, stats, o)
 
 	return
 }
 
+// setAttachmentMetadata sets the attachment fields derived from the decoded data
+func setAttachmentMetadata(at *Attachment, stats *dataStats, o *options) {
+	at.Entropy = stats.entropy()
+	at.PackerSuspected = stats.packerSuspected()
+	at.Size = stats.n
+	at.SHA256 = stats.sha256()
+	if o.sniffAttachments && needsSniffing(at.ContentType) {
+		at.DetectedContentType = sniffContentType(stats.head)
+	}
+
+	at.SuggestedContentType, at.SuggestedFilename = o.registry.suggestAttachmentType(at.Filename, at.ContentType, at.DetectedContentType)
+}
+
+// parseLanguageList returns the language tags of a Content-Language or
+// Accept-Language value in order, without quality values
+func parseLanguageList(s string) (tags []string) {
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(strings.Split(t, ";")[0])
+		if t != "" {
+			tags = append(tags, t)
+		}
+	}
+
+	return
+}
+
+// parseTagList parses a "tag=value; tag=value" list as used by DKIM and BIMI headers.
+// Whitespace around tags and values is removed, tag names are case sensitive.
+func parseTagList(s string) map[string]string {
+	tags := map[string]string{}
+	for _, spec := range strings.Split(s, ";") {
+		kv := strings.SplitN(spec, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		if tag := strings.TrimSpace(kv[0]); tag != "" {
+			tags[tag] = strings.Join(strings.Fields(kv[1]), "")
+		}
+	}
+
+	return tags
+}
+
 type headerParser struct {
-	header *mail.Header
-	err    error
+	header   *mail.Header
+	err      error
+	lenient  bool
+	warnings *[]string
+
+	dateParser DateParserFunc
+	compat     CompatLevel
+	registry   *registry
+}
+
+func (hp headerParser) warn(format string, args ...interface{}) {
+	if hp.warnings != nil {
+		*hp.warnings = append(*hp.warnings, fmt.Sprintf(format, args...))
+	}
 }
 
 func (hp headerParser) parseAddress(s string) (ma *mail.Address) {
@@ -395,6 +987,19 @@ func (hp headerParser) parseAddress(s string) (ma *mail.Address) {
 
 	if strings.Trim(s, " \n") != "" {
 		ma, hp.err = mail.ParseAddress(s)
+		if hp.err != nil {
+			if obs, err := parseObsoleteAddress(s); err == nil {
+				hp.err = nil
+				return obs
+			}
+
+			if hp.lenient {
+				ma = salvageAddress(s)
+				hp.warn("Salvaged malformed address %q: %v", s, hp.err)
+			} else {
+				hp.warn("Dropped malformed address %q: %v", s, hp.err)
+			}
+		}
 
 		return ma
 	}
@@ -409,6 +1014,20 @@ func (hp headerParser) parseAddressList(s string) (ma []*mail.Address) {
 
 	if strings.Trim(s, " \n") != "" {
 		ma, hp.err = mail.ParseAddressList(s)
+		if hp.err != nil {
+			if obs, err := parseObsoleteAddressList(s); err == nil {
+				hp.err = nil
+				return obs
+			}
+
+			if hp.lenient {
+				ma = salvageAddressList(s)
+				hp.warn("Salvaged malformed address list %q: %v", s, hp.err)
+			} else {
+				hp.warn("Dropped malformed address list %q: %v", s, hp.err)
+			}
+		}
+
 		return
 	}
 
@@ -426,6 +1045,57 @@ func (hp headerParser) parseTime(s string) (t time.Time) {
 	}
 
 	t, hp.err = time.Parse("Mon, 2 Jan 2006 15:04:05 -0700", s)
+	if hp.err == nil {
+		return t
+	}
+
+	t, hp.err = parseObsoleteDate(s)
+	if hp.err == nil {
+		return t
+	}
+
+	if registered, ok := hp.registry.parseRegisteredDate(s); ok {
+		return registered
+	}
+
+	if hp.dateParser != nil {
+		custom, err := hp.dateParser(s)
+		if err == nil {
+			return custom
+		}
+	}
+
+	hp.warn("Dropped malformed date %q: %v", s, hp.err)
+
+	return
+}
+
+func (hp headerParser) parseKeywords(fields []string) (result []string) {
+	if hp.err != nil {
+		return
+	}
+
+	for _, f := range fields {
+		for _, k := range strings.Split(decodeMimeSentence(f), ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				result = append(result, k)
+			}
+		}
+	}
+
+	return
+}
+
+func (hp headerParser) parseUnstructuredList(fields []string) (result []string) {
+	if hp.err != nil {
+		return
+	}
+
+	for _, f := range fields {
+		if f = strings.TrimSpace(decodeMimeSentence(f)); f != "" {
+			result = append(result, f)
+		}
+	}
 
 	return
 }
@@ -435,6 +1105,14 @@ func (hp headerParser) parseMessageId(s string) string {
 		return ""
 	}
 
+	if hp.compat >= CompatV3 {
+		if ids := splitMessageIDs(s); len(ids) > 0 {
+			return ids[0]
+		}
+
+		return ""
+	}
+
 	return strings.Trim(s, "<> ")
 }
 
@@ -443,6 +1121,10 @@ func (hp headerParser) parseMessageIdList(s string) (result []string) {
 		return
 	}
 
+	if hp.compat >= CompatV3 {
+		return splitMessageIDs(s)
+	}
+
 	for _, p := range strings.Split(s, " ") {
 		if strings.Trim(p, " \n") != "" {
 			result = append(result, hp.parseMessageId(p))
@@ -453,23 +1135,93 @@ func (hp headerParser) parseMessageIdList(s string) (result []string) {
 }
 
 // Attachment with filename, content type and data (as a io.Reader)
+//
+// Entropy is the Shannon entropy of the decoded data in bits per byte and
+// PackerSuspected is set for executables that look packed or encrypted.
+// Both are cheap heuristics meant as pre-filters, not as a verdict.
 type Attachment struct {
-	Filename    string
-	ContentType string
-	Data        io.Reader
+	Filename        string
+	ContentID       string
+	ContentType     string
+	ContentLanguage []string
+	Description     string
+	Disposition     ContentDisposition
+	Data            io.Reader
+
+	// Header is the MIME header of the part and RawData its still transfer
+	// encoded content, when parsed WithRawParts
+	Header  textproto.MIMEHeader
+	RawData []byte
+
+	// Offsets locate the part in the source message, when parsed WithPartOffsets
+	Offsets PartOffsets
+
+	// Message is the attached message parsed, for message/rfc822 and
+	// message/global attachments buffered in memory or spooled
+	Message *Email
+
+	// Size is the decoded size in bytes and SHA256 the hex encoded SHA-256 digest of Data
+	Size   int64
+	SHA256 string
+
+	// DetectedContentType is the content type sniffed from the data of
+	// attachments without a meaningful one, see WithAttachmentSniffing
+	DetectedContentType string
+
+	// SuggestedContentType is the content type corrected by the filename
+	// extension when the declared one is obviously wrong, SuggestedFilename the
+	// filename with an extension inferred from the content type when it lacks
+	// one, see RegisterExtension
+	SuggestedContentType string
+	SuggestedFilename    string
+
+	// Verdicts are the results of the scanners set WithScanner
+	Verdicts []Verdict
+
+	// ZipEntries lists the files of zip attachments, see WithZipListing
+	ZipEntries []ZipEntry
+
+	Entropy         float64
+	PackerSuspected bool
+
+	// Text is the text extracted by the ContentExtractor registered for the content type
+	Text string
 }
 
-// EmbeddedFile with content id, content type and data (as a io.Reader)
+// EmbeddedFile with content id or location, content type and data (as a io.Reader)
 type EmbeddedFile struct {
-	CID         string
-	ContentType string
-	Data        io.Reader
+	CID             string
+	ContentLocation string
+	Filename        string
+	ContentType     string
+	ContentLanguage []string
+	Description     string
+	Disposition     ContentDisposition
+	Data            io.Reader
+
+	// Header is the MIME header of the part and RawData its still transfer
+	// encoded content, when parsed WithRawParts
+	Header  textproto.MIMEHeader
+	RawData []byte
+
+	// Offsets locate the part in the source message, when parsed WithPartOffsets
+	Offsets PartOffsets
+
+	// Size is the decoded size in bytes and SHA256 the hex encoded SHA-256 digest of Data
+	Size   int64
+	SHA256 string
 }
 
 // Email with fields for all the headers defined in RFC5322 with it's attachments and
 type Email struct {
 	Header mail.Header
 
+	MIMEVersion string
+
+	// RequiresSMTPUTF8 is set for messages with UTF-8 header fields (RFC 6532),
+	// like non-ASCII addresses, which can only be relayed with SMTPUTF8
+	RequiresSMTPUTF8 bool
+
 	Subject    string
 	Sender     *mail.Address
 	From       []*mail.Address
@@ -481,21 +1233,129 @@ type Email struct {
 	MessageID  string
 	InReplyTo  []string
 	References []string
+	Priority   Priority
+
+	// ThreadTopic and ThreadIndex are the Outlook/Exchange conversation headers
+	ThreadTopic string
+	ThreadIndex *ThreadIndex
+
+	Keywords     []string
+	Comments     []string
+	Organization string
+
+	ContentLanguage []string
+	AcceptLanguage  []string
+
+	ClientInfo ClientInfo
+	Spam       SpamInfo
+	BIMI       *BIMI
+
+	// Campaign holds email service provider campaign headers keyed by lower case header name
+	Campaign map[string]string
+
+	// Extensions holds the results of parsers registered with RegisterHeaderParser
+	Extensions map[string]interface{}
+
+	// OriginalRecipient is the recipient before aliasing or forwarding, from the Original-Recipient header
+	OriginalRecipient *TypedAddress
+
+	// DeliveryStatus holds the message/delivery-status part of delivery status notifications
+	DeliveryStatus *DeliveryStatus
+
+	// OriginalMessage is the returned message included in a delivery status report,
+	// OriginalHeaders its header, also when only the header was returned
+	OriginalMessage *Email
+	OriginalHeaders mail.Header
 
-	ResentFrom      []*mail.Address
-	ResentSender    *mail.Address
-	ResentTo        []*mail.Address
-	ResentDate      time.Time
-	ResentCc        []*mail.Address
-	ResentBcc       []*mail.Address
-	ResentMessageID string
+	// ResentBlocks has one entry per resend of the message, most recent first
+	ResentBlocks []ResentInfo
 
 	HTMLBody string
 	TextBody string
 
+	// RawHeader is the header exactly as it was read, including the blank line ending it
+	RawHeader []byte
+
+	// RawBody is the body exactly as it was read, before any decoding and with
+	// its original line endings, when parsed WithRawBody
+	RawBody []byte
+
+	// WatchHTMLBody is the text/watch-html alternative sent for Apple Watch displays
+	WatchHTMLBody string
+
 	TextBodyParts []string
 	HTMLBodyParts []string
 
+	// textBodyData and htmlBodyData hold the decoded body parts when parsed
+	// WithBodyReaders, read through TextBodyReader and HTMLBodyReader
+	textBodyData  []io.Reader
+	htmlBodyData  []io.Reader
+	bodySeparator string
+
+	// TextBodyPartOrigins and HTMLBodyPartOrigins tell the multipart/alternative
+	// group and position each of the TextBodyParts and HTMLBodyParts came from
+	TextBodyPartOrigins []BodyPartOrigin
+	HTMLBodyPartOrigins []BodyPartOrigin
+
+	// TextBodyPartLanguages and HTMLBodyPartLanguages hold the Content-Language
+	// tags of each of the TextBodyParts and HTMLBodyParts, nil when not declared
+	TextBodyPartLanguages [][]string
+	HTMLBodyPartLanguages [][]string
+
+	// HTMLBodyBase is the absolute URL relative references of the HTML body
+	// resolve against, from the Content-Location or Content-Base of its first
+	// part (RFC 2557 section 4), empty when it declares none
+	HTMLBodyBase string
+
+	// TextBodyPartOffsets and HTMLBodyPartOffsets locate the body parts in the
+	// source message, in the order of the parts, when parsed WithPartOffsets
+	TextBodyPartOffsets []PartOffsets
+	HTMLBodyPartOffsets []PartOffsets
+
+	// alternativeGroups counts the multipart/alternative groups parsed so far
+	alternativeGroups int
+
+	// ctx is the context the message is parsed with
+	ctx context.Context
+
+	// locator finds the offsets of parts while parsing WithPartOffsets
+	locator *partLocator
+
+	// budget tracks the limits of the parse, shared with nested messages
+	budget *parseBudget
+
+	// registry holds the registrations of the Parser the message was parsed with
+	registry *registry
+
+	// Alternatives lists the parts of the outermost multipart/alternative in
+	// message order, the last one is preferred by the sender, see BestBody
+	Alternatives []Alternative
+
+	// Accounting sums up the sizes of the header and the decoded parts
+	Accounting Accounting
+
+	// Signature is the signature block at the end of TextBody, see SplitSignature,
+	// when parsed WithSignatureDetection or WithSignatureStripping
+	Signature string
+
+	// LikelyBcc is set when the message was likely received as a blind copy, with the reasons in BccEvidence
+	LikelyBcc   bool
+	BccEvidence []string
+
+	// LikelyAutoReply is set when the message was likely sent by a vacation responder, with the reasons in AutoReplyEvidence
+	LikelyAutoReply   bool
+	AutoReplyEvidence []string
+
+	// Warnings describes problems that were tolerated while parsing, like malformed addresses
+	Warnings []string
+
+	// TextAsHTML is a lightweight HTML rendering of TextBody for messages without
+	// a HTML body, when parsed WithTextAsHTML or WithTextToHTMLFallback
+	TextAsHTML string
+
+	// CalendarReply is set when the message carries a text/calendar reply to a meeting invitation
+	CalendarReply *CalendarReply
+
 	Attachments   []Attachment
 	EmbeddedFiles []EmbeddedFile
 }
@@ -32,6 +32,11 @@ func TestParseEmail(t *testing.T) {
 		resentMessageID string
 		inReplyTo       []string
 		references      []string
+		keywords        []string
+		comments        []string
+		organization    string
+		contentLanguage []string
+		acceptLanguage  []string
 		htmlBody        string
 		textBody        string
 		attachments     []attachmentData
@@ -245,6 +250,135 @@ So, "Hello".`,
 				},
 			},
 		},
+		8: {
+			mailData: data3,
+			subject:  "Quarterly report",
+			from: []mail.Address{
+				{
+					Name:    "John Doe",
+					Address: "jdoe@machine.example",
+				},
+			},
+			to: []mail.Address{
+				{
+					Name:    "Mary Smith",
+					Address: "mary@example.net",
+				},
+			},
+			messageID:       "5678@local.machine.example",
+			date:            parseDate("Fri, 21 Nov 1997 09:55:06 -0600"),
+			keywords:        []string{"report", "Q3", "finance", "draft"},
+			comments:        []string{"Please review before Friday", "Numbers are preliminary"},
+			organization:    "Example Corp.",
+			contentLanguage: []string{"en-US", "fr"},
+			acceptLanguage:  []string{"en-US", "en", "sk"},
+			textBody:        `See attached numbers.`,
+		},
+		9: {
+			mailData: data4,
+			subject:  "Price list",
+			from: []mail.Address{
+				{
+					Name:    "John Doe",
+					Address: "jdoe@machine.example",
+				},
+			},
+			to: []mail.Address{
+				{
+					Name:    "Mary Smith",
+					Address: "mary@example.net",
+				},
+			},
+			messageID: "9012@local.machine.example",
+			date:      parseDate("Fri, 21 Nov 1997 09:55:06 -0600"),
+			htmlBody:  `<p>Prices <img src="cid:logo@example"></p>`,
+			attachments: []attachmentData{
+				{
+					filename:    "prices.csv",
+					contentType: "text/csv",
+					base64data:  "bmFtZTtwcmljZQpjYWbDqTsz",
+				},
+			},
+			embeddedFiles: []embeddedFileData{
+				{
+					cid:         "logo@example",
+					contentType: "image/svg+xml",
+					base64data:  "PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciLz4=",
+				},
+			},
+		},
+		10: {
+			mailData: data5,
+			subject:  "Exports",
+			from: []mail.Address{
+				{
+					Name:    "John Doe",
+					Address: "jdoe@machine.example",
+				},
+			},
+			to: []mail.Address{
+				{
+					Name:    "Mary Smith",
+					Address: "mary@example.net",
+				},
+			},
+			messageID: "3456@local.machine.example",
+			date:      parseDate("Fri, 21 Nov 1997 09:55:06 -0600"),
+			textBody:  "Exports attached.",
+			attachments: []attachmentData{
+				{
+					filename:    "a.csv",
+					contentType: "text/csv",
+					base64data:  "aWQsbmFtZQoxLGNhZmU=",
+				},
+				{
+					filename:    "b.csv",
+					contentType: "text/csv",
+					base64data:  "aWQsbmFtZQoxLGNhZsOp",
+				},
+				{
+					filename:    "c.bin",
+					contentType: "application/octet-stream",
+					base64data:  "YmluYXJ5",
+				},
+				{
+					filename:    "d.txt",
+					contentType: "text/plain",
+					base64data:  "bm8gZW5jb2Rpbmc=",
+				},
+			},
+		},
+		11: {
+			mailData: data6,
+			subject:  "Wrapped",
+			from: []mail.Address{
+				{
+					Name:    "John Doe",
+					Address: "jdoe@machine.example",
+				},
+			},
+			to: []mail.Address{
+				{
+					Name:    "Mary Smith",
+					Address: "mary@example.net",
+				},
+			},
+			messageID: "7890@local.machine.example",
+			date:      parseDate("Fri, 21 Nov 1997 09:55:06 -0600"),
+			textBody:  "Signed textScanned by the gateway",
+			attachments: []attachmentData{
+				{
+					filename:    "signature.asc",
+					contentType: "application/pgp-signature",
+					base64data:  "c2lnbmF0dXJl",
+				},
+				{
+					filename:    "notes.txt",
+					contentType: "text/plain",
+					base64data:  "bm90ZXM=",
+				},
+			},
+		},
 	}
 
 	for index, td := range testData {
@@ -293,38 +427,43 @@ So, "Hello".`,
 			t.Errorf("[Test Case %v] Wrong bcc. Expected: %s, Got: %s", index, td.bcc, d)
 		}
 
-		if td.resentMessageID != e.ResentMessageID {
-			t.Errorf("[Test Case %v] Wrong resent messageID. Expected: '%s', Got: '%s'", index, td.resentMessageID, e.ResentMessageID)
+		var resent ResentInfo
+		if len(e.ResentBlocks) > 0 {
+			resent = e.ResentBlocks[0]
+		}
+
+		if td.resentMessageID != resent.MessageID {
+			t.Errorf("[Test Case %v] Wrong resent messageID. Expected: '%s', Got: '%s'", index, td.resentMessageID, resent.MessageID)
 		}
 
-		if !td.resentDate.Equal(e.ResentDate) && !td.resentDate.IsZero() && !e.ResentDate.IsZero() {
-			t.Errorf("[Test Case %v] Wrong resent date. Expected: %v, Got: %v", index, td.resentDate, e.ResentDate)
+		if !td.resentDate.Equal(resent.Date) {
+			t.Errorf("[Test Case %v] Wrong resent date. Expected: %v, Got: %v", index, td.resentDate, resent.Date)
 		}
 
-		d = dereferenceAddressList(e.ResentFrom)
+		d = dereferenceAddressList(resent.From)
 		if !assertAddressListEq(td.resentFrom, d) {
 			t.Errorf("[Test Case %v] Wrong resent from. Expected: %s, Got: %s", index, td.resentFrom, d)
 		}
 
 		var resentSender mail.Address
-		if e.ResentSender != nil {
-			resentSender = *e.ResentSender
+		if resent.Sender != nil {
+			resentSender = *resent.Sender
 		}
 		if td.resentSender != resentSender {
 			t.Errorf("[Test Case %v] Wrong resent sender. Expected: %s, Got: %s", index, td.resentSender, resentSender)
 		}
 
-		d = dereferenceAddressList(e.ResentTo)
+		d = dereferenceAddressList(resent.To)
 		if !assertAddressListEq(td.resentTo, d) {
 			t.Errorf("[Test Case %v] Wrong resent to. Expected: %s, Got: %s", index, td.resentTo, d)
 		}
 
-		d = dereferenceAddressList(e.ResentCc)
+		d = dereferenceAddressList(resent.Cc)
 		if !assertAddressListEq(td.resentCc, d) {
 			t.Errorf("[Test Case %v] Wrong resent cc. Expected: %s, Got: %s", index, td.resentCc, d)
 		}
 
-		d = dereferenceAddressList(e.ResentBcc)
+		d = dereferenceAddressList(resent.Bcc)
 		if !assertAddressListEq(td.resentBcc, d) {
 			t.Errorf("[Test Case %v] Wrong resent bcc. Expected: %s, Got: %s", index, td.resentBcc, d)
 		}
@@ -337,6 +476,26 @@ So, "Hello".`,
 			t.Errorf("[Test Case %v] Wrong references. Expected: %s, Got: %s", index, td.references, e.References)
 		}
 
+		if !assertSliceEq(td.keywords, e.Keywords) {
+			t.Errorf("[Test Case %v] Wrong keywords. Expected: %s, Got: %s", index, td.keywords, e.Keywords)
+		}
+
+		if !assertSliceEq(td.comments, e.Comments) {
+			t.Errorf("[Test Case %v] Wrong comments. Expected: %s, Got: %s", index, td.comments, e.Comments)
+		}
+
+		if td.organization != e.Organization {
+			t.Errorf("[Test Case %v] Wrong organization. Expected: '%s', Got: '%s'", index, td.organization, e.Organization)
+		}
+
+		if !assertSliceEq(td.contentLanguage, e.ContentLanguage) {
+			t.Errorf("[Test Case %v] Wrong content language. Expected: %s, Got: %s", index, td.contentLanguage, e.ContentLanguage)
+		}
+
+		if !assertSliceEq(td.acceptLanguage, e.AcceptLanguage) {
+			t.Errorf("[Test Case %v] Wrong accept language. Expected: %s, Got: %s", index, td.acceptLanguage, e.AcceptLanguage)
+		}
+
 		d = dereferenceAddressList(e.ReplyTo)
 		if !assertAddressListEq(td.replyTo, d) {
 			t.Errorf("[Test Case %v] Wrong reply to. Expected: %s, Got: %s", index, td.replyTo, d)
@@ -368,6 +527,7 @@ So, "Hello".`,
 					if ra.Filename == ad.filename && encoded == ad.base64data && ra.ContentType == ad.contentType {
 						found = true
 						attachs = append(attachs[:i], attachs[i+1:]...)
+						break
 					}
 				}
 
@@ -400,6 +560,7 @@ So, "Hello".`,
 					if ra.CID == ad.cid && encoded == ad.base64data && ra.ContentType == ad.contentType {
 						found = true
 						embeds = append(embeds[:i], embeds[i+1:]...)
+						break
 					}
 				}
 
@@ -583,6 +744,134 @@ YKUKF+Os3baUndC0pDnwNAmLy1SUr2Gw0luxQuV/AwC6cEhVV5VRrwAAAABJRU5ErkJggg==
 --------------C70C0458A558E585ACB75FB4--
 `
 
+var data3 = `From: John Doe <jdoe@machine.example>
+To: Mary Smith <mary@example.net>
+Subject: Quarterly report
+Keywords: report, Q3,
+ finance
+Keywords: draft,
+Comments: Please review before Friday
+Comments: =?UTF-8?Q?Numbers_are_preliminary?=
+Organization: Example Corp.
+Content-Language: en-US, fr
+Accept-Language: en-US,en;q=0.9,sk;q=0.5
+Date: Fri, 21 Nov 1997 09:55:06 -0600
+Message-ID: <5678@local.machine.example>
+
+See attached numbers.
+`
+
+var data4 = `From: John Doe <jdoe@machine.example>
+To: Mary Smith <mary@example.net>
+Subject: Price list
+Date: Fri, 21 Nov 1997 09:55:06 -0600
+Message-ID: <9012@local.machine.example>
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="mixed"
+
+--mixed
+Content-Type: multipart/related; boundary="related"
+
+--related
+Content-Type: text/html; charset=utf-8
+
+<p>Prices <img src="cid:logo@example"></p>
+--related
+Content-Type: image/svg+xml
+Content-ID: <logo@example>
+Content-Transfer-Encoding: quoted-printable
+
+<svg xmlns=3D"http://www.w3.org/2000/svg"/>
+--related--
+
+--mixed
+Content-Type: text/csv; charset=utf-8
+Content-Disposition: attachment; filename="prices.csv"
+Content-Transfer-Encoding: QUOTED-PRINTABLE
+
+name;price
+caf=C3=A9;3
+--mixed--
+`
+
+var data5 = `From: John Doe <jdoe@machine.example>
+To: Mary Smith <mary@example.net>
+Subject: Exports
+Date: Fri, 21 Nov 1997 09:55:06 -0600
+Message-ID: <3456@local.machine.example>
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="mixed"
+
+--mixed
+Content-Type: text/plain
+
+Exports attached.
+--mixed
+Content-Type: text/csv
+Content-Disposition: attachment; filename="a.csv"
+Content-Transfer-Encoding: 7bit
+
+id,name
+1,cafe
+--mixed
+Content-Type: text/csv; charset=utf-8
+Content-Disposition: attachment; filename="b.csv"
+Content-Transfer-Encoding: 8BIT
+
+id,name
+1,café
+--mixed
+Content-Type: application/octet-stream
+Content-Disposition: attachment; filename="c.bin"
+Content-Transfer-Encoding: binary
+
+binary
+--mixed
+Content-Type: text/plain
+Content-Disposition: attachment; filename="d.txt"
+
+no encoding
+--mixed--
+`
+
+var data6 = `From: John Doe <jdoe@machine.example>
+To: Mary Smith <mary@example.net>
+Subject: Wrapped
+Date: Fri, 21 Nov 1997 09:55:06 -0600
+Message-ID: <7890@local.machine.example>
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="gateway"
+
+--gateway
+Content-Type: multipart/mixed; boundary="inner"
+
+--inner
+Content-Type: multipart/signed; protocol="application/pgp-signature"; boundary="signed"
+
+--signed
+Content-Type: text/plain
+
+Signed text
+--signed
+Content-Type: application/pgp-signature; name="signature.asc"
+
+signature
+--signed--
+
+--inner
+Content-Type: text/plain; name="notes.txt"
+Content-Transfer-Encoding: base64
+
+bm90ZXM=
+--inner--
+
+--gateway
+Content-Type: text/plain
+
+Scanned by the gateway
+--gateway--
+`
+
 var rfc5322exampleA11 = `From: John Doe <jdoe@machine.example>
 Sender: Michael Jones <mjones@machine.example>
 To: Mary Smith <mary@example.net>
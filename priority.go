@@ -0,0 +1,73 @@
+package parsemail
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Priority of a message normalized from the X-Priority, Importance, Priority and X-MSMail-Priority headers
+type Priority int
+
+// Normalized priorities, PriorityNormal is used when no priority header is present
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+	PriorityLow
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "High"
+	case PriorityLow:
+		return "Low"
+	default:
+		return "Normal"
+	}
+}
+
+// priorityHeaders in order of precedence when they disagree
+var priorityHeaders = []string{"X-Priority", "Importance", "Priority", "X-MSMail-Priority"}
+
+func (hp headerParser) parsePriority() Priority {
+	for _, name := range priorityHeaders {
+		if p, ok := normalizePriority(hp.header.Get(name)); ok {
+			return p
+		}
+	}
+
+	return PriorityNormal
+}
+
+// normalizePriority maps the numeric 1-5 X-Priority scale as well as the
+// textual high/normal/low and urgent/normal/non-urgent scales to Priority
+func normalizePriority(s string) (Priority, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return PriorityNormal, false
+	}
+
+	if f := strings.Fields(s); len(f) > 0 {
+		if n, err := strconv.Atoi(f[0]); err == nil {
+			switch {
+			case n < 3:
+				return PriorityHigh, true
+			case n > 3:
+				return PriorityLow, true
+			default:
+				return PriorityNormal, true
+			}
+		}
+	}
+
+	switch s {
+	case "high", "urgent", "highest":
+		return PriorityHigh, true
+	case "low", "non-urgent", "lowest":
+		return PriorityLow, true
+	case "normal":
+		return PriorityNormal, true
+	}
+
+	return PriorityNormal, false
+}
@@ -0,0 +1,32 @@
+package parsemail
+
+import (
+	"net/mail"
+	"testing"
+)
+
+func TestParsePriority(t *testing.T) {
+	var testData = map[int]struct {
+		header   mail.Header
+		expected Priority
+	}{
+		1:  {header: mail.Header{}, expected: PriorityNormal},
+		2:  {header: mail.Header{"X-Priority": {"1 (Highest)"}}, expected: PriorityHigh},
+		3:  {header: mail.Header{"X-Priority": {"2"}}, expected: PriorityHigh},
+		4:  {header: mail.Header{"X-Priority": {"3 (Normal)"}}, expected: PriorityNormal},
+		5:  {header: mail.Header{"X-Priority": {"5 (Lowest)"}}, expected: PriorityLow},
+		6:  {header: mail.Header{"Importance": {"High"}}, expected: PriorityHigh},
+		7:  {header: mail.Header{"Priority": {"non-urgent"}}, expected: PriorityLow},
+		8:  {header: mail.Header{"Priority": {"urgent"}}, expected: PriorityHigh},
+		9:  {header: mail.Header{"X-Msmail-Priority": {"Low"}}, expected: PriorityLow},
+		10: {header: mail.Header{"X-Priority": {"4"}, "Importance": {"high"}}, expected: PriorityLow},
+		11: {header: mail.Header{"X-Priority": {"garbage"}, "Importance": {"low"}}, expected: PriorityLow},
+	}
+
+	for index, td := range testData {
+		hp := headerParser{header: &td.header}
+		if got := hp.parsePriority(); got != td.expected {
+			t.Errorf("[Test Case %v] Wrong priority. Expected: %s, Got: %s", index, td.expected, got)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package parsemail
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// quotePreamblePattern matches the attribution line clients put above a quoted reply, in common languages
+	quotePreamblePattern = regexp.MustCompile(`(?i)^\s*(?:` +
+		`on\s.+\swrote|` +
+		`am\s.+\sschrieb\s.+|` +
+		`le\s.+\sa\s+écrit\s*|` +
+		`el\s.+\sescribió|` +
+		`il\s.+\sha\s+scritto|` +
+		`op\s.+\sschreef\s.+|` +
+		`em\s.+\sescreveu|` +
+		`.+\s<[^<>\s]+@[^<>\s]+>\s+(?:wrote|schrieb|a écrit|escribió|ha scritto|schreef|escreveu))\s*:\s*$`)
+	// quoteHeaderPattern matches the first line of the header block Outlook puts above a quoted reply
+	quoteHeaderPattern = regexp.MustCompile(`(?i)^\s*\*?(?:from|von|de|da|van)\*?:\s*\S`)
+	// quoteHeaderNextPattern matches the header lines following quoteHeaderPattern
+	quoteHeaderNextPattern = regexp.MustCompile(`(?i)^\s*\*?(?:sent|date|to|subject|gesendet|datum|an|betreff|envoyé|objet|enviado|asunto|inviato|oggetto|verzonden|onderwerp)\*?:`)
+)
+
+// StripQuoted returns the new content of a plain text reply: quoted lines
+// starting with ">", "On ... wrote:" style preambles and everything from an
+// Outlook style separator or header block on are removed.
+func StripQuoted(text string) string {
+	text = NewContent(strings.Replace(text, "\r\n", "\n", -1))
+	lines := strings.Split(text, "\n")
+
+	var kept []string
+	for i := 0; i < len(lines); i++ {
+		if isQuoteHeader(lines, i) {
+			break
+		}
+
+		if strings.HasPrefix(strings.TrimLeft(lines[i], " \t"), ">") {
+			continue
+		}
+
+		// a preamble above ">" quoted lines is dropped with them, one above unmarked content starts the quote
+		if span := quotePreambleSpan(lines, i); span > 0 {
+			if !quotedLinesFollow(lines, i+span) {
+				break
+			}
+
+			i += span - 1
+			continue
+		}
+
+		kept = append(kept, lines[i])
+	}
+
+	return strings.TrimSpace(collapseBlankLines(kept))
+}
+
+// VisibleText returns the new content of the email without quoted previous
+// messages, see StripQuoted. Messages without a text body use a rendering of
+// the HTML body.
+func (e Email) VisibleText() string {
+	text := e.TextBody
+	if text == "" && e.HTMLBody != "" {
+		text = HTMLToText(e.HTMLBody)
+	}
+
+	return StripQuoted(text)
+}
+
+// isQuoteHeader reports whether lines[i] starts a "From: ... Sent: ..." header block
+func isQuoteHeader(lines []string, i int) bool {
+	if !quoteHeaderPattern.MatchString(lines[i]) {
+		return false
+	}
+
+	for j := i + 1; j < len(lines) && j <= i+2; j++ {
+		if quoteHeaderNextPattern.MatchString(lines[j]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quotePreambleSpan returns the number of lines of the preamble starting at lines[i],
+// which is zero if there is none and two if it is wrapped
+func quotePreambleSpan(lines []string, i int) int {
+	switch {
+	case quotePreamblePattern.MatchString(lines[i]):
+		return 1
+	case i+1 < len(lines) && !quotePreamblePattern.MatchString(lines[i+1]) &&
+		quotePreamblePattern.MatchString(lines[i]+" "+lines[i+1]):
+		return 2
+	}
+
+	return 0
+}
+
+// quotedLinesFollow reports whether the first non blank line from lines[i] on is quoted
+func quotedLinesFollow(lines []string, i int) bool {
+	for ; i < len(lines); i++ {
+		if trimmed := strings.TrimSpace(lines[i]); trimmed != "" {
+			return strings.HasPrefix(trimmed, ">")
+		}
+	}
+
+	return true
+}
+
+// collapseBlankLines joins lines replacing runs of blank lines with a single one
+func collapseBlankLines(lines []string) string {
+	var result []string
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" && i > 0 && strings.TrimSpace(lines[i-1]) == "" {
+			continue
+		}
+
+		result = append(result, line)
+	}
+
+	return strings.Join(result, "\n")
+}
@@ -0,0 +1,62 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripQuoted(t *testing.T) {
+	var testData = map[int]struct {
+		text     string
+		expected string
+	}{
+		1: {
+			text:     "Thanks, fixed.\n\nOn Mon, 21 Nov 1997 at 09:55, John Doe <jdoe@machine.example> wrote:\n> It's broken.\n> Please fix.\n",
+			expected: "Thanks, fixed.",
+		},
+		2: {
+			text:     "Sounds good.\r\n\r\nOn Mon, 21 Nov 1997 at 09:55, John Doe\r\n<jdoe@machine.example> wrote:\r\n\r\n> Lunch?\r\n",
+			expected: "Sounds good.",
+		},
+		3: {
+			text:     "On Mon, Mary wrote:\n> Question one?\nAnswer one.\n\n> Question two?\nAnswer two.\n",
+			expected: "Answer one.\n\nAnswer two.",
+		},
+		4: {
+			text:     "See below.\n\n-----Original Message-----\nFrom: John Doe\nSent: Monday\n\nOld text\n",
+			expected: "See below.",
+		},
+		5: {
+			text:     "Ja, passt.\n\nFrom: John Doe <jdoe@machine.example>\nSent: Monday, November 21, 1997 9:55 AM\nTo: Mary\nSubject: Lunch\n\nLunch?\n",
+			expected: "Ja, passt.",
+		},
+		6: {
+			text:     "Merci !\n\nLe lun. 21 nov. 1997 à 09:55, John Doe <jdoe@machine.example> a écrit :\nCa marche ?\n",
+			expected: "Merci !",
+		},
+		7: {
+			text:     "Hi,\n\nI wrote: the server is down.\nFrom: the logs it looks like a disk issue.\n",
+			expected: "Hi,\n\nI wrote: the server is down.\nFrom: the logs it looks like a disk issue.",
+		},
+	}
+
+	for index, td := range testData {
+		if got := StripQuoted(td.text); got != td.expected {
+			t.Errorf("[Test Case %v] Wrong visible text. Expected: %q, Got: %q", index, td.expected, got)
+		}
+	}
+}
+
+func TestVisibleText(t *testing.T) {
+	mailData := "From: Mary Smith <mary@example.net>\nContent-Type: text/html\n\n" +
+		"<div>Works for me.</div><div>On Mon, John Doe wrote:</div><blockquote><p>Lunch?</p></blockquote>\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := e.VisibleText(); got != "Works for me." {
+		t.Errorf("Wrong visible text. Expected: %q, Got: %q", "Works for me.", got)
+	}
+}
@@ -0,0 +1,65 @@
+package parsemail
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/textproto"
+	"strings"
+)
+
+// headerField is a single header field in the order it appeared in the message
+type headerField struct {
+	Name  string
+	Value string
+}
+
+// readRawHeader reads the message header up to and including the blank line separating it from the body
+func readRawHeader(br *bufio.Reader) ([]byte, error) {
+	var raw bytes.Buffer
+	for {
+		line, err := br.ReadBytes('\n')
+		raw.Write(line)
+
+		if err == io.EOF {
+			return raw.Bytes(), nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if len(bytes.TrimRight(line, "\r\n")) == 0 {
+			return raw.Bytes(), nil
+		}
+	}
+}
+
+// parseHeaderFields splits a raw header into its unfolded fields, keeping their order.
+// Names are canonicalized like in mail.Header, lines that aren't fields are skipped.
+func parseHeaderFields(raw []byte) (fields []headerField) {
+	for _, line := range strings.SplitAfter(string(raw), "\n") {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if trimmed == "" {
+			continue
+		}
+
+		if trimmed[0] == ' ' || trimmed[0] == '\t' {
+			if len(fields) > 0 {
+				f := &fields[len(fields)-1]
+				f.Value = strings.TrimSpace(f.Value + " " + strings.TrimSpace(trimmed))
+			}
+			continue
+		}
+
+		colon := strings.IndexByte(trimmed, ':')
+		if colon <= 0 {
+			continue
+		}
+
+		fields = append(fields, headerField{
+			Name:  textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(trimmed[:colon])),
+			Value: strings.TrimSpace(trimmed[colon+1:]),
+		})
+	}
+
+	return
+}
@@ -0,0 +1,30 @@
+package parsemail
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+)
+
+// rawBuffer records the transfer encoded content of a part, a nil rawBuffer records nothing
+type rawBuffer struct {
+	bytes.Buffer
+}
+
+func (b *rawBuffer) bytes() []byte {
+	if b == nil {
+		return nil
+	}
+
+	return b.Bytes()
+}
+
+// rawPartSource returns the reader to decode part from, recording its raw content when parsing WithRawParts
+func rawPartSource(part *multipart.Part, o *options) (io.Reader, *rawBuffer) {
+	if !o.keepRawParts {
+		return part, nil
+	}
+
+	raw := &rawBuffer{}
+	return io.TeeReader(part, raw), raw
+}
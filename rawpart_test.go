@@ -0,0 +1,82 @@
+package parsemail
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestRawParts(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\n\nbody\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\"\nContent-Transfer-Encoding: base64\n\nY2Fmw6kg\nYXUgbGFpdA==\n" +
+		"--m\nContent-Type: application/octet-stream\nContent-Disposition: attachment; filename=\"b.bin\"\nContent-Transfer-Encoding: base64\n\naGVs\nbG8=\n" +
+		"--m\nContent-Type: image/png\nContent-ID: <img@x>\nContent-Transfer-Encoding: base64\n\niVBORw==\n--m--\n"
+
+	var testData = map[int]struct {
+		options      []Option
+		attachments  []string
+		embeddedFile string
+		decoded      []string
+	}{
+		1: {
+			decoded: []string{"café au lait", "hello"},
+		},
+		2: {
+			options:      []Option{WithRawParts()},
+			attachments:  []string{"Y2Fmw6kg\nYXUgbGFpdA==", "aGVs\nbG8="},
+			embeddedFile: "iVBORw==",
+			decoded:      []string{"café au lait", "hello"},
+		},
+		3: {
+			options:      []Option{WithRawParts(), WithSpooling(1, "")},
+			attachments:  []string{"Y2Fmw6kg\nYXUgbGFpdA==", "aGVs\nbG8="},
+			embeddedFile: "iVBORw==",
+			decoded:      []string{"café au lait", "hello"},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := ParseWithOptions(strings.NewReader(mailData), td.options...)
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if len(e.Attachments) != 2 || len(e.EmbeddedFiles) != 1 {
+			t.Errorf("[Test Case %v] Wrong parts. Expected: 2 attachments and 1 embedded file, Got: %v and %v", index, len(e.Attachments), len(e.EmbeddedFiles))
+			e.Close()
+			continue
+		}
+
+		for i, a := range e.Attachments {
+			if a.Header.Get("Content-Transfer-Encoding") == "" {
+				t.Errorf("[Test Case %v] Attachment %v header lacks the transfer encoding: %v", index, i, a.Header)
+			}
+
+			var raw string
+			if td.attachments != nil {
+				raw = td.attachments[i]
+			}
+			if string(a.RawData) != raw {
+				t.Errorf("[Test Case %v] Wrong raw data of attachment %v. Expected: %q, Got: %q", index, i, raw, a.RawData)
+			}
+
+			data, _ := ioutil.ReadAll(a.Data)
+			if string(data) != td.decoded[i] {
+				t.Errorf("[Test Case %v] Wrong data of attachment %v. Expected: %q, Got: %q", index, i, td.decoded[i], data)
+			}
+		}
+
+		ef := e.EmbeddedFiles[0]
+		if ef.Header.Get("Content-Id") != "<img@x>" {
+			t.Errorf("[Test Case %v] Wrong embedded file header: %v", index, ef.Header)
+		}
+
+		if string(ef.RawData) != td.embeddedFile {
+			t.Errorf("[Test Case %v] Wrong raw data of embedded file. Expected: %q, Got: %q", index, td.embeddedFile, ef.RawData)
+		}
+
+		e.Close()
+	}
+}
@@ -0,0 +1,121 @@
+package parsemail
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var receivedClausePattern = regexp.MustCompile(`(?i)\b(from|by|with|id|for)\s+<?([^\s<>;]+)>?`)
+
+// ReceivedHop is a parsed Received header field, one per relay the message passed
+type ReceivedHop struct {
+	From string
+	By   string
+	With string
+	ID   string
+	For  string
+	Date time.Time
+
+	Raw string
+}
+
+// HopDelay is a hop with the time it took to reach it from the previous one.
+// Delay is negative for hops with clocks running behind, and zero when the
+// hop or all hops before it have no date.
+type HopDelay struct {
+	ReceivedHop
+	Delay time.Duration
+	Slow  bool
+}
+
+// Transit describes how long a message took from its Date to the last hop
+type Transit struct {
+	Hops  []HopDelay
+	Total time.Duration
+}
+
+// SlowHops returns the hops whose delay exceeded the threshold given to Email.Transit
+func (t Transit) SlowHops() (slow []HopDelay) {
+	for _, h := range t.Hops {
+		if h.Slow {
+			slow = append(slow, h)
+		}
+	}
+
+	return
+}
+
+// parseReceived parses a Received field value: the clauses before the last
+// ";" and the date after it. Comments are ignored.
+func parseReceived(s string) ReceivedHop {
+	hop := ReceivedHop{Raw: s}
+	clauses := s
+	if i := strings.LastIndex(s, ";"); i != -1 {
+		clauses = s[:i]
+		hop.Date = headerParser{}.parseTime(strings.TrimSpace(s[i+1:]))
+	}
+
+	clauses, _ = splitComments(clauses)
+	for _, m := range receivedClausePattern.FindAllStringSubmatch(clauses, -1) {
+		var field *string
+		switch strings.ToLower(m[1]) {
+		case "from":
+			field = &hop.From
+		case "by":
+			field = &hop.By
+		case "with":
+			field = &hop.With
+		case "id":
+			field = &hop.ID
+		case "for":
+			field = &hop.For
+		}
+
+		if *field == "" {
+			*field = m[2]
+		}
+	}
+
+	return hop
+}
+
+// ReceivedHops returns the parsed Received fields in the order the message
+// passed the relays, the reverse of their order in the header
+func (e Email) ReceivedHops() (hops []ReceivedHop) {
+	received := e.Header["Received"]
+	for i := len(received) - 1; i >= 0; i-- {
+		hops = append(hops, parseReceived(received[i]))
+	}
+
+	return
+}
+
+// Transit computes the delay of every hop relative to the previous dated hop,
+// or to the Date of the email for the first one, flagging hops delayed more
+// than slowThreshold. Total is the time from the Date of the email, or the
+// first dated hop, to the last dated hop.
+func (e Email) Transit(slowThreshold time.Duration) (t Transit) {
+	previous := e.Date
+	first := e.Date
+	for _, hop := range e.ReceivedHops() {
+		hd := HopDelay{ReceivedHop: hop}
+		if !hop.Date.IsZero() {
+			if !previous.IsZero() {
+				hd.Delay = hop.Date.Sub(previous)
+				hd.Slow = slowThreshold > 0 && hd.Delay > slowThreshold
+			}
+
+			if first.IsZero() {
+				first = hop.Date
+			}
+
+			t.Total = hop.Date.Sub(first)
+			previous = hop.Date
+		}
+
+		t.Hops = append(t.Hops, hd)
+	}
+
+	return
+}
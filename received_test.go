@@ -0,0 +1,61 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReceivedHops(t *testing.T) {
+	hop := parseReceived("from mail.example.com (mail.example.com [192.0.2.1]) by mx.example.org (Postfix) with ESMTPS id 4A1B2C for <jane@example.org>; Mon, 4 Mar 2019 10:00:30 +0000 (UTC)")
+	if hop.From != "mail.example.com" || hop.By != "mx.example.org" || hop.With != "ESMTPS" || hop.ID != "4A1B2C" || hop.For != "jane@example.org" {
+		t.Errorf("Wrong hop clauses: %+v", hop)
+	}
+
+	if !hop.Date.Equal(time.Date(2019, 3, 4, 10, 0, 30, 0, time.UTC)) {
+		t.Errorf("Wrong hop date: %v", hop.Date)
+	}
+}
+
+func TestTransit(t *testing.T) {
+	message := "Received: by mailbox.example.org; Mon, 4 Mar 2019 10:20:31 +0000\n" +
+		"Received: from relay.example.net by mx.example.org; Mon, 4 Mar 2019 10:00:31 +0000\n" +
+		"Received: from localhost by relay.example.net; garbage\n" +
+		"Received: from laptop by mail.example.com; Mon, 4 Mar 2019 11:00:01 +0100\n" +
+		"From: jane@example.com\nDate: Mon, 4 Mar 2019 10:00:00 +0000\n\nbody\n"
+
+	e, err := Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	transit := e.Transit(10 * time.Minute)
+	expected := []struct {
+		by    string
+		delay time.Duration
+		slow  bool
+	}{
+		{by: "mail.example.com", delay: time.Second},
+		{by: "relay.example.net"},
+		{by: "mx.example.org", delay: 30 * time.Second},
+		{by: "mailbox.example.org", delay: 20 * time.Minute, slow: true},
+	}
+
+	if len(transit.Hops) != len(expected) {
+		t.Fatalf("Wrong number of hops. Expected: %v, Got: %v", len(expected), len(transit.Hops))
+	}
+
+	for i, h := range transit.Hops {
+		if h.By != expected[i].by || h.Delay != expected[i].delay || h.Slow != expected[i].slow {
+			t.Errorf("Wrong hop %v. Expected: %+v, Got: %s %v %v", i, expected[i], h.By, h.Delay, h.Slow)
+		}
+	}
+
+	if transit.Total != 20*time.Minute+31*time.Second {
+		t.Errorf("Wrong total transit time: %v", transit.Total)
+	}
+
+	if slow := transit.SlowHops(); len(slow) != 1 || slow[0].By != "mailbox.example.org" {
+		t.Errorf("Wrong slow hops: %v", slow)
+	}
+}
@@ -0,0 +1,274 @@
+package parsemail
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Redaction lists header fields to remove or replace by a hash when a message
+// is exported, for privacy preserving data sharing. Hashed values are
+// "sha256:" followed by the hex encoded SHA-256 (HMAC-SHA256 when HashKey is
+// set) of the original value, so equal values stay correlatable without being
+// revealed. Field names are case insensitive. Email.Redacted applies it to a
+// whole message, export that copy, with encoding/json or through Handler.
+type Redaction struct {
+	Drop    []string
+	Hash    []string
+	HashKey []byte
+}
+
+// Apply returns a copy of header with the redaction applied, header itself is not modified
+func (r Redaction) Apply(header mail.Header) mail.Header {
+	drop := map[string]bool{}
+	for _, name := range r.Drop {
+		drop[textproto.CanonicalMIMEHeaderKey(name)] = true
+	}
+
+	hash := map[string]bool{}
+	for _, name := range r.Hash {
+		hash[textproto.CanonicalMIMEHeaderKey(name)] = true
+	}
+
+	redacted := mail.Header{}
+	for name, values := range header {
+		key := textproto.CanonicalMIMEHeaderKey(name)
+		if drop[key] {
+			continue
+		}
+
+		copied := make([]string, len(values))
+		for i, v := range values {
+			if hash[key] {
+				v = r.hashValue(v)
+			}
+			copied[i] = v
+		}
+
+		redacted[name] = copied
+	}
+
+	return redacted
+}
+
+func (r Redaction) hashValue(v string) string {
+	var sum []byte
+	if len(r.HashKey) > 0 {
+		mac := hmac.New(sha256.New, r.HashKey)
+		mac.Write([]byte(v))
+		sum = mac.Sum(nil)
+	} else {
+		s := sha256.Sum256([]byte(v))
+		sum = s[:]
+	}
+
+	return "sha256:" + hex.EncodeToString(sum)
+}
+
+// applyMIME is Apply for part headers, a nil header stays nil
+func (r Redaction) applyMIME(header textproto.MIMEHeader) textproto.MIMEHeader {
+	if header == nil {
+		return nil
+	}
+
+	return textproto.MIMEHeader(r.Apply(mail.Header(header)))
+}
+
+// redacts reports whether the field name is dropped or hashed
+func (r Redaction) redacts(name string) bool {
+	for _, n := range append(r.Drop, r.Hash...) {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyResent returns a copy of blocks with the fields parsed from redacted
+// Resent- fields cleared
+func (r Redaction) applyResent(blocks []ResentInfo) []ResentInfo {
+	if blocks == nil {
+		return nil
+	}
+
+	redacted := make([]ResentInfo, len(blocks))
+	for i, ri := range blocks {
+		if r.redacts("Resent-From") {
+			ri.From = nil
+		}
+		if r.redacts("Resent-Sender") {
+			ri.Sender = nil
+		}
+		if r.redacts("Resent-To") {
+			ri.To = nil
+		}
+		if r.redacts("Resent-Cc") {
+			ri.Cc = nil
+		}
+		if r.redacts("Resent-Bcc") {
+			ri.Bcc = nil
+		}
+		if r.redacts("Resent-Date") {
+			ri.Date = time.Time{}
+		}
+		if r.redacts("Resent-Message-ID") {
+			ri.MessageID = ""
+		}
+		redacted[i] = ri
+	}
+
+	return redacted
+}
+
+// redactedEmailFields clear the Email fields parsed from a header field
+var redactedEmailFields = map[string]func(e *Email){
+	"Subject":            func(e *Email) { e.Subject = "" },
+	"From":               func(e *Email) { e.From = nil },
+	"Sender":             func(e *Email) { e.Sender = nil },
+	"Reply-To":           func(e *Email) { e.ReplyTo = nil },
+	"To":                 func(e *Email) { e.To = nil },
+	"Cc":                 func(e *Email) { e.Cc = nil },
+	"Bcc":                func(e *Email) { e.Bcc = nil },
+	"Date":               func(e *Email) { e.Date = time.Time{} },
+	"Message-Id":         func(e *Email) { e.MessageID = "" },
+	"In-Reply-To":        func(e *Email) { e.InReplyTo = nil },
+	"References":         func(e *Email) { e.References = nil },
+	"Thread-Topic":       func(e *Email) { e.ThreadTopic = "" },
+	"Thread-Index":       func(e *Email) { e.ThreadIndex = nil },
+	"Keywords":           func(e *Email) { e.Keywords = nil },
+	"Comments":           func(e *Email) { e.Comments = nil },
+	"Organization":       func(e *Email) { e.Organization = "" },
+	"Content-Language":   func(e *Email) { e.ContentLanguage = nil },
+	"Accept-Language":    func(e *Email) { e.AcceptLanguage = nil },
+	"Original-Recipient": func(e *Email) { e.OriginalRecipient = nil },
+}
+
+// applyFields clears the fields of e parsed from redacted header fields
+func (r Redaction) applyFields(e *Email) {
+	for name, clear := range redactedEmailFields {
+		if r.redacts(name) {
+			clear(e)
+		}
+	}
+
+	if e.Extensions != nil {
+		extensions := map[string]interface{}{}
+		for name, v := range e.Extensions {
+			if !r.redacts(name) {
+				extensions[name] = v
+			}
+		}
+		e.Extensions = extensions
+	}
+}
+
+// applyDeliveryStatus returns a copy of ds with the values of redacted
+// delivery status fields cleared
+func (r Redaction) applyDeliveryStatus(ds *DeliveryStatus) *DeliveryStatus {
+	if ds == nil {
+		return nil
+	}
+
+	redacted := *ds
+	if r.redacts("Reporting-MTA") {
+		redacted.ReportingMTA = ""
+	}
+	if r.redacts("Received-From-MTA") {
+		redacted.ReceivedFromMTA = ""
+	}
+	if r.redacts("Original-Envelope-Id") {
+		redacted.OriginalEnvelopeID = ""
+	}
+	if r.redacts("Arrival-Date") {
+		redacted.ArrivalDate = time.Time{}
+	}
+
+	redacted.Recipients = make([]RecipientStatus, len(ds.Recipients))
+	for i, rs := range ds.Recipients {
+		if r.redacts("Original-Recipient") {
+			rs.OriginalRecipient = nil
+		}
+		if r.redacts("Final-Recipient") {
+			rs.FinalRecipient = nil
+		}
+		if r.redacts("Remote-MTA") {
+			rs.RemoteMTA = ""
+		}
+		if r.redacts("Diagnostic-Code") {
+			rs.DiagnosticCode = ""
+		}
+		if r.redacts("Last-Attempt-Date") {
+			rs.LastAttemptDate = time.Time{}
+		}
+		redacted.Recipients[i] = rs
+	}
+
+	return &redacted
+}
+
+// Redacted returns a copy of the email with the redaction applied to its Header,
+// to the headers of its attachments and embedded files and, recursively, to
+// attached and returned messages. The fields parsed from redacted header fields
+// and delivery status fields are cleared, and ClientInfo, Spam, BIMI, Campaign,
+// ResentBlocks and the Bcc and auto reply evidence are rebuilt from the
+// redacted fields. RawHeader and RawBody, which hold the fields as they were
+// read, are dropped, as are the data of attached messages, leaving their
+// redacted Message.
+func (e Email) Redacted(r Redaction) Email {
+	e.Header = r.Apply(e.Header)
+	e.RawHeader = nil
+	e.RawBody = nil
+
+	r.applyFields(&e)
+	hp := headerParser{header: &e.Header, registry: e.registry}
+	e.Priority = hp.parsePriority()
+	e.ClientInfo = hp.parseClientInfo()
+	e.Spam = hp.parseSpamInfo()
+	e.BIMI = hp.parseBIMI()
+	e.Campaign = hp.parseCampaign()
+	e.ResentBlocks = r.applyResent(e.ResentBlocks)
+	e.DeliveryStatus = r.applyDeliveryStatus(e.DeliveryStatus)
+	detectBcc(&e)
+	detectAutoReply(&e)
+
+	if e.OriginalHeaders != nil {
+		e.OriginalHeaders = r.Apply(e.OriginalHeaders)
+	}
+
+	if e.OriginalMessage != nil {
+		original := e.OriginalMessage.Redacted(r)
+		e.OriginalMessage = &original
+	}
+
+	if e.Attachments != nil {
+		attachments := make([]Attachment, len(e.Attachments))
+		for i, a := range e.Attachments {
+			a.Header = r.applyMIME(a.Header)
+			if a.Message != nil {
+				message := a.Message.Redacted(r)
+				a.Message = &message
+				a.Data = bytes.NewReader(nil)
+				a.RawData = nil
+			}
+			attachments[i] = a
+		}
+		e.Attachments = attachments
+	}
+
+	if e.EmbeddedFiles != nil {
+		embeddedFiles := make([]EmbeddedFile, len(e.EmbeddedFiles))
+		for i, ef := range e.EmbeddedFiles {
+			ef.Header = r.applyMIME(ef.Header)
+			embeddedFiles[i] = ef
+		}
+		e.EmbeddedFiles = embeddedFiles
+	}
+
+	return e
+}
@@ -0,0 +1,175 @@
+package parsemail
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	header := mail.Header{
+		"Received":         {"from a by b", "from c by d"},
+		"X-Originating-Ip": {"[192.0.2.1]"},
+		"Delivered-To":     {"mary@example.net"},
+		"Subject":          {"Saying Hello"},
+	}
+
+	r := Redaction{
+		Drop: []string{"received", "Delivered-To"},
+		Hash: []string{"X-Originating-IP"},
+	}
+
+	redacted := r.Apply(header)
+	if _, ok := redacted["Received"]; ok {
+		t.Errorf("Received was not dropped")
+	}
+
+	if _, ok := redacted["Delivered-To"]; ok {
+		t.Errorf("Delivered-To was not dropped")
+	}
+
+	if redacted.Get("Subject") != "Saying Hello" {
+		t.Errorf("Subject was modified: %s", redacted.Get("Subject"))
+	}
+
+	hashed := redacted.Get("X-Originating-Ip")
+	if !strings.HasPrefix(hashed, "sha256:") || strings.Contains(hashed, "192.0.2.1") {
+		t.Errorf("X-Originating-IP was not hashed: %s", hashed)
+	}
+
+	if hashed != r.Apply(header).Get("X-Originating-Ip") {
+		t.Errorf("Hashing is not deterministic")
+	}
+
+	keyed := Redaction{Hash: r.Hash, HashKey: []byte("secret")}.Apply(header).Get("X-Originating-Ip")
+	if keyed == hashed || !strings.HasPrefix(keyed, "sha256:") {
+		t.Errorf("Keyed hash not applied: %s", keyed)
+	}
+
+	if len(header["Received"]) != 2 || header.Get("X-Originating-Ip") != "[192.0.2.1]" {
+		t.Errorf("Original header was modified: %v", header)
+	}
+}
+
+func TestEmailRedacted(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nResent-From: mary@example.net\nResent-Date: Tue, 1 Jul 2003 10:52:37 +0200\n" +
+		"X-Mailer: Secret Mailer 1.0\nX-Campaign: spring-sale\nX-Originating-IP: [192.0.2.1]\n" +
+		"Content-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\n\nbody\n" +
+		"--m\nContent-Type: message/rfc822\nContent-Disposition: attachment; filename=\"fwd.eml\"\nX-Originating-IP: [192.0.2.2]\n\n" +
+		"From: mary@example.net\nX-Mailer: Secret Mailer 1.0\nX-Originating-IP: [192.0.2.3]\n\nforwarded\n--m--\n"
+
+	e, err := ParseWithOptions(strings.NewReader(mailData), WithRawBody(), WithRawParts())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := Redaction{Drop: []string{"X-Mailer", "X-Campaign", "Resent-From"}, Hash: []string{"X-Originating-IP"}}
+	redacted := e.Redacted(r)
+
+	if redacted.RawHeader != nil || redacted.RawBody != nil {
+		t.Errorf("Raw header and body were kept")
+	}
+
+	if redacted.ClientInfo.Mailer != "" || redacted.Campaign != nil {
+		t.Errorf("Client info or campaign were kept: %v %v", redacted.ClientInfo, redacted.Campaign)
+	}
+
+	if len(redacted.ResentBlocks) != 1 || redacted.ResentBlocks[0].From != nil || redacted.ResentBlocks[0].Date.IsZero() {
+		t.Errorf("Wrong resent blocks: %v", redacted.ResentBlocks)
+	}
+
+	a := redacted.Attachments[0]
+	if strings.Contains(a.Header.Get("X-Originating-Ip"), "192.0.2.2") {
+		t.Errorf("Attachment header was not redacted: %v", a.Header)
+	}
+
+	if data, _ := ioutil.ReadAll(a.Data); len(data) != 0 || a.RawData != nil {
+		t.Errorf("Attached message data was kept: %s", data)
+	}
+
+	if a.Message.ClientInfo.Mailer != "" || strings.Contains(a.Message.Header.Get("X-Originating-Ip"), "192.0.2.3") {
+		t.Errorf("Attached message was not redacted: %v", a.Message.Header)
+	}
+
+	if e.ClientInfo.Mailer != "Secret Mailer 1.0" || e.Attachments[0].Message.Header.Get("X-Mailer") == "" || e.RawHeader == nil {
+		t.Errorf("Original email was modified")
+	}
+}
+
+func TestRedactedJSONExport(t *testing.T) {
+	dsn := "From: MAILER-DAEMON@mx.example.net\nTo: sender@example.com\nSubject: Undelivered Mail\n" +
+		"Content-Type: multipart/report; report-type=delivery-status; boundary=b\n\n" +
+		"--b\nContent-Type: text/plain\n\nFailed.\n--b\nContent-Type: message/delivery-status\n\nReporting-MTA: dns; relay.example.org\n\n" +
+		"Final-Recipient: rfc822; mary@example.net\nAction: failed\nStatus: 5.1.1\nDiagnostic-Code: smtp; 550 mary@example.net unknown\n--b--\n"
+
+	var testData = map[int]struct {
+		mailData  string
+		options   []Option
+		redaction Redaction
+		leaked    []string
+	}{
+		1: {
+			mailData:  "From: jdoe@machine.example\nX-Originating-IP: [192.0.2.1]\nDelivered-To: mary@example.net\nSubject: Saying Hello\n\nbody\n",
+			redaction: Redaction{Drop: []string{"Delivered-To"}, Hash: []string{"X-Originating-IP"}},
+			leaked:    []string{"192.0.2.1", "mary@example.net"},
+		},
+		2: {
+			mailData: "From: jdoe@machine.example\nTo: bob@example.com\nCc: carol@example.com\nDelivered-To: mary@example.net\n" +
+				"Received: from mx.example.net by mx.example.com for <dave@example.org>; Tue, 1 Jul 2003 10:52:37 +0200\nSubject: Saying Hello\n\nbody\n",
+			redaction: Redaction{Drop: []string{"Delivered-To", "Received"}},
+			leaked:    []string{"mary@example.net", "dave@example.org"},
+		},
+		3: {
+			mailData:  "From: jdoe@machine.example\nTo: bob@example.com\nCc: carol@example.com\nSubject: Saying Hello\n\nbody\n",
+			redaction: Redaction{Drop: []string{"To"}, Hash: []string{"Cc"}},
+			leaked:    []string{"bob@example.com", "carol@example.com"},
+		},
+		4: {
+			mailData:  "From: jdoe@machine.example\nX-Spam-Status: Yes, score=9.1 required=5.0 tests=SECRET_RULE\nX-Case-Id: case-4711\nSubject: Saying Hello\n\nbody\n",
+			options:   []Option{WithHeaderParser("X-Case-Id", func(values []string) (interface{}, error) { return values[0], nil })},
+			redaction: Redaction{Drop: []string{"X-Spam-Status", "X-Case-Id"}},
+			leaked:    []string{"SECRET_RULE", "case-4711"},
+		},
+		5: {
+			mailData:  dsn,
+			redaction: Redaction{Drop: []string{"Final-Recipient", "Diagnostic-Code", "Reporting-MTA"}},
+			leaked:    []string{"mary@example.net", "relay.example.org"},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := NewParser(append(td.options, WithRawBody())...).Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		original, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exported, err := json.Marshal(e.Redacted(td.redaction))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, value := range td.leaked {
+			if !bytes.Contains(original, []byte(value)) {
+				t.Errorf("[Test Case %v] %s not exported without redaction: %s", index, value, original)
+			}
+
+			if bytes.Contains(exported, []byte(value)) {
+				t.Errorf("[Test Case %v] Redacted value %s exported: %s", index, value, exported)
+			}
+		}
+
+		if !bytes.Contains(exported, []byte("jdoe@machine.example")) && !bytes.Contains(exported, []byte("Undelivered Mail")) {
+			t.Errorf("[Test Case %v] Unredacted fields not exported: %s", index, exported)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package parsemail
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+)
+
+// base64LineLen is the length of encoded lines, the maximum of RFC 2045 section 6.8
+const base64LineLen = 76
+
+// lineWrapper breaks the text written to it into CRLF terminated lines of base64LineLen
+type lineWrapper struct {
+	w   io.Writer
+	col int
+}
+
+func (lw *lineWrapper) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := base64LineLen - lw.col
+		if n > len(p) {
+			n = len(p)
+		}
+
+		if _, err := lw.w.Write(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		lw.col += n
+		p = p[n:]
+
+		if lw.col == base64LineLen {
+			if _, err := io.WriteString(lw.w, "\r\n"); err != nil {
+				return written, err
+			}
+			lw.col = 0
+		}
+	}
+
+	return written, nil
+}
+
+// close terminates the last line
+func (lw *lineWrapper) close() error {
+	if lw.col == 0 {
+		return nil
+	}
+
+	_, err := io.WriteString(lw.w, "\r\n")
+	return err
+}
+
+// WriteBase64 writes the attachment data to w as canonical base64: padded,
+// in CRLF terminated lines of 76 characters, whatever the encoding it was
+// sent with. Seekable data is read from its start.
+func (a Attachment) WriteBase64(w io.Writer) error {
+	var data io.Reader = a.Data
+	if sr, err := a.SectionReader(); err == nil {
+		data = sr
+	}
+
+	lw := &lineWrapper{w: w}
+	enc := base64.NewEncoder(base64.StdEncoding, lw)
+	if _, err := io.Copy(enc, data); err != nil {
+		return err
+	}
+
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	return lw.close()
+}
+
+// WriteMIMEPart writes the attachment as a new base64 encoded part of mw, with
+// normalized Content-Type, Content-Disposition, Content-ID and
+// Content-Transfer-Encoding headers
+func (a Attachment) WriteMIMEPart(mw *multipart.Writer) error {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	disposition := a.Disposition.Type
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	var params map[string]string
+	if a.Filename != "" {
+		params = map[string]string{"filename": a.Filename}
+	}
+
+	h := textproto.MIMEHeader{}
+	h.Set(headerContentType, contentType)
+	h.Set(headerContentDisposition, mime.FormatMediaType(disposition, params))
+	if a.ContentID != "" {
+		h.Set("Content-Id", "<"+a.ContentID+">")
+	}
+	h.Set(headerContentEncoding, encodingBase64)
+
+	w, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	return a.WriteBase64(w)
+}
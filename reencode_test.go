@@ -0,0 +1,92 @@
+package parsemail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestAttachmentWriteBase64(t *testing.T) {
+	var testData = map[int]struct {
+		data  string
+		lines int
+	}{
+		1: {data: "", lines: 0},
+		2: {data: "hello", lines: 1},
+		3: {data: strings.Repeat("x", 57), lines: 1},
+		4: {data: strings.Repeat("x", 58), lines: 2},
+		5: {data: strings.Repeat("café ", 40), lines: 5},
+	}
+
+	for index, td := range testData {
+		for _, a := range []Attachment{
+			{Data: bytes.NewReader([]byte(td.data))},
+			{Data: strings.NewReader(td.data)},
+		} {
+			var buf bytes.Buffer
+			if err := a.WriteBase64(&buf); err != nil {
+				t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+				continue
+			}
+
+			encoded := buf.String()
+			if encoded != "" && !strings.HasSuffix(encoded, "\r\n") {
+				t.Errorf("[Test Case %v] Last line not terminated: %q", index, encoded)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(encoded, "\r\n"), "\r\n")
+			if encoded == "" {
+				lines = nil
+			}
+
+			if len(lines) != td.lines {
+				t.Errorf("[Test Case %v] Wrong number of lines. Expected: %v, Got: %v", index, td.lines, len(lines))
+			}
+
+			for _, line := range lines {
+				if len(line) > 76 {
+					t.Errorf("[Test Case %v] Line too long: %v", index, len(line))
+				}
+			}
+
+			decoded, err := base64.StdEncoding.DecodeString(strings.Join(lines, ""))
+			if err != nil || string(decoded) != td.data {
+				t.Errorf("[Test Case %v] Wrong encoding. Expected: %q, Got: %q (%v)", index, td.data, decoded, err)
+			}
+		}
+	}
+}
+
+func TestAttachmentWriteMIMEPart(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"a.txt\"\nContent-Transfer-Encoding: quoted-printable\n\ncaf=C3=A9\n--m--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := e.Attachments[0].WriteMIMEPart(mw); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mw.Close()
+
+	part, err := multipart.NewReader(&buf, mw.Boundary()).NextRawPart()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if part.Header.Get("Content-Transfer-Encoding") != "base64" || part.Header.Get("Content-Disposition") != "attachment; filename=a.txt" {
+		t.Errorf("Wrong header: %v", part.Header)
+	}
+
+	body, _ := ioutil.ReadAll(part)
+	if string(body) != "Y2Fmw6k=\r\n" {
+		t.Errorf("Wrong body. Expected: %q, Got: %q", "Y2Fmw6k=\r\n", body)
+	}
+}
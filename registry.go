@@ -0,0 +1,18 @@
+package parsemail
+
+// registry holds the registrations of a Parser, see WithCharsetDecoder and the
+// other With options named like the package level Register funcs. They take
+// precedence over the package level registrations, which keep applying to
+// every name the Parser doesn't register itself. Removals are recorded as nil
+// or empty entries, hiding the package level registration of the name.
+type registry struct {
+	charsetDecoders       map[string]CharsetDecoderFunc
+	decompressors         map[string]DecompressorFunc
+	dateLayouts           []string
+	headerParsers         map[string]registeredHeaderParser
+	contentExtractors     map[string]ContentExtractor
+	extensionTypes        map[string]string
+	typeExtensions        map[string]string
+	subaddressConventions map[string]SubaddressConvention
+	trackingDomains       map[string]bool
+}
@@ -0,0 +1,115 @@
+package parsemail
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParserRegistrations(t *testing.T) {
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte("Compressed hello."))
+	gw.Close()
+
+	upper := func(data []byte) (string, error) {
+		return strings.ToUpper(string(data)), nil
+	}
+
+	caseID := func(values []string) (interface{}, error) {
+		return strings.TrimSpace(values[0]), nil
+	}
+
+	extract := ContentExtractorFunc(func(r io.Reader, contentType string) (string, error) {
+		data, err := ioutil.ReadAll(r)
+		return "extracted " + string(data), err
+	})
+
+	var testData = map[int]struct {
+		mailData      string
+		options       []Option
+		registrations []Option
+		check         func(Email) bool
+	}{
+		1: {
+			mailData:      "From: jdoe@machine.example\nContent-Type: text/html; charset=x-shout\n\n<p>hi</p>\n",
+			options:       []Option{WithHTMLCharsetPrecedence(PreferMIMECharset)},
+			registrations: []Option{WithCharsetDecoder("X-Shout", upper)},
+			check:         func(e Email) bool { return e.HTMLBody == "<P>HI</P>" },
+		},
+		2: {
+			mailData: "From: jdoe@machine.example\nContent-Type: text/plain\nContent-Transfer-Encoding: base64\nContent-Encoding: gzip\n\n" +
+				base64.StdEncoding.EncodeToString(gz.Bytes()) + "\n",
+			registrations: []Option{WithDecompressor("gzip", nil)},
+			check:         func(e Email) bool { return e.TextBody == gz.String() },
+		},
+		3: {
+			mailData:      "From: jdoe@machine.example\nDate: 2024-05-01 10:00\n\nbody\n",
+			registrations: []Option{WithDateLayout("2006-01-02 15:04")},
+			check:         func(e Email) bool { return e.Date.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) },
+		},
+		4: {
+			mailData:      "From: jdoe@machine.example\nX-Case-Id: 4711\n\nbody\n",
+			registrations: []Option{WithHeaderParser("X-Case-ID", caseID)},
+			check:         func(e Email) bool { return e.Extensions["X-Case-ID"] == "4711" },
+		},
+		5: {
+			mailData: "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n--m\nContent-Type: text/plain\n\nbody\n" +
+				"--m\nContent-Type: application/octet-stream\nContent-Disposition: attachment; filename=\"notes.foo\"\n\nnotes\n--m--\n",
+			registrations: []Option{WithContentExtractor("application/*", extract), WithExtension(".foo", "application/x-foo")},
+			check: func(e Email) bool {
+				a := e.Attachments[0]
+				return a.Text == "extracted notes" && a.SuggestedContentType == "application/x-foo"
+			},
+		},
+		6: {
+			mailData:      "From: jdoe@machine.example\nContent-Type: text/html\n\n<img src=\"https://pixel.track.example/o.gif\">\n",
+			registrations: []Option{WithTrackingDomain("track.example")},
+			check: func(e Email) bool {
+				trackers := e.Trackers()
+				return len(trackers) == 1 && trackers[0].Reasons[0] == "Known tracking domain track.example"
+			},
+		},
+	}
+
+	for index, td := range testData {
+		e, err := NewParser(append(td.options, td.registrations...)...).Parse(strings.NewReader(td.mailData))
+		if err != nil {
+			t.Errorf("[Test Case %v] Unexpected error: %v", index, err)
+			continue
+		}
+
+		if !td.check(e) {
+			t.Errorf("[Test Case %v] Registration not applied: %+v", index, e)
+		}
+
+		if e, err = ParseWithOptions(strings.NewReader(td.mailData), td.options...); err == nil && td.check(e) {
+			t.Errorf("[Test Case %v] Registration applied to the package level parse", index)
+		}
+	}
+}
+
+func TestParserSubaddressConvention(t *testing.T) {
+	p := NewParser(WithSubaddressConvention("example.com", SubaddressConvention{Separators: "-", FoldCase: true}))
+
+	if base, tag := p.SplitSubaddress("Jane-News@Example.com"); base != "jane@example.com" || tag != "News" {
+		t.Errorf("Wrong split: %s, %s", base, tag)
+	}
+
+	if base, tag := SplitSubaddress("Jane-News@Example.com"); base != "Jane-News@example.com" || tag != "" {
+		t.Errorf("Parser convention applied to SplitSubaddress: %s, %s", base, tag)
+	}
+
+	if normalized := p.NormalizeAddress("Jane@Example.com", true); normalized != "jane@example.com" {
+		t.Errorf("Wrong normalized address: %s", normalized)
+	}
+
+	if p = NewParser(WithSubaddressConvention("gmail.com", SubaddressConvention{})); p.NormalizeAddress("J.Doe@gmail.com", true) != "J.Doe@gmail.com" {
+		t.Errorf("Removed convention still applied: %s", p.NormalizeAddress("J.Doe@gmail.com", true))
+	}
+}
@@ -0,0 +1,62 @@
+package parsemail
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// ResentInfo holds the Resent- fields added by one resend of the message
+type ResentInfo struct {
+	From      []*mail.Address
+	Sender    *mail.Address
+	To        []*mail.Address
+	Cc        []*mail.Address
+	Bcc       []*mail.Address
+	Date      time.Time
+	MessageID string
+}
+
+// parseResentBlocks groups the Resent- fields into one ResentInfo per resend, in
+// header order, so the most recent resend comes first. A block is a run of
+// adjacent Resent- fields, a field repeating within a run starts a new block.
+func (hp headerParser) parseResentBlocks(fields []headerField) (blocks []ResentInfo) {
+	var block mail.Header
+	flush := func() {
+		if block != nil {
+			blocks = append(blocks, hp.parseResentBlock(block))
+			block = nil
+		}
+	}
+
+	for _, f := range fields {
+		if !strings.HasPrefix(f.Name, "Resent-") {
+			flush()
+			continue
+		}
+
+		if _, repeated := block[f.Name]; repeated {
+			flush()
+		}
+
+		if block == nil {
+			block = mail.Header{}
+		}
+		block[f.Name] = append(block[f.Name], f.Value)
+	}
+	flush()
+
+	return
+}
+
+func (hp headerParser) parseResentBlock(block mail.Header) (ri ResentInfo) {
+	ri.From = hp.parseAddressList(block.Get("Resent-From"))
+	ri.Sender = hp.parseAddress(block.Get("Resent-Sender"))
+	ri.To = hp.parseAddressList(block.Get("Resent-To"))
+	ri.Cc = hp.parseAddressList(block.Get("Resent-Cc"))
+	ri.Bcc = hp.parseAddressList(block.Get("Resent-Bcc"))
+	ri.Date = hp.parseTime(block.Get("Resent-Date"))
+	ri.MessageID = hp.parseMessageId(block.Get("Resent-Message-ID"))
+
+	return
+}
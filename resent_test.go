@@ -0,0 +1,81 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResentBlocks(t *testing.T) {
+	message := `Resent-From: Jane Brown <j-brown@other.example>
+Resent-To: Boss <boss@other.example>
+Resent-Date: Tue, 25 Nov 1997 08:00:00 -0800
+Resent-Message-ID: <111213@other.example>
+Received: from example.net by other.example; 24 Nov 1997 14:22:05 -0800
+Resent-From: Mary Smith <mary@example.net>
+Resent-To: Jane Brown <j-brown@other.example>
+Resent-Date: Mon, 24 Nov 1997 14:22:01 -0800
+Resent-Message-ID: <78910@example.net>
+Resent-Cc: <archive@example.net>
+Resent-Cc: <second@example.net>
+From: John Doe <jdoe@machine.example>
+To: Mary Smith <mary@example.net>
+Subject: Saying Hello
+Date: Fri, 21 Nov 1997 09:55:06 -0600
+Message-ID: <1234@local.machine.example>
+
+This is a message just to say hello.
+`
+
+	e, err := Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		from      string
+		to        []string
+		cc        []string
+		messageID string
+		date      string
+	}{
+		{"j-brown@other.example", []string{"boss@other.example"}, nil, "111213@other.example", "Tue, 25 Nov 1997 08:00:00 -0800"},
+		{"mary@example.net", []string{"j-brown@other.example"}, []string{"archive@example.net"}, "78910@example.net", "Mon, 24 Nov 1997 14:22:01 -0800"},
+		{"", nil, []string{"second@example.net"}, "", ""},
+	}
+
+	if len(e.ResentBlocks) != len(expected) {
+		t.Fatalf("Wrong number of resent blocks. Expected: %v, Got: %v", len(expected), len(e.ResentBlocks))
+	}
+
+	for i, exp := range expected {
+		rb := e.ResentBlocks[i]
+
+		from := ""
+		if len(rb.From) > 0 {
+			from = rb.From[0].Address
+		}
+		if from != exp.from {
+			t.Errorf("[Block %v] Wrong from. Expected: %s, Got: %s", i, exp.from, from)
+		}
+
+		var to, cc []string
+		for _, a := range rb.To {
+			to = append(to, a.Address)
+		}
+		for _, a := range rb.Cc {
+			cc = append(cc, a.Address)
+		}
+
+		if !assertSliceEq(exp.to, to) || !assertSliceEq(exp.cc, cc) {
+			t.Errorf("[Block %v] Wrong recipients. Expected: %v %v, Got: %v %v", i, exp.to, exp.cc, to, cc)
+		}
+
+		if rb.MessageID != exp.messageID {
+			t.Errorf("[Block %v] Wrong message id. Expected: %s, Got: %s", i, exp.messageID, rb.MessageID)
+		}
+
+		if exp.date != "" && !parseDate(exp.date).Equal(rb.Date) {
+			t.Errorf("[Block %v] Wrong date. Expected: %s, Got: %v", i, exp.date, rb.Date)
+		}
+	}
+}
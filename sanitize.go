@@ -0,0 +1,184 @@
+package parsemail
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sanitizeRemovedElements are dropped from sanitized HTML along with their content
+var sanitizeRemovedElements = map[string]bool{
+	"script": true, "noscript": true, "iframe": true, "frame": true, "frameset": true, "object": true,
+	"embed": true, "applet": true, "base": true, "link": true, "meta": true, "template": true,
+	// raw text is rendered unescaped, and browsers ignore these tags in places like <select>
+	"xmp": true, "noembed": true, "noframes": true, "plaintext": true,
+	// foreign content parses tags inside <style>, which this parser keeps as raw text
+	"svg": true, "math": true,
+}
+
+// sanitizeURLAttributes hold URLs that are checked for script schemes
+var sanitizeURLAttributes = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true, "background": true, "poster": true,
+	"lowsrc": true, "dynsrc": true, "xlink:href": true, "cite": true, "longdesc": true, "data": true,
+}
+
+var (
+	cssCommentPattern   = regexp.MustCompile(`/\*[\s\S]*?\*/`)
+	cssEscapePattern    = regexp.MustCompile(`\\([0-9a-fA-F]{1,6})\s?|\\(.)`)
+	cssImportPattern    = regexp.MustCompile(`(?i)@import[^;]*;?`)
+	cssDangerousPattern = regexp.MustCompile(`(?i)expression\s*\(|behavior\s*:|-moz-binding|javascript:|vbscript:|url\s*\(\s*['"]?\s*data:(?:text|application)/`)
+)
+
+// SanitizeHTML returns a render safe version of the HTML in s for display of
+// untrusted messages: scripts, frames, plugins, SVG and MathML, the xmp,
+// noembed, noframes and plaintext raw text elements and the document base are
+// removed with their content, event handler attributes and javascript: or
+// vbscript: URLs are dropped, form actions pointing to other sites are removed
+// and CSS that can run code or load stylesheets is stripped.
+func SanitizeHTML(s string) string {
+	doc := ParseHTML(s)
+	doc.Walk(func(n *HTMLNode) bool {
+		if n.Type == HTMLCommentNode {
+			n.Parent.RemoveChild(n)
+			return false
+		}
+
+		if n.Type != HTMLElementNode {
+			return true
+		}
+
+		if sanitizeRemovedElements[n.Data] {
+			n.Parent.RemoveChild(n)
+			return false
+		}
+
+		if n.Data == "style" {
+			var css string
+			for n.FirstChild != nil {
+				css += n.FirstChild.Data
+				n.RemoveChild(n.FirstChild)
+			}
+
+			if css = sanitizeStylesheet(css); css == "" {
+				n.Parent.RemoveChild(n)
+			} else {
+				n.AppendChild(&HTMLNode{Type: HTMLTextNode, Data: css})
+			}
+			return false
+		}
+
+		sanitizeAttributes(n)
+
+		return true
+	})
+
+	return doc.String()
+}
+
+func sanitizeAttributes(n *HTMLNode) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		switch {
+		case strings.HasPrefix(a.Key, "on"), a.Key == "srcdoc":
+			continue
+		case a.Key == "style":
+			if a.Val = sanitizeDeclarations(a.Val); a.Val == "" {
+				continue
+			}
+		case a.Key == "action" || a.Key == "formaction":
+			if isExternalURL(a.Val) || isScriptURL(a.Val) {
+				continue
+			}
+		case sanitizeURLAttributes[a.Key]:
+			if isScriptURL(a.Val) || (isDataURL(a.Val) && !(n.Data == "img" && a.Key == "src" && isImageDataURL(a.Val))) {
+				continue
+			}
+		}
+
+		attrs = append(attrs, a)
+	}
+
+	n.Attr = attrs
+}
+
+// sanitizeStylesheet removes @import rules from css, and all of it if it still
+// contains dangerous constructs. Left angle brackets are escaped.
+func sanitizeStylesheet(css string) string {
+	css = cssImportPattern.ReplaceAllString(cssCommentPattern.ReplaceAllString(css, ""), "")
+	if cssDangerousPattern.MatchString(unescapeCSS(css)) {
+		return ""
+	}
+
+	// < has no meaning in CSS outside of strings, where the escape keeps it;
+	// it could only end up starting markup in a parser disagreeing about the style element
+	return strings.Replace(css, "<", `\3c `, -1)
+}
+
+// sanitizeDeclarations removes the dangerous declarations of an inline style
+func sanitizeDeclarations(style string) string {
+	var kept []string
+	for _, d := range strings.Split(cssCommentPattern.ReplaceAllString(style, ""), ";") {
+		if strings.TrimSpace(d) == "" || cssDangerousPattern.MatchString(unescapeCSS(d)) {
+			continue
+		}
+
+		kept = append(kept, strings.TrimSpace(d))
+	}
+
+	return strings.Join(kept, "; ")
+}
+
+// unescapeCSS resolves CSS backslash escapes, which can be used to hide keywords
+func unescapeCSS(s string) string {
+	return cssEscapePattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := cssEscapePattern.FindStringSubmatch(m)
+		if sub[1] == "" {
+			return sub[2]
+		}
+
+		r, err := strconv.ParseUint(sub[1], 16, 32)
+		if err != nil {
+			return ""
+		}
+
+		return string(rune(r))
+	})
+}
+
+// urlScheme returns the lower case scheme of u, ignoring the white space and
+// control characters browsers skip
+func urlScheme(u string) string {
+	u = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, u)
+
+	i := strings.IndexByte(u, ':')
+	if i == -1 || strings.ContainsAny(u[:i], "/?#") {
+		return ""
+	}
+
+	return strings.ToLower(u[:i])
+}
+
+func isScriptURL(u string) bool {
+	scheme := urlScheme(u)
+	return scheme == "javascript" || scheme == "vbscript" || scheme == "livescript"
+}
+
+func isDataURL(u string) bool {
+	return urlScheme(u) == "data"
+}
+
+func isImageDataURL(u string) bool {
+	u = strings.ToLower(strings.TrimSpace(u))
+	return strings.HasPrefix(u, "data:image/") && !strings.HasPrefix(u, "data:image/svg")
+}
+
+// isExternalURL reports whether u is absolute or protocol relative, so it points to another site
+func isExternalURL(u string) bool {
+	u = strings.TrimSpace(u)
+	return strings.HasPrefix(u, "//") || strings.HasPrefix(u, `\\`) || urlScheme(u) != ""
+}
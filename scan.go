@@ -0,0 +1,101 @@
+package parsemail
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// Verdict is the result of scanning an attachment, like with an antivirus or a
+// malware sandbox. Scanner names the scanner and Threat, for malicious
+// attachments, what was found.
+type Verdict struct {
+	Scanner   string
+	Malicious bool
+	Threat    string
+}
+
+// Scanner scans attachments. Scan is called with a reader streaming the
+// decoded data of an attachment while it is parsed and the attachment, whose
+// Data isn't set yet, for its metadata. Scanners run concurrently and the
+// parse waits for all of them before moving on to the next part.
+type Scanner interface {
+	Scan(r io.Reader, a Attachment) (Verdict, error)
+}
+
+// ScannerFunc adapts a function to the Scanner interface
+type ScannerFunc func(r io.Reader, a Attachment) (Verdict, error)
+
+// Scan calls fn(r, a)
+func (fn ScannerFunc) Scan(r io.Reader, a Attachment) (Verdict, error) {
+	return fn(r, a)
+}
+
+type scanResult struct {
+	verdict Verdict
+	err     error
+}
+
+// scanSession streams the data written to it to running scanners
+type scanSession struct {
+	pipes   []*io.PipeWriter
+	results []scanResult
+	wg      sync.WaitGroup
+}
+
+// startScans starts scanners on the attachment at, it returns nil without scanners
+func startScans(scanners []Scanner, at Attachment) *scanSession {
+	if len(scanners) == 0 {
+		return nil
+	}
+
+	s := &scanSession{results: make([]scanResult, len(scanners))}
+	for i, sc := range scanners {
+		pr, pw := io.Pipe()
+		s.pipes = append(s.pipes, pw)
+		s.wg.Add(1)
+
+		go func(i int, sc Scanner) {
+			defer s.wg.Done()
+
+			verdict, err := sc.Scan(pr, at)
+			// scanners may decide before reading everything, the rest is discarded so writes don't block
+			io.Copy(ioutil.Discard, pr)
+			s.results[i] = scanResult{verdict: verdict, err: err}
+		}(i, sc)
+	}
+
+	return s
+}
+
+func (s *scanSession) Write(p []byte) (int, error) {
+	for _, pw := range s.pipes {
+		pw.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// finish ends the data the scanners read, with decodeErr when decoding failed,
+// waits for them and records their verdicts on at. Failed scans are reported
+// in e.Warnings unless decoding failed.
+func (s *scanSession) finish(e *Email, at *Attachment, decodeErr error) {
+	for _, pw := range s.pipes {
+		pw.CloseWithError(decodeErr)
+	}
+	s.wg.Wait()
+
+	if decodeErr != nil {
+		return
+	}
+
+	for _, r := range s.results {
+		if r.err != nil {
+			e.Warnings = append(e.Warnings, fmt.Sprintf("Failed to scan %s: %v", at.Filename, r.err))
+			continue
+		}
+
+		at.Verdicts = append(at.Verdicts, r.verdict)
+	}
+}
@@ -0,0 +1,70 @@
+package parsemail
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"evil.txt\"\nContent-Transfer-Encoding: base64\n\nc29tZSBFVklMIGRhdGE=\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"fine.txt\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	signature := ScannerFunc(func(r io.Reader, a Attachment) (Verdict, error) {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return Verdict{}, err
+		}
+
+		if bytes.Contains(data, []byte("EVIL")) {
+			return Verdict{Scanner: "signature", Malicious: true, Threat: "Test.Evil"}, nil
+		}
+
+		return Verdict{Scanner: "signature"}, nil
+	})
+
+	// decides by the filename without reading the data
+	byName := ScannerFunc(func(r io.Reader, a Attachment) (Verdict, error) {
+		return Verdict{Scanner: "name", Malicious: strings.HasPrefix(a.Filename, "evil")}, nil
+	})
+
+	unavailable := ScannerFunc(func(r io.Reader, a Attachment) (Verdict, error) {
+		return Verdict{}, errors.New("Service unavailable")
+	})
+
+	for _, opts := range [][]Option{
+		{WithScanner(signature), WithScanner(byName), WithScanner(unavailable)},
+		{WithScanner(signature), WithScanner(byName), WithScanner(unavailable), WithAttachmentStreaming(func(a Attachment) error { return nil })},
+	} {
+		e, err := ParseWithOptions(strings.NewReader(mailData), opts...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := [][]Verdict{
+			{{Scanner: "signature", Malicious: true, Threat: "Test.Evil"}, {Scanner: "name", Malicious: true}},
+			{{Scanner: "signature"}, {Scanner: "name"}},
+		}
+
+		for i, a := range e.Attachments {
+			if len(a.Verdicts) != len(expected[i]) {
+				t.Errorf("Wrong verdicts of %s. Expected: %v, Got: %v", a.Filename, expected[i], a.Verdicts)
+				continue
+			}
+
+			for j, v := range a.Verdicts {
+				if v != expected[i][j] {
+					t.Errorf("Wrong verdict of %s. Expected: %v, Got: %v", a.Filename, expected[i][j], v)
+				}
+			}
+		}
+
+		if len(e.Warnings) != 2 || !strings.HasPrefix(e.Warnings[0], "Failed to scan evil.txt") {
+			t.Errorf("Expected warnings about the failed scans, got: %v", e.Warnings)
+		}
+	}
+}
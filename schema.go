@@ -0,0 +1,325 @@
+package parsemail
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaItem is a schema.org item embedded in a HTML body as JSON-LD or
+// microdata. Type is the type name without the schema.org prefix and
+// Properties holds the property values as decoded from JSON: strings,
+// numbers, booleans, nested items as maps with an "@type" key and slices
+// for repeated properties.
+type SchemaItem struct {
+	Type       string
+	Properties map[string]interface{}
+}
+
+// FlightReservation is a schema.org FlightReservation
+type FlightReservation struct {
+	ReservationNumber string
+	Status            string
+	PassengerName     string
+	Airline           string
+	FlightNumber      string
+	DepartureAirport  string
+	ArrivalAirport    string
+	DepartureTime     time.Time
+	ArrivalTime       time.Time
+}
+
+// ParcelDelivery is a schema.org ParcelDelivery
+type ParcelDelivery struct {
+	TrackingNumber  string
+	TrackingURL     string
+	Carrier         string
+	Status          string
+	OrderNumber     string
+	ExpectedArrival time.Time
+}
+
+// Order is a schema.org Order
+type Order struct {
+	OrderNumber   string
+	Status        string
+	Merchant      string
+	Price         string
+	PriceCurrency string
+	URL           string
+}
+
+// StructuredData holds the schema.org items of a HTML body, with the
+// reservations, deliveries and orders among them converted to typed structs
+type StructuredData struct {
+	Items []SchemaItem
+
+	FlightReservations []FlightReservation
+	ParcelDeliveries   []ParcelDelivery
+	Orders             []Order
+}
+
+// schemaTimeLayouts are the ISO 8601 forms found in structured data, in order of preference
+var schemaTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+// ParseStructuredData returns the schema.org items embedded in html as JSON-LD
+// scripts or microdata. Malformed JSON-LD is skipped.
+func ParseStructuredData(html string) StructuredData {
+	var items []map[string]interface{}
+	ParseHTML(html).Walk(func(n *HTMLNode) bool {
+		if n.Type != HTMLElementNode {
+			return true
+		}
+
+		if typ, _ := n.Attribute("type"); n.Data == "script" && strings.EqualFold(strings.TrimSpace(typ), "application/ld+json") {
+			var v interface{}
+			if n.FirstChild != nil && json.Unmarshal([]byte(n.FirstChild.Data), &v) == nil {
+				items = appendJSONLDItems(items, v)
+			}
+			return false
+		}
+
+		if _, ok := n.Attribute("itemscope"); ok {
+			if _, prop := n.Attribute("itemprop"); !prop {
+				items = append(items, parseMicrodataItem(n))
+				return false
+			}
+		}
+
+		return true
+	})
+
+	var sd StructuredData
+	for _, m := range items {
+		item := SchemaItem{Type: schemaType(m), Properties: m}
+		sd.Items = append(sd.Items, item)
+
+		switch item.Type {
+		case "FlightReservation":
+			sd.FlightReservations = append(sd.FlightReservations, newFlightReservation(m))
+		case "ParcelDelivery":
+			sd.ParcelDeliveries = append(sd.ParcelDeliveries, newParcelDelivery(m))
+		case "Order":
+			sd.Orders = append(sd.Orders, newOrder(m))
+		}
+	}
+
+	return sd
+}
+
+// StructuredData returns the schema.org items of the HTML body, see
+// ParseStructuredData. JSON-LD is lost when the body was sanitized.
+func (e Email) StructuredData() StructuredData {
+	return ParseStructuredData(e.HTMLBody)
+}
+
+// appendJSONLDItems appends the items of a decoded JSON-LD document, which may be an item, a list or a @graph
+func appendJSONLDItems(items []map[string]interface{}, v interface{}) []map[string]interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			items = appendJSONLDItems(items, e)
+		}
+	case map[string]interface{}:
+		if graph, ok := v["@graph"]; ok {
+			return appendJSONLDItems(items, graph)
+		}
+
+		if _, ok := v["@type"]; ok {
+			items = append(items, v)
+		}
+	}
+
+	return items
+}
+
+// parseMicrodataItem converts the itemscope element n and its itemprop descendants into the JSON-LD representation
+func parseMicrodataItem(n *HTMLNode) map[string]interface{} {
+	item := map[string]interface{}{}
+	if typ, ok := n.Attribute("itemtype"); ok {
+		item["@type"] = strings.TrimSpace(typ)
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		c.Walk(func(d *HTMLNode) bool {
+			if d.Type != HTMLElementNode {
+				return true
+			}
+
+			_, scope := d.Attribute("itemscope")
+			if names, ok := d.Attribute("itemprop"); ok {
+				var value interface{}
+				if scope {
+					value = parseMicrodataItem(d)
+				} else {
+					value = microdataValue(d)
+				}
+
+				for _, name := range strings.Fields(names) {
+					addSchemaProperty(item, name, value)
+				}
+			}
+
+			// properties of nested items belong to them
+			return !scope
+		})
+	}
+
+	return item
+}
+
+func addSchemaProperty(item map[string]interface{}, name string, value interface{}) {
+	switch existing := item[name].(type) {
+	case nil:
+		item[name] = value
+	case []interface{}:
+		item[name] = append(existing, value)
+	default:
+		item[name] = []interface{}{existing, value}
+	}
+}
+
+// microdataValue returns the property value of the element n (HTML spec section 5.4)
+func microdataValue(n *HTMLNode) string {
+	attr := ""
+	switch n.Data {
+	case "meta":
+		attr = "content"
+	case "a", "area", "link":
+		attr = "href"
+	case "img", "audio", "embed", "iframe", "source", "track", "video":
+		attr = "src"
+	case "object":
+		attr = "data"
+	case "data", "meter":
+		attr = "value"
+	case "time":
+		if v, ok := n.Attribute("datetime"); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+
+	if attr != "" {
+		v, _ := n.Attribute(attr)
+		return strings.TrimSpace(v)
+	}
+
+	return strings.Join(strings.Fields(n.Text()), " ")
+}
+
+// schemaType returns the first type of an item without the schema.org prefix
+func schemaType(m map[string]interface{}) string {
+	t := schemaString(m["@type"])
+	if i := strings.LastIndexAny(t, "/#"); i != -1 {
+		t = t[i+1:]
+	}
+
+	return t
+}
+
+// schemaString returns a property value as a string: enumeration URLs are
+// shortened to their last segment, items to their name and lists to their first element
+func schemaString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		if strings.Contains(s, "schema.org/") {
+			s = s[strings.LastIndex(s, "/")+1:]
+		}
+		return s
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		if len(v) > 0 {
+			return schemaString(v[0])
+		}
+	case map[string]interface{}:
+		return schemaString(v["name"])
+	}
+
+	return ""
+}
+
+// schemaProperty returns the value at the path of nested property names
+func schemaProperty(m map[string]interface{}, path ...string) interface{} {
+	var v interface{} = m
+	for _, name := range path {
+		if list, ok := v.([]interface{}); ok && len(list) > 0 {
+			v = list[0]
+		}
+
+		item, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = item[name]
+	}
+
+	return v
+}
+
+func schemaTime(v interface{}) time.Time {
+	s := schemaString(v)
+	for _, layout := range schemaTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// firstSchemaString returns the first non-empty string of the properties at paths
+func firstSchemaString(m map[string]interface{}, paths ...[]string) string {
+	for _, path := range paths {
+		if s := schemaString(schemaProperty(m, path...)); s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
+
+func newFlightReservation(m map[string]interface{}) FlightReservation {
+	return FlightReservation{
+		ReservationNumber: schemaString(m["reservationNumber"]),
+		Status:            schemaString(m["reservationStatus"]),
+		PassengerName:     schemaString(m["underName"]),
+		Airline:           firstSchemaString(m, []string{"reservationFor", "airline", "iataCode"}, []string{"reservationFor", "airline"}),
+		FlightNumber:      schemaString(schemaProperty(m, "reservationFor", "flightNumber")),
+		DepartureAirport:  firstSchemaString(m, []string{"reservationFor", "departureAirport", "iataCode"}, []string{"reservationFor", "departureAirport"}),
+		ArrivalAirport:    firstSchemaString(m, []string{"reservationFor", "arrivalAirport", "iataCode"}, []string{"reservationFor", "arrivalAirport"}),
+		DepartureTime:     schemaTime(schemaProperty(m, "reservationFor", "departureTime")),
+		ArrivalTime:       schemaTime(schemaProperty(m, "reservationFor", "arrivalTime")),
+	}
+}
+
+func newParcelDelivery(m map[string]interface{}) ParcelDelivery {
+	expected := schemaTime(m["expectedArrivalUntil"])
+	if expected.IsZero() {
+		expected = schemaTime(m["expectedArrivalFrom"])
+	}
+
+	return ParcelDelivery{
+		TrackingNumber:  schemaString(m["trackingNumber"]),
+		TrackingURL:     schemaString(m["trackingUrl"]),
+		Carrier:         firstSchemaString(m, []string{"carrier"}, []string{"provider"}),
+		Status:          firstSchemaString(m, []string{"deliveryStatus", "name"}, []string{"deliveryStatus"}),
+		OrderNumber:     schemaString(schemaProperty(m, "partOfOrder", "orderNumber")),
+		ExpectedArrival: expected,
+	}
+}
+
+func newOrder(m map[string]interface{}) Order {
+	return Order{
+		OrderNumber:   schemaString(m["orderNumber"]),
+		Status:        schemaString(m["orderStatus"]),
+		Merchant:      firstSchemaString(m, []string{"merchant"}, []string{"seller"}),
+		Price:         firstSchemaString(m, []string{"price"}, []string{"totalPaymentDue", "price"}, []string{"acceptedOffer", "price"}),
+		PriceCurrency: firstSchemaString(m, []string{"priceCurrency"}, []string{"totalPaymentDue", "priceCurrency"}, []string{"acceptedOffer", "priceCurrency"}),
+		URL:           schemaString(m["url"]),
+	}
+}
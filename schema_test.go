@@ -0,0 +1,103 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStructuredData(t *testing.T) {
+	html := `<html><head><script type="application/ld+json">
+[{
+  "@context": "http://schema.org",
+  "@type": "FlightReservation",
+  "reservationNumber": "RXJ34P",
+  "reservationStatus": "http://schema.org/ReservationConfirmed",
+  "underName": {"@type": "Person", "name": "Eva Green"},
+  "reservationFor": {
+    "@type": "Flight",
+    "flightNumber": "110",
+    "airline": {"@type": "Airline", "name": "United", "iataCode": "UA"},
+    "departureAirport": {"@type": "Airport", "name": "San Francisco Airport", "iataCode": "SFO"},
+    "departureTime": "2027-03-04T20:15:00-08:00",
+    "arrivalAirport": {"@type": "Airport", "name": "John F. Kennedy International Airport", "iataCode": "JFK"},
+    "arrivalTime": "2027-03-05T06:30:00-05:00"
+  }
+}, {
+  "@context": "http://schema.org",
+  "@type": "Order",
+  "merchant": {"@type": "Organization", "name": "Amazon.com"},
+  "orderNumber": "123-4567890-1234567",
+  "orderStatus": "http://schema.org/OrderProcessing",
+  "priceCurrency": "USD",
+  "price": "29.99",
+  "url": "https://www.amazon.com/orders/123"
+}]
+</script><script type="application/ld+json">{ broken</script></head><body>
+<div itemscope itemtype="http://schema.org/ParcelDelivery">
+  <div itemprop="carrier" itemscope itemtype="http://schema.org/Organization"><meta itemprop="name" content="FedEx"></div>
+  <span itemprop="trackingNumber">3453291231</span>
+  <a itemprop="trackingUrl" href="https://fedex.com/track/3453291231">Track</a>
+  <time itemprop="expectedArrivalUntil" datetime="2027-03-12T12:00:00-08:00">March 12</time>
+  <div itemprop="partOfOrder" itemscope itemtype="http://schema.org/Order"><meta itemprop="orderNumber" content="176057"></div>
+</div></body></html>`
+
+	sd := ParseStructuredData(html)
+	if len(sd.Items) != 3 {
+		t.Fatalf("Wrong number of items: %+v", sd.Items)
+	}
+
+	types := []string{sd.Items[0].Type, sd.Items[1].Type, sd.Items[2].Type}
+	if !assertSliceEq([]string{"FlightReservation", "Order", "ParcelDelivery"}, types) {
+		t.Errorf("Wrong item types: %v", types)
+	}
+
+	if len(sd.FlightReservations) != 1 || len(sd.Orders) != 1 || len(sd.ParcelDeliveries) != 1 {
+		t.Fatalf("Wrong typed items: %+v", sd)
+	}
+
+	flight := FlightReservation{
+		ReservationNumber: "RXJ34P",
+		Status:            "ReservationConfirmed",
+		PassengerName:     "Eva Green",
+		Airline:           "UA",
+		FlightNumber:      "110",
+		DepartureAirport:  "SFO",
+		ArrivalAirport:    "JFK",
+	}
+	got := sd.FlightReservations[0]
+	departure, arrival := got.DepartureTime, got.ArrivalTime
+	got.DepartureTime, got.ArrivalTime = time.Time{}, time.Time{}
+	if got != flight {
+		t.Errorf("Wrong flight reservation. Expected: %+v, Got: %+v", flight, got)
+	}
+
+	if !departure.Equal(time.Date(2027, 3, 5, 4, 15, 0, 0, time.UTC)) || !arrival.Equal(time.Date(2027, 3, 5, 11, 30, 0, 0, time.UTC)) {
+		t.Errorf("Wrong flight times: %v, %v", departure, arrival)
+	}
+
+	order := Order{OrderNumber: "123-4567890-1234567", Status: "OrderProcessing", Merchant: "Amazon.com", Price: "29.99", PriceCurrency: "USD", URL: "https://www.amazon.com/orders/123"}
+	if sd.Orders[0] != order {
+		t.Errorf("Wrong order. Expected: %+v, Got: %+v", order, sd.Orders[0])
+	}
+
+	parcel := sd.ParcelDeliveries[0]
+	if parcel.Carrier != "FedEx" || parcel.TrackingNumber != "3453291231" || parcel.TrackingURL != "https://fedex.com/track/3453291231" ||
+		parcel.OrderNumber != "176057" || !parcel.ExpectedArrival.Equal(time.Date(2027, 3, 12, 20, 0, 0, 0, time.UTC)) {
+		t.Errorf("Wrong parcel delivery: %+v", parcel)
+	}
+}
+
+func TestEmailStructuredData(t *testing.T) {
+	mailData := "From: orders@example.com\nContent-Type: text/html\n\n" +
+		`<script type="application/ld+json">{"@type": "Order", "orderNumber": "42"}</script><p>Thanks!</p>` + "\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if orders := e.StructuredData().Orders; len(orders) != 1 || orders[0].OrderNumber != "42" {
+		t.Errorf("Wrong orders: %+v", orders)
+	}
+}
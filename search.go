@@ -0,0 +1,91 @@
+package parsemail
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// SearchDocument is a flattened view of an email for full-text search
+// engines. It holds only the decoded content people search for, leaving out
+// transport and authentication headers.
+type SearchDocument struct {
+	MessageID string
+	Date      time.Time
+	Subject   string
+
+	// Participants are the senders and recipients without duplicates, formatted as "Name <address>"
+	Participants []string
+
+	// Text is the visible text of the body, without quoted replies, see VisibleText
+	Text string
+
+	// Attachments holds the file names of the attachments
+	Attachments []string
+
+	// AttachmentText holds the text extracted from attachments, see RegisterContentExtractor
+	AttachmentText []string
+}
+
+// SearchDocument returns the search document of the email
+func (e Email) SearchDocument() SearchDocument {
+	d := SearchDocument{
+		MessageID: e.MessageID,
+		Date:      e.Date,
+		Subject:   strings.TrimSpace(e.Subject),
+		Text:      e.VisibleText(),
+	}
+
+	var sender []*mail.Address
+	if e.Sender != nil {
+		sender = []*mail.Address{e.Sender}
+	}
+
+	for _, a := range DedupeAddresses(false, e.From, sender, e.ReplyTo, e.To, e.Cc, e.Bcc) {
+		if a.Name != "" {
+			d.Participants = append(d.Participants, a.Name+" <"+a.Address+">")
+		} else {
+			d.Participants = append(d.Participants, a.Address)
+		}
+	}
+
+	for _, a := range e.Attachments {
+		if a.Filename != "" {
+			d.Attachments = append(d.Attachments, a.Filename)
+		}
+
+		if a.Text != "" {
+			d.AttachmentText = append(d.AttachmentText, a.Text)
+		}
+	}
+
+	return d
+}
+
+// String flattens the document into plain text: the subject, the
+// participants and attachment names on a line each, followed by the text and
+// the text of the attachments
+func (d SearchDocument) String() string {
+	var lines []string
+	if d.Subject != "" {
+		lines = append(lines, d.Subject)
+	}
+
+	if len(d.Participants) > 0 {
+		lines = append(lines, strings.Join(d.Participants, ", "))
+	}
+
+	if len(d.Attachments) > 0 {
+		lines = append(lines, strings.Join(d.Attachments, ", "))
+	}
+
+	if d.Text != "" {
+		lines = append(lines, "", d.Text)
+	}
+
+	for _, text := range d.AttachmentText {
+		lines = append(lines, "", text)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,36 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchDocument(t *testing.T) {
+	mailData := "From: John Doe <jdoe@machine.example>\nTo: Mary Smith <mary@example.net>, jdoe@machine.example\n" +
+		"Cc: boss@example.net\nSubject: =?utf-8?q?Re=3A_Caf=C3=A9?= plans\nMessage-ID: <1234@local.machine.example>\n" +
+		"Received: from x.y.test by example.net; 21 Nov 1997 10:05:43 -0600\n" +
+		"Content-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: multipart/alternative; boundary=b\n\n" +
+		"--b\nContent-Type: text/plain\n\nSee the menu.\n\nOn Mon, Mary wrote:\n> Lunch?\n--b--\n" +
+		"--m\nContent-Type: application/pdf\nContent-Disposition: attachment; filename=menu.pdf\nContent-Transfer-Encoding: base64\n\nJVBERi0=\n--m--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := e.SearchDocument()
+	if d.MessageID != "1234@local.machine.example" || d.Subject != "Re: Café plans" || d.Text != "See the menu." {
+		t.Errorf("Wrong search document: %+v", d)
+	}
+
+	participants := []string{"John Doe <jdoe@machine.example>", "Mary Smith <mary@example.net>", "boss@example.net"}
+	if !assertSliceEq(participants, d.Participants) {
+		t.Errorf("Wrong participants. Expected: %v, Got: %v", participants, d.Participants)
+	}
+
+	expected := "Re: Café plans\nJohn Doe <jdoe@machine.example>, Mary Smith <mary@example.net>, boss@example.net\nmenu.pdf\n\nSee the menu."
+	if d.String() != expected {
+		t.Errorf("Wrong flattened document. Expected: %q, Got: %q", expected, d.String())
+	}
+}
@@ -0,0 +1,128 @@
+package parsemail
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxSignatureLines bounds the signature blocks found by sign-off and footer detection
+const maxSignatureLines = 8
+
+var (
+	// signOffPattern matches a closing line like "Best regards," in common languages
+	signOffPattern = regexp.MustCompile(`(?i)^\s*(?:` +
+		`(?:best|kind|warm|many)?\s*regards|all the best|best wishes|best|cheers|thanks|many thanks|thank you|sincerely|yours(?: truly| sincerely)?|` +
+		`mit freundlichen grüßen|freundliche grüße|viele grüße|beste grüße|lg|` +
+		`cordialement|bien à vous|salutations|` +
+		`saludos(?: cordiales)?|atentamente|un saludo|` +
+		`cordiali saluti|saluti|distinti saluti|` +
+		`met vriendelijke groet(?:en)?|groeten|` +
+		`atenciosamente|abraços|` +
+		`med vänliga hälsningar|vänliga hälsningar|med venlig hilsen|med vennlig hilsen)\s*[,.!]?\s*$`)
+	// mobileSignaturePattern matches the footer added by mobile mail clients
+	mobileSignaturePattern = regexp.MustCompile(`(?i)^\s*(?:sent from my \w+|sent from (?:outlook|mail|yahoo mail) for \w+|get outlook for \w+|von meinem \w+ gesendet|envoyé de mon \w+|enviado desde mi \w+|inviato da(?:l mio)? \w+)`)
+	// contactLinePattern matches the contact details of a vcard like footer
+	contactLinePattern = regexp.MustCompile(`(?i)^\s*(?:` +
+		`(?:tel|phone|mobile|mob|cell|fax|m|t|f|p|e|w|email|e-mail|web|skype)\.?\s*[:|]\s*\S|` +
+		`\+?\(?\d[\d ()./-]{6,}\d\s*$|` +
+		`[^\s@]+@[^\s@]+\.[a-z]{2,}\s*$|` +
+		`(?:https?://|www\.)\S+\s*$)`)
+)
+
+// SplitSignature splits a plain text body into the message and its trailing
+// signature block. The signature starts at the last "-- " delimiter line
+// followed by at most maxSignatureLines lines, a "Sent from my ..." footer, a
+// sign-off like "Best regards," near the end or a final paragraph of contact
+// details. The signature is empty if none is found. Line endings are kept.
+func SplitSignature(text string) (body, signature string) {
+	lines := strings.Split(strings.TrimRight(text, " \t\r\n"), "\n")
+
+	start := signatureStart(lines)
+	if start == -1 {
+		return text, ""
+	}
+
+	return strings.TrimRight(strings.Join(lines[:start], "\n"), " \t\r\n"), strings.Join(lines[start:], "\n")
+}
+
+// signatureStart returns the index of the first signature line, lines may end in "\r"
+func signatureStart(lines []string) int {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimRight(lines[i], "\r") == "-- " {
+			if len(lines)-i-1 <= maxSignatureLines {
+				return i
+			}
+			break
+		}
+	}
+
+	first := len(lines) - maxSignatureLines
+	if first < 0 {
+		first = 0
+	}
+
+	for i := first; i < len(lines); i++ {
+		if mobileSignaturePattern.MatchString(lines[i]) {
+			return i
+		}
+	}
+
+	// a sign-off needs content above it and a name with a few short lines below it
+	for i := first; i < len(lines); i++ {
+		if i > 0 && signOffPattern.MatchString(lines[i]) && isSignOffBlock(lines[i+1:]) {
+			return i
+		}
+	}
+
+	// a final paragraph of at least two lines, most of them contact details
+	start := len(lines)
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+
+	footer := lines[start:]
+	if start == 0 || len(footer) < 2 || len(footer) > maxSignatureLines || !shortLines(footer) {
+		return -1
+	}
+
+	contacts := 0
+	for _, l := range footer {
+		if contactLinePattern.MatchString(l) {
+			contacts++
+		}
+	}
+
+	if contacts*2 < len(footer) {
+		return -1
+	}
+
+	return start
+}
+
+// isSignOffBlock reports whether the lines below a sign-off are a name with
+// a title or contact details, not another paragraph
+func isSignOffBlock(lines []string) bool {
+	if len(lines) == 0 || len(lines) > 5 {
+		return false
+	}
+
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			return false
+		}
+	}
+
+	return shortLines(lines)
+}
+
+// shortLines reports whether lines look like a name, title or contact details
+// rather than prose
+func shortLines(lines []string) bool {
+	for _, l := range lines {
+		if len([]rune(strings.TrimSpace(l))) > 60 {
+			return false
+		}
+	}
+
+	return true
+}
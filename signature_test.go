@@ -0,0 +1,102 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitSignature(t *testing.T) {
+	var testData = map[int]struct {
+		text      string
+		body      string
+		signature string
+	}{
+		1: {
+			text:      "Hi Mary,\n\nSee you tomorrow.\n\n-- \nJohn Doe\nhttps://example.com\n",
+			body:      "Hi Mary,\n\nSee you tomorrow.",
+			signature: "-- \nJohn Doe\nhttps://example.com",
+		},
+		2: {
+			text:      "The report is attached.\r\n\r\nBest regards,\r\nJohn Doe\r\nHead of Sales\r\n",
+			body:      "The report is attached.",
+			signature: "Best regards,\r\nJohn Doe\r\nHead of Sales",
+		},
+		3: {
+			text:      "Sure, works for me.\n\nSent from my iPhone\n",
+			body:      "Sure, works for me.",
+			signature: "Sent from my iPhone",
+		},
+		4: {
+			text:      "Please call me back.\n\nJohn Doe | Example Ltd.\nTel: +1 555 0100\njdoe@example.com\nwww.example.com\n",
+			body:      "Please call me back.",
+			signature: "John Doe | Example Ltd.\nTel: +1 555 0100\njdoe@example.com\nwww.example.com",
+		},
+		5: {
+			text: "Hi,\n\nThanks\n\nthe meeting moved to Friday, please update the invite and let everyone know.\n",
+			body: "Hi,\n\nThanks\n\nthe meeting moved to Friday, please update the invite and let everyone know.\n",
+		},
+		6: {
+			text: "Hello\nworld\n",
+			body: "Hello\nworld\n",
+		},
+		7: {
+			text:      "See you.\r\n\r\n-- \r\nJohn Doe\r\n",
+			body:      "See you.",
+			signature: "-- \r\nJohn Doe",
+		},
+		8: {
+			text: "Options:\n--\nnone of them\n",
+			body: "Options:\n--\nnone of them\n",
+		},
+		9: {
+			text: "Above the line\n-- \n" + strings.Repeat("a long quoted paragraph follows here\n", 9),
+			body: "Above the line\n-- \n" + strings.Repeat("a long quoted paragraph follows here\n", 9),
+		},
+	}
+
+	for index, td := range testData {
+		body, signature := SplitSignature(td.text)
+		if body != td.body {
+			t.Errorf("[Test Case %v] Wrong body. Expected: %q, Got: %q", index, td.body, body)
+		}
+
+		if signature != td.signature {
+			t.Errorf("[Test Case %v] Wrong signature. Expected: %q, Got: %q", index, td.signature, signature)
+		}
+	}
+}
+
+func TestSignatureStripping(t *testing.T) {
+	mailData := "From: John Doe <jdoe@machine.example>\n\nSee you.\n\nCheers,\nJohn\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Signature != "" {
+		t.Errorf("Signature set without the option: %q", e.Signature)
+	}
+
+	e, err = ParseWithOptions(strings.NewReader(mailData), WithSignatureDetection())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.TextBody != "See you.\n\nCheers,\nJohn" || e.Signature != "Cheers,\nJohn" {
+		t.Errorf("Wrong body or signature without stripping: %q, %q", e.TextBody, e.Signature)
+	}
+
+	e, err = ParseWithOptions(strings.NewReader(mailData), WithSignatureStripping(), WithTextAsHTML())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.TextBody != "See you." || e.Signature != "Cheers,\nJohn" {
+		t.Errorf("Wrong body or signature with stripping: %q, %q", e.TextBody, e.Signature)
+	}
+
+	if e.TextAsHTML != "<p>See you.</p>" {
+		t.Errorf("Wrong TextAsHTML with stripping: %q", e.TextAsHTML)
+	}
+}
@@ -0,0 +1,53 @@
+package parsemail
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+const contentTypeOctetStream = "application/octet-stream"
+
+// recoverOctetStreamBody sniffs a body mislabeled as application/octet-stream and
+// adds it as text or html body when it turns out to be one
+func recoverOctetStreamBody(e *Email, body io.Reader, h textproto.MIMEHeader, o *options) error {
+	var decoded string
+	var data io.Reader
+	var head []byte
+	var err error
+	if o.bodyReaders {
+		if data, err = readBodyData(e, body, h, o); err != nil {
+			return err
+		}
+
+		head = make([]byte, 512)
+		n, _ := data.(io.ReaderAt).ReadAt(head, 0)
+		head = head[:n]
+	} else {
+		if decoded, err = decodeBodyPart(e, body, h.Get(headerContentEncoding), h.Get(headerCompression)); err != nil {
+			return err
+		}
+
+		head = []byte(decoded)
+	}
+
+	sniffed := strings.Split(http.DetectContentType(head), ";")[0]
+	switch {
+	case sniffed != contentTypeTextHtml && sniffed != contentTypeTextPlain:
+		if c, ok := data.(io.Closer); ok {
+			c.Close()
+		}
+		return fmt.Errorf("Unknown top level mime type: %s", contentTypeOctetStream)
+	case data != nil:
+		appendBodyData(e, data, h, sniffed == contentTypeTextHtml)
+	case sniffed == contentTypeTextHtml:
+		addToHTMLBody(e, reconcileHTMLCharset(e, decoded, "", o), h, o)
+	default:
+		addToTextBody(e, decoded, h, o)
+	}
+
+	e.Warnings = append(e.Warnings, fmt.Sprintf("Recovered %s body as %s", contentTypeOctetStream, sniffed))
+	return nil
+}
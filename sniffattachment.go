@@ -0,0 +1,86 @@
+package parsemail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"strings"
+)
+
+// sniffLen is the number of leading bytes content sniffing considers, as in http.DetectContentType
+const sniffLen = 512
+
+// magicContentTypes are signatures of common attachment formats http.DetectContentType doesn't know
+var magicContentTypes = []struct {
+	magic       []byte
+	contentType string
+}{
+	{[]byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"), "application/x-ole-storage"},
+	{[]byte("7z\xbc\xaf\x27\x1c"), "application/x-7z-compressed"},
+	{[]byte("MZ"), "application/vnd.microsoft.portable-executable"},
+	{[]byte("\x7fELF"), "application/x-executable"},
+	{[]byte("{\\rtf"), "application/rtf"},
+}
+
+// ooxmlContentTypes map the part directories of Office Open XML packages to their content type
+var ooxmlContentTypes = []struct {
+	dir         string
+	contentType string
+}{
+	{"word/", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	{"xl/", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	{"ppt/", "application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+}
+
+// needsSniffing reports whether contentType tells nothing about the data
+func needsSniffing(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	return contentType == "" || contentType == contentTypeOctetStream
+}
+
+// sniffContentType returns the content type of data, identified by its leading
+// bytes. ZIP based office documents are told apart by their first entries.
+func sniffContentType(head []byte) string {
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+	}
+
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return sniffZipContentType(head)
+	}
+
+	for _, m := range magicContentTypes {
+		if bytes.HasPrefix(head, m.magic) {
+			return m.contentType
+		}
+	}
+
+	return strings.Split(http.DetectContentType(head), ";")[0]
+}
+
+func sniffZipContentType(head []byte) string {
+	// OpenDocument and EPUB start with an uncompressed "mimetype" entry holding
+	// their content type, right after its 30 byte local file header
+	if len(head) >= 38 && string(head[30:38]) == "mimetype" {
+		start := 38 + int(binary.LittleEndian.Uint16(head[28:30]))
+		end := start + int(binary.LittleEndian.Uint32(head[18:22]))
+		if end == start && start < len(head) {
+			// the size follows the data in a data descriptor
+			if i := bytes.Index(head[start:], []byte("PK")); i != -1 {
+				end = start + i
+			}
+		}
+
+		if end <= len(head) && end > start {
+			return string(head[start:end])
+		}
+	}
+
+	for _, t := range ooxmlContentTypes {
+		if bytes.Contains(head, []byte(t.dir)) {
+			return t.contentType
+		}
+	}
+
+	return "application/zip"
+}
@@ -0,0 +1,89 @@
+package parsemail
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+)
+
+func zipData(t *testing.T, names ...string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		var w io.Writer
+		var err error
+		if name == "mimetype" {
+			w, err = zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		} else {
+			w, err = zw.Create(name)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content := "content"
+		if name == "mimetype" {
+			content = "application/vnd.oasis.opendocument.text"
+		}
+		w.Write([]byte(content))
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestSniffContentType(t *testing.T) {
+	var testData = map[int]struct {
+		data     []byte
+		expected string
+	}{
+		1: {data: []byte("%PDF-1.4\n%..."), expected: "application/pdf"},
+		2: {data: []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1\x00\x00"), expected: "application/x-ole-storage"},
+		3: {data: zipData(t, "[Content_Types].xml", "word/document.xml"), expected: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		4: {data: zipData(t, "mimetype", "content.xml"), expected: "application/vnd.oasis.opendocument.text"},
+		5: {data: zipData(t, "notes.txt"), expected: "application/zip"},
+		6: {data: []byte("MZ\x90\x00"), expected: "application/vnd.microsoft.portable-executable"},
+		7: {data: []byte("just some text"), expected: "text/plain"},
+	}
+
+	for index, td := range testData {
+		if got := sniffContentType(td.data); got != td.expected {
+			t.Errorf("[Test Case %v] Wrong content type. Expected: %q, Got: %q", index, td.expected, got)
+		}
+	}
+}
+
+func TestAttachmentSniffing(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: application/octet-stream\nContent-Disposition: attachment; filename=\"scan\"\nContent-Transfer-Encoding: base64\n\n" +
+		base64.StdEncoding.EncodeToString([]byte("%PDF-1.4\n%...")) + "\n" +
+		"--m\nContent-Type: application/pdf\nContent-Disposition: attachment; filename=\"typed.pdf\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.Attachments[0].DetectedContentType != "" {
+		t.Errorf("Expected no sniffing by default, got: %q", e.Attachments[0].DetectedContentType)
+	}
+
+	e, err = ParseWithOptions(strings.NewReader(mailData), WithAttachmentSniffing())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.Attachments[0].DetectedContentType != "application/pdf" {
+		t.Errorf("Wrong detected content type. Expected: application/pdf, Got: %q", e.Attachments[0].DetectedContentType)
+	}
+
+	if e.Attachments[1].DetectedContentType != "" {
+		t.Errorf("Expected typed attachments not to be sniffed, got: %q", e.Attachments[1].DetectedContentType)
+	}
+}
@@ -0,0 +1,130 @@
+package parsemail
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sclSpamThreshold is the Microsoft spam confidence level from which messages are delivered as junk
+const sclSpamThreshold = 5
+
+var spamTestListFolding = regexp.MustCompile(`,\s+`)
+
+// SpamInfo is the normalized verdict of the spam filters that processed a message.
+// Score, Threshold and Tests come from SpamAssassin (X-Spam-Status, X-Spam-Score),
+// SCL and BCL from Microsoft (X-Forefront-Antispam-Report, X-Microsoft-Antispam,
+// X-MS-Exchange-Organization-SCL) and SESVerdict from Amazon SES. Spam is set when
+// any of them considered the message spam. Scanned is false if no spam header
+// was found at all.
+type SpamInfo struct {
+	Scanned   bool
+	Spam      bool
+	Score     float64
+	Threshold float64
+	Tests     []string
+
+	SCL *int
+	BCL *int
+
+	SESVerdict string
+}
+
+func (hp headerParser) parseSpamInfo() (si SpamInfo) {
+	if flag := hp.header.Get("X-Spam-Flag"); flag != "" {
+		si.Scanned = true
+		si.Spam = si.Spam || strings.EqualFold(strings.TrimSpace(flag), "yes")
+	}
+
+	if status := hp.header.Get("X-Spam-Status"); status != "" {
+		si.Scanned = true
+		parseSpamAssassinStatus(status, &si)
+	}
+
+	if score, err := strconv.ParseFloat(strings.TrimSpace(hp.header.Get("X-Spam-Score")), 64); err == nil {
+		si.Scanned = true
+		si.Score = score
+	}
+
+	for _, name := range []string{"X-Forefront-Antispam-Report", "X-Microsoft-Antispam", "X-MS-Exchange-Organization-SCL"} {
+		v := hp.header.Get(name)
+		if v == "" {
+			continue
+		}
+
+		si.Scanned = true
+		if name == "X-MS-Exchange-Organization-SCL" {
+			v = "SCL:" + v
+		}
+
+		for _, field := range strings.Split(v, ";") {
+			kv := strings.SplitN(strings.TrimSpace(field), ":", 2)
+			if len(kv) != 2 {
+				continue
+			}
+
+			n, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+			if err != nil {
+				continue
+			}
+
+			switch strings.ToUpper(kv[0]) {
+			case "SCL":
+				if si.SCL == nil {
+					si.SCL = &n
+				}
+			case "BCL":
+				if si.BCL == nil {
+					si.BCL = &n
+				}
+			}
+		}
+	}
+
+	if si.SCL != nil && *si.SCL >= sclSpamThreshold {
+		si.Spam = true
+	}
+
+	if verdict := strings.ToUpper(strings.TrimSpace(hp.header.Get("X-SES-Spam-Verdict"))); verdict != "" {
+		si.Scanned = true
+		si.SESVerdict = verdict
+		si.Spam = si.Spam || verdict == "FAIL"
+	}
+
+	return
+}
+
+// parseSpamAssassinStatus parses "Yes, score=5.2 required=5.0 tests=A,B autolearn=no version=3.4.0"
+func parseSpamAssassinStatus(status string, si *SpamInfo) {
+	verdict, rest := status, ""
+	if i := strings.Index(status, ","); i != -1 {
+		verdict, rest = status[:i], status[i+1:]
+	}
+	si.Spam = si.Spam || strings.EqualFold(strings.TrimSpace(verdict), "yes")
+
+	// folded test lists continue after a comma
+	rest = spamTestListFolding.ReplaceAllString(rest, ",")
+	for _, f := range strings.Fields(rest) {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch strings.ToLower(kv[0]) {
+		case "score", "hits":
+			if score, err := strconv.ParseFloat(kv[1], 64); err == nil {
+				si.Score = score
+			}
+		case "required":
+			if required, err := strconv.ParseFloat(kv[1], 64); err == nil {
+				si.Threshold = required
+			}
+		case "tests":
+			for _, test := range strings.Split(kv[1], ",") {
+				if test = strings.TrimSpace(test); test != "" && test != "none" {
+					si.Tests = append(si.Tests, test)
+				}
+			}
+		}
+	}
+}
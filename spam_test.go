@@ -0,0 +1,117 @@
+package parsemail
+
+import (
+	"net/mail"
+	"testing"
+)
+
+func TestParseSpamInfo(t *testing.T) {
+	var testData = map[int]struct {
+		header    mail.Header
+		scanned   bool
+		spam      bool
+		score     float64
+		threshold float64
+		tests     []string
+		scl       int
+		bcl       int
+		verdict   string
+	}{
+		1: {
+			header: mail.Header{},
+			scl:    -100,
+			bcl:    -100,
+		},
+		2: {
+			header: mail.Header{
+				"X-Spam-Status": {"Yes, score=7.3 required=5.0 tests=BAYES_99,\n\tHTML_MESSAGE,URIBL_BLACK autolearn=no version=3.4.0"},
+				"X-Spam-Flag":   {"YES"},
+			},
+			scanned:   true,
+			spam:      true,
+			score:     7.3,
+			threshold: 5,
+			tests:     []string{"BAYES_99", "HTML_MESSAGE", "URIBL_BLACK"},
+			scl:       -100,
+			bcl:       -100,
+		},
+		3: {
+			header: mail.Header{
+				"X-Spam-Status": {"No, hits=-1.9 required=5.0 tests=BAYES_00 autolearn=ham"},
+				"X-Spam-Score":  {"-1.9"},
+			},
+			scanned:   true,
+			score:     -1.9,
+			threshold: 5,
+			tests:     []string{"BAYES_00"},
+			scl:       -100,
+			bcl:       -100,
+		},
+		4: {
+			header: mail.Header{
+				"X-Forefront-Antispam-Report": {"CIP:192.0.2.1;CTRY:US;LANG:en;SCL:6;SRV:;IPV:NLI;SFV:SPM;"},
+				"X-Microsoft-Antispam":        {"BCL:3;"},
+			},
+			scanned: true,
+			spam:    true,
+			scl:     6,
+			bcl:     3,
+		},
+		5: {
+			header: mail.Header{
+				"X-Ms-Exchange-Organization-Scl": {"-1"},
+			},
+			scanned: true,
+			scl:     -1,
+			bcl:     -100,
+		},
+		6: {
+			header: mail.Header{
+				"X-Ses-Spam-Verdict": {"FAIL"},
+			},
+			scanned: true,
+			spam:    true,
+			scl:     -100,
+			bcl:     -100,
+			verdict: "FAIL",
+		},
+	}
+
+	for index, td := range testData {
+		hp := headerParser{header: &td.header}
+		si := hp.parseSpamInfo()
+
+		if si.Scanned != td.scanned || si.Spam != td.spam {
+			t.Errorf("[Test Case %v] Wrong verdict. Expected: scanned=%v spam=%v, Got: scanned=%v spam=%v", index, td.scanned, td.spam, si.Scanned, si.Spam)
+		}
+
+		if si.Score != td.score || si.Threshold != td.threshold {
+			t.Errorf("[Test Case %v] Wrong score. Expected: %v/%v, Got: %v/%v", index, td.score, td.threshold, si.Score, si.Threshold)
+		}
+
+		if !assertSliceEq(td.tests, si.Tests) {
+			t.Errorf("[Test Case %v] Wrong tests. Expected: %v, Got: %v", index, td.tests, si.Tests)
+		}
+
+		if scl := derefLevel(si.SCL); scl != td.scl {
+			t.Errorf("[Test Case %v] Wrong SCL. Expected: %v, Got: %v", index, td.scl, scl)
+		}
+
+		if bcl := derefLevel(si.BCL); bcl != td.bcl {
+			t.Errorf("[Test Case %v] Wrong BCL. Expected: %v, Got: %v", index, td.bcl, bcl)
+		}
+
+		if si.SESVerdict != td.verdict {
+			t.Errorf("[Test Case %v] Wrong SES verdict. Expected: %s, Got: %s", index, td.verdict, si.SESVerdict)
+		}
+	}
+}
+
+// derefLevel returns the level or -100 if it wasn't reported
+func derefLevel(level *int) int {
+	if level == nil {
+		return -100
+	}
+
+	return *level
+}
@@ -0,0 +1,87 @@
+package parsemail
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// spooledFile is part data held in a temporary file, which Close removes
+type spooledFile struct {
+	*io.SectionReader
+	f *os.File
+}
+
+func (s *spooledFile) Close() error {
+	err := s.f.Close()
+	if rmErr := os.Remove(s.f.Name()); err == nil {
+		err = rmErr
+	}
+
+	return err
+}
+
+// spoolData reads r into memory, or into a temporary file in dir when it is
+// longer than threshold bytes
+func spoolData(r io.Reader, threshold int64, dir string) (io.Reader, error) {
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, threshold+1); err == io.EOF {
+		return bytes.NewReader(buf.Bytes()), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile(dir, "parsemail-")
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := io.Copy(f, io.MultiReader(&buf, r))
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	return &spooledFile{SectionReader: io.NewSectionReader(f, 0, n), f: f}, nil
+}
+
+// Close releases the temporary files attachments, embedded files and body
+// parts were spooled to, also those of attached and returned messages, see WithSpooling.
+// Their data can't be read afterwards.
+func (e Email) Close() error {
+	var err error
+	closeData := func(r io.Reader) {
+		if s, ok := r.(*spooledFile); ok {
+			if closeErr := s.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+
+	for _, a := range e.Attachments {
+		closeData(a.Data)
+		if a.Message != nil {
+			if closeErr := a.Message.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+
+	for _, ef := range e.EmbeddedFiles {
+		closeData(ef.Data)
+	}
+
+	for _, data := range append(e.textBodyData, e.htmlBodyData...) {
+		closeData(data)
+	}
+
+	if e.OriginalMessage != nil {
+		if closeErr := e.OriginalMessage.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,99 @@
+package parsemail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpooling(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parsemail-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	large := bytes.Repeat([]byte("spooled "), 100)
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: application/octet-stream\nContent-Disposition: attachment; filename=\"large.bin\"\nContent-Transfer-Encoding: base64\n\n" +
+		base64.StdEncoding.EncodeToString(large) + "\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"small.txt\"\nContent-Transfer-Encoding: base64\n\nc21hbGw=\n--m--\n"
+
+	e, err := ParseWithOptions(strings.NewReader(mailData), WithSpooling(64, dir))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, ok := e.Attachments[0].Data.(*spooledFile); !ok {
+		t.Errorf("Expected the large attachment to be spooled, got: %T", e.Attachments[0].Data)
+	}
+
+	if _, ok := e.Attachments[1].Data.(*bytes.Reader); !ok {
+		t.Errorf("Expected the small attachment to be kept in memory, got: %T", e.Attachments[1].Data)
+	}
+
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("Wrong number of spooled files. Expected: 1, Got: %v", len(files))
+	}
+
+	sr, err := e.Attachments[0].SectionReader()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if data, _ := ioutil.ReadAll(sr); !bytes.Equal(data, large) {
+		t.Errorf("Wrong spooled data. Expected: %q, Got: %q", large, data)
+	}
+
+	if data, _ := ioutil.ReadAll(e.Attachments[0].Data); !bytes.Equal(data, large) {
+		t.Errorf("Wrong spooled data. Expected: %q, Got: %q", large, data)
+	}
+
+	if err := e.Close(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected spooled files to be removed, found %v", len(files))
+	}
+}
+
+func TestSpoolingReturnedMessage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parsemail-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	returned := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: text/plain\n\nbody\n" +
+		"--m\nContent-Type: application/octet-stream\nContent-Disposition: attachment; filename=\"large.bin\"\nContent-Transfer-Encoding: base64\n\n" +
+		base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("spooled "), 100)) + "\n--m--\n"
+	mailData := "From: MAILER-DAEMON@example.com\nContent-Type: multipart/report; report-type=delivery-status; boundary=r\n\n" +
+		"--r\nContent-Type: text/plain\n\nUndeliverable\n" +
+		"--r\nContent-Type: message/rfc822\n\n" + returned + "\n--r--\n"
+
+	e, err := ParseWithOptions(strings.NewReader(mailData), WithSpooling(64, dir))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if e.OriginalMessage == nil || len(e.OriginalMessage.Attachments) != 1 {
+		t.Fatalf("Returned message not parsed: %v", e.OriginalMessage)
+	}
+
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("Wrong number of spooled files. Expected: 1, Got: %v", len(files))
+	}
+
+	if err := e.Close(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected spooled files to be removed, found %v", len(files))
+	}
+}
@@ -0,0 +1,75 @@
+package parsemail
+
+import "strings"
+
+// DecodeSRS recovers the original envelope sender of an address rewritten
+// with the Sender Rewriting Scheme by forwarders, like
+// SRS0=HHH=TT=example.com=jane@forwarder.example (jane@example.com) or its
+// SRS1 form for forwarders of forwarders. The hash and timestamp are not
+// verified. ok is false when the address isn't an SRS address.
+func DecodeSRS(address string) (original string, ok bool) {
+	local, _ := splitAddress(strings.Trim(strings.TrimSpace(address), "<>"))
+	if len(local) < 5 || strings.IndexByte("=+-", local[4]) == -1 {
+		return "", false
+	}
+
+	switch strings.ToUpper(local[:4]) {
+	case "SRS0":
+		// SRS0=HHH=TT=domain=local
+		parts := strings.SplitN(local[5:], "=", 4)
+		if len(parts) != 4 || parts[2] == "" || parts[3] == "" {
+			return "", false
+		}
+
+		return parts[3] + "@" + parts[2], true
+	case "SRS1":
+		// SRS1=HHH=forwarder==HHH=TT=domain=local, the separator after the forwarder is the one SRS0 used
+		parts := strings.SplitN(local[5:], "=", 3)
+		if len(parts) != 3 || len(parts[2]) < 2 {
+			return "", false
+		}
+
+		srs0 := strings.SplitN(parts[2][1:], "=", 4)
+		if len(srs0) != 4 || srs0[2] == "" || srs0[3] == "" {
+			return "", false
+		}
+
+		return srs0[3] + "@" + srs0[2], true
+	}
+
+	return "", false
+}
+
+// DecodeVERP recovers the recipient encoded in a variable envelope return path
+// (VERP) like bounces-jane=example.org@lists.example.com or
+// list-bounces+jane=example.org@lists.example.com (jane@example.org). The
+// recipient starts after the first "+", or the first "-" when there is no "+",
+// and its "@" is encoded as the last "=". ok is false when the address isn't
+// a VERP address.
+func DecodeVERP(address string) (recipient string, ok bool) {
+	local, domain := splitAddress(strings.Trim(strings.TrimSpace(address), "<>"))
+	if domain == "" {
+		return "", false
+	}
+
+	if _, srs := DecodeSRS(address); srs {
+		return "", false
+	}
+
+	start := strings.IndexByte(local, '+')
+	if start == -1 {
+		start = strings.IndexByte(local, '-')
+	}
+
+	at := strings.LastIndexByte(local, '=')
+	if start <= 0 || at <= start+1 || at == len(local)-1 {
+		return "", false
+	}
+
+	recipientDomain := local[at+1:]
+	if !strings.Contains(recipientDomain, ".") {
+		return "", false
+	}
+
+	return local[start+1:at] + "@" + recipientDomain, true
+}
@@ -0,0 +1,48 @@
+package parsemail
+
+import "testing"
+
+func TestDecodeSRS(t *testing.T) {
+	var testData = map[int]struct {
+		address  string
+		original string
+		ok       bool
+	}{
+		1: {address: "SRS0=HHH=TT=example.com=jane@forwarder.example", original: "jane@example.com", ok: true},
+		2: {address: "<srs0=a1b2=2K=example.com=jane.doe@forwarder.example>", original: "jane.doe@example.com", ok: true},
+		3: {address: "SRS1=HHH=first.example==HHH=TT=example.com=jane@second.example", original: "jane@example.com", ok: true},
+		4: {address: "SRS0=HHH=TT=example.com=a=b@forwarder.example", original: "a=b@example.com", ok: true},
+		5: {address: "jane@example.com"},
+		6: {address: "SRS0=broken@forwarder.example"},
+	}
+
+	for index, td := range testData {
+		original, ok := DecodeSRS(td.address)
+		if original != td.original || ok != td.ok {
+			t.Errorf("[Test Case %v] Wrong decoding. Expected: '%s' %v, Got: '%s' %v", index, td.original, td.ok, original, ok)
+		}
+	}
+}
+
+func TestDecodeVERP(t *testing.T) {
+	var testData = map[int]struct {
+		address   string
+		recipient string
+		ok        bool
+	}{
+		1: {address: "bounces-jane=example.org@lists.example.com", recipient: "jane@example.org", ok: true},
+		2: {address: "list-bounces+jane-doe=example.org@lists.example.com", recipient: "jane-doe@example.org", ok: true},
+		3: {address: "<bounce-jane.doe=mail.example.org@example.com>", recipient: "jane.doe@mail.example.org", ok: true},
+		4: {address: "bounces@lists.example.com"},
+		5: {address: "bounces-jane=local@lists.example.com"},
+		6: {address: "SRS0=HHH=TT=example.com=jane@forwarder.example"},
+		7: {address: "no-reply@example.com"},
+	}
+
+	for index, td := range testData {
+		recipient, ok := DecodeVERP(td.address)
+		if recipient != td.recipient || ok != td.ok {
+			t.Errorf("[Test Case %v] Wrong decoding. Expected: '%s' %v, Got: '%s' %v", index, td.recipient, td.ok, recipient, ok)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package parsemail
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// topSendersLimit is the number of senders reported in MailboxStats.TopSenders
+const topSendersLimit = 10
+
+// sizeBuckets are the upper bounds of the MailboxStats.SizeDistribution buckets
+var sizeBuckets = []struct {
+	label string
+	limit int64
+}{
+	{"<10KB", 10 << 10},
+	{"<100KB", 100 << 10},
+	{"<1MB", 1 << 20},
+	{"<10MB", 10 << 20},
+	{">=10MB", -1},
+}
+
+// MailboxStats are aggregate statistics over the messages of a mailbox, meant to be marshaled as JSON
+type MailboxStats struct {
+	Messages    int   `json:"messages"`
+	ParseErrors int   `json:"parse_errors"`
+	TotalBytes  int64 `json:"total_bytes"`
+
+	TopSenders       []SenderCount  `json:"top_senders"`
+	SizeDistribution []SizeBucket   `json:"size_distribution"`
+	AttachmentTypes  map[string]int `json:"attachment_types"`
+	MessagesPerDay   map[string]int `json:"messages_per_day"`
+
+	senders map[string]int
+}
+
+// SenderCount is the number of messages sent from Address
+type SenderCount struct {
+	Address  string `json:"address"`
+	Messages int    `json:"messages"`
+}
+
+// SizeBucket is the number of messages with raw size within the bucket
+type SizeBucket struct {
+	Bucket   string `json:"bucket"`
+	Messages int    `json:"messages"`
+}
+
+// AnalyzeMbox collects MailboxStats over all messages of the mbox stream read from r
+func AnalyzeMbox(r io.Reader) (*MailboxStats, error) {
+	stats := newMailboxStats()
+	mr := NewMboxReader(r)
+	for {
+		raw, err := mr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		stats.add(raw)
+	}
+
+	stats.finish()
+	return stats, nil
+}
+
+// AnalyzeMaildir collects MailboxStats over all messages of the Maildir at dir
+func AnalyzeMaildir(dir string) (*MailboxStats, error) {
+	stats := newMailboxStats()
+	err := ReadMaildir(dir, func(raw []byte) error {
+		stats.add(raw)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats.finish()
+	return stats, nil
+}
+
+// WriteJSON writes the statistics to w as indented JSON
+func (s *MailboxStats) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+func newMailboxStats() *MailboxStats {
+	s := &MailboxStats{
+		AttachmentTypes: map[string]int{},
+		MessagesPerDay:  map[string]int{},
+		senders:         map[string]int{},
+	}
+
+	for _, b := range sizeBuckets {
+		s.SizeDistribution = append(s.SizeDistribution, SizeBucket{Bucket: b.label})
+	}
+
+	return s
+}
+
+func (s *MailboxStats) add(raw []byte) {
+	size := int64(len(raw))
+	s.Messages++
+	s.TotalBytes += size
+
+	for i, b := range sizeBuckets {
+		if b.limit < 0 || size < b.limit {
+			s.SizeDistribution[i].Messages++
+			break
+		}
+	}
+
+	e, err := Parse(bytes.NewReader(raw))
+	if err != nil {
+		s.ParseErrors++
+		return
+	}
+
+	if len(e.From) > 0 {
+		s.senders[strings.ToLower(e.From[0].Address)]++
+	}
+
+	for _, a := range e.Attachments {
+		s.AttachmentTypes[strings.ToLower(a.ContentType)]++
+	}
+
+	day := "unknown"
+	if !e.Date.IsZero() {
+		day = e.Date.UTC().Format("2006-01-02")
+	}
+	s.MessagesPerDay[day]++
+}
+
+func (s *MailboxStats) finish() {
+	s.TopSenders = nil
+	for address, n := range s.senders {
+		s.TopSenders = append(s.TopSenders, SenderCount{Address: address, Messages: n})
+	}
+
+	sort.Slice(s.TopSenders, func(i, j int) bool {
+		if s.TopSenders[i].Messages != s.TopSenders[j].Messages {
+			return s.TopSenders[i].Messages > s.TopSenders[j].Messages
+		}
+
+		return s.TopSenders[i].Address < s.TopSenders[j].Address
+	})
+
+	if len(s.TopSenders) > topSendersLimit {
+		s.TopSenders = s.TopSenders[:topSendersLimit]
+	}
+}
@@ -0,0 +1,81 @@
+package parsemail
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+)
+
+// AttachmentStreamFunc consumes an attachment while the message is parsed, see
+// WithAttachmentStreaming. The attachment Data is an io.ReadCloser that is
+// only valid until the function returns.
+type AttachmentStreamFunc func(a Attachment) error
+
+// AttachmentDecision tells how an attachment is handled, see WithAttachmentFilter
+type AttachmentDecision int
+
+const (
+	// StreamAttachment streams the attachment to the function set
+	// WithAttachmentStreaming, or buffers it when there is none
+	StreamAttachment AttachmentDecision = iota
+	// BufferAttachment decodes the attachment into Data
+	BufferAttachment
+	// SkipAttachment drops the attachment without decoding it
+	SkipAttachment
+)
+
+// AttachmentFilterFunc decides how the attachment with the MIME header h is handled
+type AttachmentFilterFunc func(h textproto.MIMEHeader) AttachmentDecision
+
+// ErrAttachmentStreamed is returned reading the data of an attachment that was
+// streamed while parsing
+var ErrAttachmentStreamed = errors.New("Attachment data was streamed while parsing")
+
+// streamedData is the Data of attachments after they were streamed
+type streamedData struct{}
+
+func (streamedData) Read(p []byte) (int, error) {
+	return 0, ErrAttachmentStreamed
+}
+
+// partStream lazily decodes the data of a part, Close discards what wasn't read
+type partStream struct {
+	r      io.Reader
+	closed bool
+	err    error
+}
+
+func (s *partStream) Read(p []byte) (int, error) {
+	if s.closed {
+		return 0, io.EOF
+	}
+
+	return s.r.Read(p)
+}
+
+func (s *partStream) Close() error {
+	if !s.closed {
+		s.closed = true
+		_, s.err = io.Copy(ioutil.Discard, s.r)
+	}
+
+	return s.err
+}
+
+// streamAttachment passes at to the attachment stream func of o with Data decoding the part data r
+// with the header h, writing the decoded data to w as it passes through
+func streamAttachment(r io.Reader, h textproto.MIMEHeader, at Attachment, w io.Writer, o *options) error {
+	dr, err := partDataReader(r, h, o)
+	if err != nil {
+		return err
+	}
+
+	s := &partStream{r: io.TeeReader(dr, w)}
+	at.Data = s
+	if err := o.attachmentStream(at); err != nil {
+		return err
+	}
+
+	return s.Close()
+}
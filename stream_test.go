@@ -0,0 +1,104 @@
+package parsemail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestAttachmentStreaming(t *testing.T) {
+	data := bytes.Repeat([]byte("streamed attachment data "), 1000)
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: application/octet-stream\nContent-Disposition: attachment; filename=\"a.bin\"\nContent-Transfer-Encoding: base64\n\n" +
+		base64.StdEncoding.EncodeToString(data) + "\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"b.txt\"\nContent-Transfer-Encoding: base64\n\nc2tpcHBlZA==\n--m--\n"
+
+	var streamed bytes.Buffer
+	e, err := ParseWithOptions(strings.NewReader(mailData), WithAttachmentStreaming(func(a Attachment) error {
+		if _, ok := a.Data.(io.ReadCloser); !ok {
+			t.Errorf("Expected streamed data to be an io.ReadCloser")
+		}
+
+		if a.Filename == "a.bin" {
+			_, err := io.Copy(&streamed, a.Data)
+			return err
+		}
+
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(streamed.Bytes(), data) {
+		t.Errorf("Wrong streamed data. Expected %v bytes, Got: %v bytes", len(data), streamed.Len())
+	}
+
+	if len(e.Attachments) != 2 {
+		t.Fatalf("Wrong number of attachments. Expected: 2, Got: %v", len(e.Attachments))
+	}
+
+	if e.Attachments[0].Entropy != shannonEntropy(data) {
+		t.Errorf("Wrong entropy. Expected: %v, Got: %v", shannonEntropy(data), e.Attachments[0].Entropy)
+	}
+
+	if _, err := ioutil.ReadAll(e.Attachments[1].Data); err != ErrAttachmentStreamed {
+		t.Errorf("Expected ErrAttachmentStreamed reading a streamed attachment, got: %v", err)
+	}
+
+	abort := errors.New("Disallowed attachment")
+	_, err = ParseWithOptions(strings.NewReader(mailData), WithAttachmentStreaming(func(a Attachment) error {
+		return abort
+	}))
+	if err != abort {
+		t.Errorf("Expected the stream error to abort the parse, got: %v", err)
+	}
+}
+
+func TestAttachmentFilter(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: application/x-msdownload\nContent-Disposition: attachment; filename=\"setup.exe\"\nContent-Transfer-Encoding: base64\n\n!!! not even base64 !!!\n" +
+		"--m\nContent-Type: text/plain\nContent-Disposition: attachment; filename=\"small.txt\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n" +
+		"--m\nContent-Type: application/pdf\nContent-Disposition: attachment; filename=\"large.pdf\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	var streamed []string
+	e, err := ParseWithOptions(strings.NewReader(mailData),
+		WithAttachmentFilter(func(h textproto.MIMEHeader) AttachmentDecision {
+			switch {
+			case strings.HasPrefix(h.Get("Content-Type"), "application/x-msdownload"):
+				return SkipAttachment
+			case strings.HasPrefix(h.Get("Content-Type"), "text/"):
+				return BufferAttachment
+			}
+			return StreamAttachment
+		}),
+		WithAttachmentStreaming(func(a Attachment) error {
+			streamed = append(streamed, a.Filename)
+			return nil
+		}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, a := range e.Attachments {
+		names = append(names, a.Filename)
+	}
+
+	if expected := []string{"small.txt", "large.pdf"}; !assertSliceEq(expected, names) {
+		t.Errorf("Wrong attachments. Expected: %v, Got: %v", expected, names)
+	}
+
+	if expected := []string{"large.pdf"}; !assertSliceEq(expected, streamed) {
+		t.Errorf("Wrong streamed attachments. Expected: %v, Got: %v", expected, streamed)
+	}
+
+	if data, err := ioutil.ReadAll(e.Attachments[0].Data); err != nil || string(data) != "hello" {
+		t.Errorf("Expected the buffered attachment to be readable, got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,116 @@
+package parsemail
+
+import (
+	"strings"
+	"sync"
+)
+
+// SubaddressConvention describes how a mail provider separates the tag of a
+// sub-address (like user+tag@example.com) and which parts of the local part
+// it treats as insignificant.
+type SubaddressConvention struct {
+	// Separators holds the characters starting the tag, the first one found is used
+	Separators string
+	// IgnoreDots removes dots from the local part of the base address
+	IgnoreDots bool
+	// FoldCase lower cases the local part of the base address
+	FoldCase bool
+}
+
+// DefaultSubaddressConvention is used for domains without a registered convention
+var DefaultSubaddressConvention = SubaddressConvention{Separators: "+"}
+
+var (
+	subaddressConventionsMu sync.RWMutex
+	subaddressConventions   = map[string]SubaddressConvention{
+		"gmail.com":      {Separators: "+", IgnoreDots: true, FoldCase: true},
+		"googlemail.com": {Separators: "+", IgnoreDots: true, FoldCase: true},
+		"outlook.com":    {Separators: "+", FoldCase: true},
+		"hotmail.com":    {Separators: "+", FoldCase: true},
+		"fastmail.com":   {Separators: "+", FoldCase: true},
+		"yahoo.com":      {Separators: "-", FoldCase: true},
+	}
+)
+
+// RegisterSubaddressConvention sets the convention used by SplitSubaddress for
+// addresses in domain. Registering the zero value removes the convention.
+func RegisterSubaddressConvention(domain string, c SubaddressConvention) {
+	subaddressConventionsMu.Lock()
+	defer subaddressConventionsMu.Unlock()
+
+	domain = strings.ToLower(domain)
+	if c == (SubaddressConvention{}) {
+		delete(subaddressConventions, domain)
+		return
+	}
+
+	subaddressConventions[domain] = c
+}
+
+// SplitSubaddress splits address into its base address and sub-address tag
+// using the convention registered for its domain, so jane+news@example.com
+// becomes jane@example.com and news. The tag is empty when there is none.
+func SplitSubaddress(address string) (base, tag string) {
+	return splitSubaddress(address, nil)
+}
+
+// SplitSubaddress splits address like the package level SplitSubaddress, with
+// the conventions registered for the Parser, see WithSubaddressConvention
+func (p *Parser) SplitSubaddress(address string) (base, tag string) {
+	return splitSubaddress(address, &p.o.registry)
+}
+
+func splitSubaddress(address string, reg *registry) (base, tag string) {
+	_, domain := splitAddress(address)
+
+	c, ok := reg.subaddressConvention(domain)
+	if !ok {
+		c = DefaultSubaddressConvention
+	}
+
+	return c.Split(address)
+}
+
+// subaddressConvention returns the convention registered for domain, reg may be nil
+func (reg *registry) subaddressConvention(domain string) (SubaddressConvention, bool) {
+	domain = strings.ToLower(domain)
+	if reg != nil {
+		if c, ok := reg.subaddressConventions[domain]; ok {
+			return c, c != (SubaddressConvention{})
+		}
+	}
+
+	subaddressConventionsMu.RLock()
+	defer subaddressConventionsMu.RUnlock()
+
+	c, ok := subaddressConventions[domain]
+	return c, ok
+}
+
+// Split splits address into its base address and sub-address tag using the
+// convention c. The domain of the base address is lower cased, quoted local
+// parts are left as they are.
+func (c SubaddressConvention) Split(address string) (base, tag string) {
+	local, domain := splitAddress(address)
+	if domain == "" {
+		return address, ""
+	}
+
+	if strings.HasPrefix(local, "\"") {
+		return local + "@" + strings.ToLower(domain), ""
+	}
+
+	if i := strings.IndexAny(local, c.Separators); i > 0 && c.Separators != "" {
+		local, tag = local[:i], local[i+1:]
+	}
+
+	if c.IgnoreDots {
+		local = strings.Replace(local, ".", "", -1)
+	}
+
+	if c.FoldCase {
+		local = strings.ToLower(local)
+	}
+
+	return local + "@" + strings.ToLower(domain), tag
+}
@@ -0,0 +1,41 @@
+package parsemail
+
+import "testing"
+
+func TestSplitSubaddress(t *testing.T) {
+	var testData = map[int]struct {
+		address string
+		base    string
+		tag     string
+	}{
+		1: {address: "jane+news@Example.com", base: "jane@example.com", tag: "news"},
+		2: {address: "jane@example.com", base: "jane@example.com"},
+		3: {address: "J.Doe+shop+x@gmail.com", base: "jdoe@gmail.com", tag: "shop+x"},
+		4: {address: "jane-news@yahoo.com", base: "jane@yahoo.com", tag: "news"},
+		5: {address: "jane-news@example.com", base: "jane-news@example.com"},
+		6: {address: "+news@example.com", base: "+news@example.com"},
+		7: {address: `"jane+news"@example.com`, base: `"jane+news"@example.com`},
+		8: {address: "not an address", base: "not an address"},
+	}
+
+	for index, td := range testData {
+		base, tag := SplitSubaddress(td.address)
+		if base != td.base || tag != td.tag {
+			t.Errorf("[Test Case %v] Wrong split. Expected: '%s' '%s', Got: '%s' '%s'", index, td.base, td.tag, base, tag)
+		}
+	}
+}
+
+func TestRegisterSubaddressConvention(t *testing.T) {
+	RegisterSubaddressConvention("Example.net", SubaddressConvention{Separators: "-+"})
+	defer RegisterSubaddressConvention("example.net", SubaddressConvention{})
+
+	if base, tag := SplitSubaddress("jane-news@example.net"); base != "jane@example.net" || tag != "news" {
+		t.Errorf("Wrong split with registered convention: '%s' '%s'", base, tag)
+	}
+
+	RegisterSubaddressConvention("example.net", SubaddressConvention{})
+	if base, tag := SplitSubaddress("jane-news@example.net"); base != "jane-news@example.net" || tag != "" {
+		t.Errorf("Wrong split after removing convention: '%s' '%s'", base, tag)
+	}
+}
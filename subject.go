@@ -0,0 +1,35 @@
+package parsemail
+
+import (
+	"regexp"
+	"strings"
+)
+
+// subjectPrefixPattern matches one reply or forward prefix, like "Re:", "RE[2]:"
+// or "Fwd:", in English and the most common localized forms, or one [list-tag]
+var subjectPrefixPattern = regexp.MustCompile(`(?i)^\s*(?:` +
+	`(?:re|fwd?|aw|wg|sv|vs|vb|antw|doorst|rif|r|i|tr|enc|res|rv|odp|pd|ynt|ilt|vl|atb|trs|` +
+	`回复|回覆|答复|转发|轉寄|返信|転送|답장|전달)\s*(?:\[\d+\]|\(\d+\))?\s*[:：]` +
+	`|\[[^\[\]]*\])`)
+
+// subjectSuffixPattern matches trailing forward markers like "(fwd)"
+var subjectSuffixPattern = regexp.MustCompile(`(?i)\s*\((?:fwd?|forw(?:arded)?)\)\s*$`)
+
+// NormalizeSubject returns the subject without reply and forward prefixes
+// (Re:, Fwd:, Aw:, Sv:, RE[2]:, 回复: ...), leading [list-tag] brackets and
+// trailing (fwd) markers, with whitespace collapsed. Messages of a thread
+// share the normalized subject.
+func NormalizeSubject(subject string) string {
+	s := subject
+	for {
+		stripped := subjectPrefixPattern.ReplaceAllString(s, "")
+		stripped = subjectSuffixPattern.ReplaceAllString(stripped, "")
+		if stripped == s {
+			break
+		}
+
+		s = stripped
+	}
+
+	return strings.Join(strings.Fields(s), " ")
+}
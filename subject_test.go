@@ -0,0 +1,29 @@
+package parsemail
+
+import "testing"
+
+func TestNormalizeSubject(t *testing.T) {
+	var testData = map[int]struct {
+		subject    string
+		normalized string
+	}{
+		1:  {subject: "Hello", normalized: "Hello"},
+		2:  {subject: "Re: Hello", normalized: "Hello"},
+		3:  {subject: "RE: re:  Fwd: Hello  world", normalized: "Hello world"},
+		4:  {subject: "[golang-nuts] Re: [golang-nuts] Hello", normalized: "Hello"},
+		5:  {subject: "AW: WG: Angebot", normalized: "Angebot"},
+		6:  {subject: "Re[2]: Re(3): Hello", normalized: "Hello"},
+		7:  {subject: "SV: Vs: Hej", normalized: "Hej"},
+		8:  {subject: "回复：会议", normalized: "会议"},
+		9:  {subject: "Hello (fwd)", normalized: "Hello"},
+		10: {subject: "Reminder: meeting", normalized: "Reminder: meeting"},
+		11: {subject: "Re: Ticket [#1234] updated", normalized: "Ticket [#1234] updated"},
+		12: {subject: "", normalized: ""},
+	}
+
+	for index, td := range testData {
+		if n := NormalizeSubject(td.subject); n != td.normalized {
+			t.Errorf("[Test Case %v] Wrong normalized subject. Expected: '%s', Got: '%s'", index, td.normalized, n)
+		}
+	}
+}
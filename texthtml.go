@@ -0,0 +1,92 @@
+package parsemail
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	textURLPattern      = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+	textBulletPattern   = regexp.MustCompile(`^\s*(?:[-*+•]|\d+[.)])\s+(.*)$`)
+	textStrongPattern   = regexp.MustCompile(`(^|[\s(])\*([^\s*](?:[^*]*[^\s*])?)\*`)
+	textEmphasisPattern = regexp.MustCompile(`(^|[\s(])_([^\s_](?:[^_]*[^\s_])?)_`)
+)
+
+// textToHTML renders plain text as minimal HTML: paragraphs split on blank lines,
+// bullet and numbered lines as lists, URLs as links and *strong*/_emphasis_ markup.
+// All text is escaped, so the result is safe to embed.
+func textToHTML(text string) string {
+	var sb strings.Builder
+	lines := strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+
+	inParagraph, inList := false, false
+	closeBlock := func() {
+		if inParagraph {
+			sb.WriteString("</p>\n")
+			inParagraph = false
+		}
+		if inList {
+			sb.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			closeBlock()
+			continue
+		}
+
+		if m := textBulletPattern.FindStringSubmatch(line); m != nil {
+			if !inList {
+				closeBlock()
+				sb.WriteString("<ul>\n")
+				inList = true
+			}
+			sb.WriteString("<li>" + formatTextLine(m[1]) + "</li>\n")
+			continue
+		}
+
+		if inList {
+			closeBlock()
+		}
+
+		if inParagraph {
+			sb.WriteString("<br>\n")
+		} else {
+			sb.WriteString("<p>")
+			inParagraph = true
+		}
+		sb.WriteString(formatTextLine(line))
+	}
+	closeBlock()
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// formatTextLine escapes a line of text, linkifying URLs and applying emphasis markup outside of them
+func formatTextLine(line string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range textURLPattern.FindAllStringIndex(line, -1) {
+		url := strings.TrimRight(line[loc[0]:loc[1]], ".,;:!?)]}'")
+		end := loc[0] + len(url)
+
+		sb.WriteString(formatTextEmphasis(html.EscapeString(line[last:loc[0]])))
+		href := url
+		if strings.HasPrefix(strings.ToLower(href), "www.") {
+			href = "http://" + href
+		}
+		sb.WriteString(`<a href="` + html.EscapeString(href) + `">` + html.EscapeString(url) + "</a>")
+		last = end
+	}
+	sb.WriteString(formatTextEmphasis(html.EscapeString(line[last:])))
+
+	return sb.String()
+}
+
+func formatTextEmphasis(s string) string {
+	s = textStrongPattern.ReplaceAllString(s, "$1<strong>$2</strong>")
+	return textEmphasisPattern.ReplaceAllString(s, "$1<em>$2</em>")
+}
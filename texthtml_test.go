@@ -0,0 +1,90 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextToHTML(t *testing.T) {
+	var testData = map[int]struct {
+		text     string
+		expected string
+	}{
+		1: {
+			text:     "This is a message just to say hello.\nSo, \"Hello\".",
+			expected: "<p>This is a message just to say hello.<br>\nSo, &#34;Hello&#34;.</p>",
+		},
+		2: {
+			text:     "See https://example.com/a?b=1&c=2.\n\nOr www.example.org",
+			expected: "<p>See <a href=\"https://example.com/a?b=1&amp;c=2\">https://example.com/a?b=1&amp;c=2</a>.</p>\n<p>Or <a href=\"http://www.example.org\">www.example.org</a></p>",
+		},
+		3: {
+			text:     "Agenda:\n- *budget* review\n* _hiring_\n1. a < b\nThanks",
+			expected: "<p>Agenda:</p>\n<ul>\n<li><strong>budget</strong> review</li>\n<li><em>hiring</em></li>\n<li>a &lt; b</li>\n</ul>\n<p>Thanks</p>",
+		},
+		4: {
+			text:     "snake_case_name and 2*3*4 stay as they are, http://example.com/_x_/*y*",
+			expected: "<p>snake_case_name and 2*3*4 stay as they are, <a href=\"http://example.com/_x_/*y*\">http://example.com/_x_/*y*</a></p>",
+		},
+	}
+
+	for index, td := range testData {
+		if got := textToHTML(td.text); got != td.expected {
+			t.Errorf("[Test Case %v] Wrong HTML. Expected: '%s', Got: '%s'", index, td.expected, got)
+		}
+	}
+
+	e, err := Parse(strings.NewReader(rfc5322exampleA11))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.TextAsHTML != "" {
+		t.Errorf("TextAsHTML set without the option: '%s'", e.TextAsHTML)
+	}
+
+	e, err = ParseWithOptions(strings.NewReader(rfc5322exampleA11), WithTextAsHTML())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.TextAsHTML != textToHTML(e.TextBody) || e.HTMLBody != "" {
+		t.Errorf("TextAsHTML not set for text only message: '%s'", e.TextAsHTML)
+	}
+
+	e, err = ParseWithOptions(strings.NewReader(data2), WithTextAsHTML())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.TextAsHTML != "" {
+		t.Errorf("TextAsHTML set for message with html body: '%s'", e.TextAsHTML)
+	}
+}
+
+func TestTextToHTMLFallback(t *testing.T) {
+	mailData := "From: John Doe <jdoe@machine.example>\nContent-Type: text/plain\n\n<b>Hi</b>, see www.example.com\nBye\n"
+	expected := "<p>&lt;b&gt;Hi&lt;/b&gt;, see <a href=\"http://www.example.com\">www.example.com</a><br>\nBye</p>"
+
+	e, err := Parse(strings.NewReader(mailData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.HTMLBody != "" {
+		t.Errorf("Expected no HTML body without the option, Got: %q", e.HTMLBody)
+	}
+
+	e, err = ParseWithOptions(strings.NewReader(mailData), WithTextToHTMLFallback())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.HTMLBody != expected {
+		t.Errorf("Wrong HTML body. Expected: %q, Got: %q", expected, e.HTMLBody)
+	}
+
+	if e.TextAsHTML != expected {
+		t.Errorf("Wrong TextAsHTML. Expected: %q, Got: %q", expected, e.TextAsHTML)
+	}
+}
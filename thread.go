@@ -0,0 +1,265 @@
+package parsemail
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// replySubjectPattern matches subjects of replies and forwards, after optional [list-tags]
+var replySubjectPattern = regexp.MustCompile(`(?i)^\s*(?:\[[^\[\]]*\]\s*)*(?:re|fwd?|aw|wg|sv|vs|antw|rif|r|tr|res|回复|回覆|转发|返信)\s*(?:\[\d+\]|\(\d+\))?\s*[:：]`)
+
+// Thread is a node of a conversation tree built by BuildThreads. Email is
+// nil for messages that are referenced by others but missing from the set,
+// and for the nodes grouping messages with the same subject.
+type Thread struct {
+	Email     *Email
+	MessageID string
+
+	Parent   *Thread
+	Children []*Thread
+}
+
+// Walk calls fn for t and all its descendants in depth first order, with the
+// depth relative to t
+func (t *Thread) Walk(fn func(t *Thread, depth int)) {
+	t.walk(fn, 0)
+}
+
+func (t *Thread) walk(fn func(t *Thread, depth int), depth int) {
+	fn(t, depth)
+	for _, c := range t.Children {
+		c.walk(fn, depth+1)
+	}
+}
+
+// Date returns the date of the message of t, or the earliest date of its descendants when it has none
+func (t *Thread) Date() (d time.Time) {
+	if t.Email != nil {
+		return t.Email.Date
+	}
+
+	for _, c := range t.Children {
+		if cd := c.Date(); !cd.IsZero() && (d.IsZero() || cd.Before(d)) {
+			d = cd
+		}
+	}
+
+	return
+}
+
+// Subject returns the subject of the message of t, or of its first descendant with a message
+func (t *Thread) Subject() string {
+	if t.Email != nil {
+		return t.Email.Subject
+	}
+
+	for _, c := range t.Children {
+		if s := c.Subject(); s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
+
+func (t *Thread) addChild(c *Thread) {
+	if c.Parent != nil {
+		c.Parent.removeChild(c)
+	}
+
+	c.Parent = t
+	t.Children = append(t.Children, c)
+}
+
+func (t *Thread) removeChild(c *Thread) {
+	for i, child := range t.Children {
+		if child == c {
+			t.Children = append(t.Children[:i], t.Children[i+1:]...)
+			break
+		}
+	}
+
+	c.Parent = nil
+}
+
+// isAncestorOf reports whether t is c or one of its ancestors
+func (t *Thread) isAncestorOf(c *Thread) bool {
+	for ; c != nil; c = c.Parent {
+		if c == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BuildThreads arranges the emails into conversation trees with the threading
+// algorithm of Jamie Zawinski (https://www.jwz.org/doc/threading.html):
+// messages are linked by their References and In-Reply-To fields, and
+// conversations which lost their references are joined by NormalizeSubject.
+// Roots and children are sorted by date. The Email fields point into emails.
+func BuildThreads(emails []Email) []*Thread {
+	ids := map[string]*Thread{}
+	var containers []*Thread
+	container := func(id string) *Thread {
+		if t, ok := ids[id]; ok {
+			return t
+		}
+
+		t := &Thread{MessageID: id}
+		ids[id] = t
+		containers = append(containers, t)
+		return t
+	}
+
+	for i := range emails {
+		e := &emails[i]
+
+		id := e.MessageID
+		if id == "" || (ids[id] != nil && ids[id].Email != nil) {
+			// missing or duplicate message id
+			id = "\x00" + strconv.Itoa(i)
+		}
+
+		t := container(id)
+		t.Email = e
+		t.MessageID = e.MessageID
+
+		refs := append([]string{}, e.References...)
+		if len(e.InReplyTo) > 0 && (len(refs) == 0 || refs[len(refs)-1] != e.InReplyTo[0]) {
+			refs = append(refs, e.InReplyTo[0])
+		}
+
+		var parent *Thread
+		for _, ref := range refs {
+			r := container(ref)
+			if parent != nil && r.Parent == nil && !r.isAncestorOf(parent) {
+				parent.addChild(r)
+			}
+			parent = r
+		}
+
+		if t.Parent != nil {
+			t.Parent.removeChild(t)
+		}
+
+		if parent != nil && !t.isAncestorOf(parent) {
+			parent.addChild(t)
+		}
+	}
+
+	var roots []*Thread
+	for _, t := range containers {
+		if t.Parent == nil {
+			roots = append(roots, t)
+		}
+	}
+
+	roots = pruneThreads(nil, roots)
+	roots = groupThreadsBySubject(roots)
+	sortThreads(roots)
+
+	return roots
+}
+
+// pruneThreads removes empty containers without children and replaces empty
+// containers by their children, except at the root level when there is more than one
+func pruneThreads(parent *Thread, nodes []*Thread) (result []*Thread) {
+	for _, t := range nodes {
+		t.Children = pruneThreads(t, t.Children)
+		if t.Email != nil {
+			result = append(result, t)
+			continue
+		}
+
+		switch {
+		case len(t.Children) == 0:
+		case parent != nil || len(t.Children) == 1:
+			for _, c := range t.Children {
+				c.Parent = parent
+				result = append(result, c)
+			}
+		default:
+			result = append(result, t)
+		}
+	}
+
+	return
+}
+
+func groupThreadsBySubject(roots []*Thread) []*Thread {
+	isReply := func(t *Thread) bool {
+		return t.Email != nil && replySubjectPattern.MatchString(t.Email.Subject)
+	}
+
+	subjects := map[string]*Thread{}
+	for _, t := range roots {
+		subject := NormalizeSubject(t.Subject())
+		if subject == "" {
+			continue
+		}
+
+		old, ok := subjects[subject]
+		if !ok || (t.Email == nil && old.Email != nil) || (isReply(old) && !isReply(t) && t.Email != nil) {
+			subjects[subject] = t
+		}
+	}
+
+	var result []*Thread
+	for _, t := range roots {
+		subject := NormalizeSubject(t.Subject())
+		c, ok := subjects[subject]
+		if subject == "" || !ok || c == t {
+			result = append(result, t)
+			continue
+		}
+
+		switch {
+		case c.Email == nil && t.Email == nil:
+			for len(t.Children) > 0 {
+				c.addChild(t.Children[0])
+			}
+		case c.Email == nil:
+			c.addChild(t)
+		case t.Email == nil:
+			// t is kept in place of c
+			t.addChild(c)
+			subjects[subject] = t
+			result = replaceThread(result, c, t)
+		case !isReply(c) && isReply(t):
+			c.addChild(t)
+		default:
+			group := &Thread{}
+			group.addChild(c)
+			group.addChild(t)
+			subjects[subject] = group
+			result = replaceThread(result, c, group)
+		}
+	}
+
+	return result
+}
+
+// replaceThread replaces old by t in roots, or appends t when old isn't in roots yet
+func replaceThread(roots []*Thread, old, t *Thread) []*Thread {
+	for i, r := range roots {
+		if r == old {
+			roots[i] = t
+			return roots
+		}
+	}
+
+	return append(roots, t)
+}
+
+func sortThreads(nodes []*Thread) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Date().Before(nodes[j].Date())
+	})
+
+	for _, t := range nodes {
+		sortThreads(t.Children)
+	}
+}
@@ -0,0 +1,89 @@
+package parsemail
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildThreads(t *testing.T) {
+	day := time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC)
+	email := func(hour int, id, subject string, refs ...string) Email {
+		return Email{MessageID: id, Subject: subject, References: refs, Date: day.Add(time.Duration(hour) * time.Hour)}
+	}
+
+	emails := []Email{
+		email(5, "c@example.com", "Re: Hello", "a@example.com", "b@example.com"),
+		email(1, "a@example.com", "Hello"),
+		email(3, "d@example.com", "Re: Other", "x@example.com"),
+		email(2, "b@example.com", "Re: Hello", "a@example.com"),
+		email(4, "e@example.com", "Re: Other", "x@example.com"),
+		email(6, "f@example.com", "RE: Hello"),
+		email(7, "", "Lunch"),
+		email(8, "", "Dinner"),
+	}
+	emails[3].InReplyTo = []string{"a@example.com"}
+
+	roots := BuildThreads(emails)
+
+	var lines []string
+	for _, r := range roots {
+		r.Walk(func(n *Thread, depth int) {
+			name := "()"
+			if n.Email != nil {
+				name = n.Email.Subject
+			}
+			lines = append(lines, fmt.Sprintf("%s%s %s", strings.Repeat("  ", depth), name, n.MessageID))
+		})
+	}
+
+	expected := []string{
+		"Hello a@example.com",
+		"  Re: Hello b@example.com",
+		"    Re: Hello c@example.com",
+		"  RE: Hello f@example.com",
+		"() x@example.com",
+		"  Re: Other d@example.com",
+		"  Re: Other e@example.com",
+		"Lunch ",
+		"Dinner ",
+	}
+
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Wrong threads. Expected:\n%s\nGot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+
+	if roots[1].Subject() != "Re: Other" || !roots[1].Date().Equal(day.Add(3*time.Hour)) {
+		t.Errorf("Wrong empty container subject and date: %s %v", roots[1].Subject(), roots[1].Date())
+	}
+
+	for _, r := range roots {
+		r.Walk(func(n *Thread, depth int) {
+			for _, c := range n.Children {
+				if c.Parent != n {
+					t.Errorf("Wrong parent of %s", c.MessageID)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildThreadsLoops(t *testing.T) {
+	emails := []Email{
+		{MessageID: "a@example.com", References: []string{"b@example.com"}},
+		{MessageID: "b@example.com", References: []string{"a@example.com"}},
+		{MessageID: "c@example.com", References: []string{"c@example.com"}},
+	}
+
+	count := 0
+	for _, r := range BuildThreads(emails) {
+		r.Walk(func(n *Thread, depth int) {
+			count++
+		})
+	}
+
+	if count != 3 {
+		t.Errorf("Expected all 3 messages in the threads, got %v", count)
+	}
+}
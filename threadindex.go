@@ -0,0 +1,88 @@
+package parsemail
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	threadIndexHeaderLen = 22
+	threadIndexChildLen  = 5
+)
+
+// ThreadIndex is a decoded Outlook/Exchange Thread-Index header. All messages
+// of a conversation share the GUID, every reply appends one child block. Date is
+// the time the conversation was started and Children the creation times of the
+// replies leading to this message, so the position in the conversation tree is
+// len(Children).
+type ThreadIndex struct {
+	Date     time.Time
+	GUID     string
+	Children []time.Time
+
+	// Raw is the decoded binary header value
+	Raw []byte
+}
+
+// ConversationID returns the identifier Exchange uses for the conversation: the
+// base64 encoded header block shared by all its messages
+func (ti ThreadIndex) ConversationID() string {
+	if len(ti.Raw) < threadIndexHeaderLen {
+		return ""
+	}
+
+	return base64.StdEncoding.EncodeToString(ti.Raw[:threadIndexHeaderLen])
+}
+
+// parseThreadIndex decodes the base64 Thread-Index value, returning nil when it's missing or malformed
+func parseThreadIndex(s string) *ThreadIndex {
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil || len(raw) < threadIndexHeaderLen || (len(raw)-threadIndexHeaderLen)%threadIndexChildLen != 0 {
+		return nil
+	}
+
+	// the header holds the 48 most significant bits of a FILETIME followed by a GUID
+	var ft [8]byte
+	copy(ft[:6], raw[:6])
+	filetime := binary.BigEndian.Uint64(ft[:])
+
+	ti := &ThreadIndex{
+		Date: filetimeToTime(filetime),
+		GUID: formatGUID(raw[6:threadIndexHeaderLen]),
+		Raw:  raw,
+	}
+
+	for child := raw[threadIndexHeaderLen:]; len(child) > 0; child = child[threadIndexChildLen:] {
+		// 1 bit delta scale, 31 bits time delta, 8 bits random and sequence
+		v := binary.BigEndian.Uint32(child[:4])
+		delta := uint64(v & 0x7fffffff)
+		if v&0x80000000 == 0 {
+			delta <<= 18
+		} else {
+			delta <<= 23
+		}
+
+		filetime += delta
+		ti.Children = append(ti.Children, filetimeToTime(filetime))
+	}
+
+	return ti
+}
+
+func filetimeToTime(ft uint64) time.Time {
+	const unixEpochDelta = 11644473600 // seconds from 1601-01-01 to 1970-01-01
+	return time.Unix(int64(ft/1e7)-unixEpochDelta, int64(ft%1e7)*100).UTC()
+}
+
+// formatGUID formats a GUID stored in Windows byte order (little endian first three groups)
+func formatGUID(b []byte) string {
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(b[0:4]),
+		binary.LittleEndian.Uint16(b[4:6]),
+		binary.LittleEndian.Uint16(b[6:8]),
+		b[8:10],
+		b[10:16])
+}
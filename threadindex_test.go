@@ -0,0 +1,70 @@
+package parsemail
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseThreadIndex(t *testing.T) {
+	started := time.Date(2017, time.April, 7, 9, 17, 26, 0, time.UTC)
+	filetime := uint64(started.Unix()+11644473600) * 1e7
+
+	raw := make([]byte, 8)
+	binary.BigEndian.PutUint64(raw, filetime>>16<<16)
+	raw = raw[:6]
+	raw = append(raw, 0x33, 0x22, 0x11, 0x00, 0x55, 0x44, 0x77, 0x66, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff)
+
+	// a reply 10 minutes later with the delta stored in 2^18 units
+	delta := uint32(uint64(10*time.Minute/100) >> 18)
+	child := make([]byte, 5)
+	binary.BigEndian.PutUint32(child, delta)
+	child[4] = 0x42
+	raw = append(raw, child...)
+
+	message := "From: John Doe <jdoe@machine.example>\n" +
+		"Thread-Topic: Saying Hello\n" +
+		"Thread-Index: " + base64.StdEncoding.EncodeToString(raw) + "\n\nHello.\n"
+
+	e, err := Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.ThreadTopic != "Saying Hello" {
+		t.Errorf("Wrong thread topic: '%s'", e.ThreadTopic)
+	}
+
+	ti := e.ThreadIndex
+	if ti == nil {
+		t.Fatal("Thread index not parsed")
+	}
+
+	if ti.GUID != "00112233-4455-6677-8899-aabbccddeeff" {
+		t.Errorf("Wrong GUID: %s", ti.GUID)
+	}
+
+	if d := ti.Date.Sub(started); d < -10*time.Millisecond || d > 0 {
+		t.Errorf("Wrong date. Expected: %v, Got: %v", started, ti.Date)
+	}
+
+	if len(ti.Children) != 1 {
+		t.Fatalf("Wrong number of children: %v", len(ti.Children))
+	}
+
+	if d := ti.Children[0].Sub(started) - 10*time.Minute; d < -time.Second || d > time.Second {
+		t.Errorf("Wrong child date. Expected: %v, Got: %v", started.Add(10*time.Minute), ti.Children[0])
+	}
+
+	if ti.ConversationID() != base64.StdEncoding.EncodeToString(raw[:22]) {
+		t.Errorf("Wrong conversation id: %s", ti.ConversationID())
+	}
+
+	for _, malformed := range []string{"", "not base64!", base64.StdEncoding.EncodeToString(raw[:25])} {
+		if ti := parseThreadIndex(malformed); ti != nil {
+			t.Errorf("Expected malformed thread index '%s' to be ignored, got %v", malformed, ti)
+		}
+	}
+}
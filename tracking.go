@@ -0,0 +1,168 @@
+package parsemail
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Tracker is a remote image in a HTML body that likely reports opens of the
+// message, with the reasons it was flagged
+type Tracker struct {
+	URL     string
+	Reasons []string
+}
+
+var (
+	trackingDomainsMu sync.RWMutex
+	trackingDomains   = map[string]bool{
+		"list-manage.com":        true,
+		"sendgrid.net":           true,
+		"mandrillapp.com":        true,
+		"mailgun.org":            true,
+		"hubspotemail.net":       true,
+		"exacttarget.com":        true,
+		"sailthru.com":           true,
+		"customer.io":            true,
+		"mailtrack.io":           true,
+		"yesware.com":            true,
+		"mixmax.com":             true,
+		"bananatag.com":          true,
+		"mailfoogae.appspot.com": true,
+		"cirrusinsight.com":      true,
+		"emltrk.com":             true,
+		"litmus.com":             true,
+		"sparkpostmail.com":      true,
+	}
+)
+
+var (
+	hiddenStylePattern = regexp.MustCompile(`(?i)(?:^|[;\s])(?:display\s*:\s*none|visibility\s*:\s*hidden|opacity\s*:\s*0(?:\.0+)?\s*(?:;|$))`)
+	tinyStylePattern   = regexp.MustCompile(`(?i)(?:^|[;\s])(?:max-)?(width|height)\s*:\s*([0-9.]+)\s*(?:px)?\s*(?:;|!|$)`)
+)
+
+// RegisterTrackingDomain adds domain, and its subdomains, to the domains whose
+// images FindTrackers flags as tracking pixels
+func RegisterTrackingDomain(domain string) {
+	trackingDomainsMu.Lock()
+	defer trackingDomainsMu.Unlock()
+
+	trackingDomains[strings.ToLower(strings.TrimSuffix(domain, "."))] = true
+}
+
+// FindTrackers returns the remote images of a HTML body that are 1x1 pixels,
+// hidden or served from a known tracking domain, in document order
+func FindTrackers(html string) []Tracker {
+	return findTrackers(html, nil)
+}
+
+// FindTrackers finds the tracking pixels of a HTML body like the package level
+// FindTrackers, also flagging the domains registered with WithTrackingDomain
+func (p *Parser) FindTrackers(html string) []Tracker {
+	return findTrackers(html, &p.o.registry)
+}
+
+func findTrackers(html string, reg *registry) (trackers []Tracker) {
+	ParseHTML(html).Walk(func(n *HTMLNode) bool {
+		if n.Type != HTMLElementNode || n.Data != "img" {
+			return true
+		}
+
+		src, _ := n.Attribute("src")
+		src = strings.TrimSpace(src)
+		u, err := url.Parse(src)
+		if err != nil || u.Host == "" || (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https") {
+			return true
+		}
+
+		var reasons []string
+		if isTinyImage(n) {
+			reasons = append(reasons, "1x1 image")
+		}
+
+		if isHiddenElement(n) {
+			reasons = append(reasons, "Hidden image")
+		}
+
+		if domain, ok := reg.trackingDomain(u.Hostname()); ok {
+			reasons = append(reasons, "Known tracking domain "+domain)
+		}
+
+		if len(reasons) > 0 {
+			trackers = append(trackers, Tracker{URL: src, Reasons: reasons})
+		}
+
+		return true
+	})
+
+	return
+}
+
+// Trackers returns the tracking pixels of the HTML body, see FindTrackers. The
+// tracking domains registered for the Parser of the email are flagged too.
+func (e Email) Trackers() []Tracker {
+	return findTrackers(e.HTMLBody, e.registry)
+}
+
+// isTinyImage reports whether the width or height of img, set by attribute or inline style, is at most one pixel
+func isTinyImage(img *HTMLNode) bool {
+	for _, key := range []string{"width", "height"} {
+		if v, ok := img.Attribute(key); ok && isTinySize(strings.TrimSuffix(strings.TrimSpace(v), "px")) {
+			return true
+		}
+	}
+
+	style, _ := img.Attribute("style")
+	for _, m := range tinyStylePattern.FindAllStringSubmatch(style, -1) {
+		if isTinySize(m[2]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTinySize(s string) bool {
+	f, err := strconv.ParseFloat(s, 64)
+	return err == nil && f <= 1
+}
+
+// isHiddenElement reports whether n or one of its ancestors is hidden by an attribute or inline style
+func isHiddenElement(n *HTMLNode) bool {
+	for ; n != nil; n = n.Parent {
+		if n.Type != HTMLElementNode {
+			continue
+		}
+
+		if _, ok := n.Attribute("hidden"); ok {
+			return true
+		}
+
+		if style, _ := n.Attribute("style"); hiddenStylePattern.MatchString(style) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// trackingDomain returns the registered tracking domain host belongs to, reg may be nil
+func (reg *registry) trackingDomain(host string) (string, bool) {
+	trackingDomainsMu.RLock()
+	defer trackingDomainsMu.RUnlock()
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for {
+		if trackingDomains[host] || (reg != nil && reg.trackingDomains[host]) {
+			return host, true
+		}
+
+		i := strings.IndexByte(host, '.')
+		if i == -1 {
+			return "", false
+		}
+		host = host[i+1:]
+	}
+}
@@ -0,0 +1,77 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindTrackers(t *testing.T) {
+	var testData = map[int]struct {
+		html     string
+		trackers []Tracker
+	}{
+		1: {
+			html: `<p>Hi</p><img src="https://example.com/logo.png" width="120" height="40"><img src="cid:logo@example.com" width="1" height="1">`,
+		},
+		2: {
+			html: `<img src="https://example.com/o.gif?id=42" width="1" height="1" alt="">`,
+			trackers: []Tracker{
+				{URL: "https://example.com/o.gif?id=42", Reasons: []string{"1x1 image"}},
+			},
+		},
+		3: {
+			html: `<div style="display: none"><img src="//example.com/a.png"></div><img src="https://example.com/b.png" style="width:0px;height:0px">`,
+			trackers: []Tracker{
+				{URL: "//example.com/a.png", Reasons: []string{"Hidden image"}},
+				{URL: "https://example.com/b.png", Reasons: []string{"1x1 image"}},
+			},
+		},
+		4: {
+			html: `<img src="https://u123.ct.sendgrid.net/wf/open?upn=abc" border="0" style="height:1px !important;width:1px !important">`,
+			trackers: []Tracker{
+				{URL: "https://u123.ct.sendgrid.net/wf/open?upn=abc", Reasons: []string{"1x1 image", "Known tracking domain sendgrid.net"}},
+			},
+		},
+		5: {
+			html: `<img src="https://cdn.example.com/banner.png" width="600">`,
+		},
+	}
+
+	for index, td := range testData {
+		trackers := FindTrackers(td.html)
+		if len(trackers) != len(td.trackers) {
+			t.Errorf("[Test Case %v] Wrong trackers. Expected: %+v, Got: %+v", index, td.trackers, trackers)
+			continue
+		}
+
+		for i, tr := range td.trackers {
+			if trackers[i].URL != tr.URL || !assertSliceEq(tr.Reasons, trackers[i].Reasons) {
+				t.Errorf("[Test Case %v] Wrong tracker. Expected: %+v, Got: %+v", index, tr, trackers[i])
+			}
+		}
+	}
+}
+
+func TestRegisterTrackingDomain(t *testing.T) {
+	html := `<img src="https://open.tracker.example/p.png" width="600">`
+	if trackers := FindTrackers(html); len(trackers) != 0 {
+		t.Fatalf("Unexpected trackers: %+v", trackers)
+	}
+
+	RegisterTrackingDomain("tracker.example")
+	defer func() {
+		trackingDomainsMu.Lock()
+		delete(trackingDomains, "tracker.example")
+		trackingDomainsMu.Unlock()
+	}()
+
+	e, err := Parse(strings.NewReader("From: jdoe@machine.example\nContent-Type: text/html\n\n" + html + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trackers := e.Trackers()
+	if len(trackers) != 1 || !assertSliceEq([]string{"Known tracking domain tracker.example"}, trackers[0].Reasons) {
+		t.Errorf("Wrong trackers: %+v", trackers)
+	}
+}
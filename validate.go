@@ -0,0 +1,204 @@
+package parsemail
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxLineLength is the line length limit of RFC 5322 section 2.1.1, without the CRLF
+const maxLineLength = 998
+
+var (
+	msgIDPattern     = regexp.MustCompile(`^<[^<>@\s]+@[^<>@\s]+>$`)
+	msgIDListPattern = regexp.MustCompile(`<[^<>@\s]+@[^<>@\s]+>`)
+
+	// dateTimeLayouts are the date-time forms of RFC 5322 section 3.3, without the obsolete syntax
+	dateTimeLayouts = []string{
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04 -0700",
+		"2 Jan 2006 15:04:05 -0700",
+		"2 Jan 2006 15:04 -0700",
+	}
+
+	// singletonFields may occur at most once (RFC 5322 section 3.6)
+	singletonFields = []string{"Date", "From", "Sender", "Reply-To", "To", "Cc", "Bcc", "Message-Id", "In-Reply-To", "References", "Subject"}
+)
+
+// Violation is a deviation of a message from RFC 5322
+type Violation struct {
+	// Field is the header field the violation concerns, empty for message wide violations
+	Field string
+	// Line is the line the violation was found on, counting from 1, or 0 when it isn't tied to a line
+	Line    int
+	Message string
+}
+
+func (v Violation) String() string {
+	switch {
+	case v.Line > 0 && v.Field != "":
+		return fmt.Sprintf("line %d: %s: %s", v.Line, v.Field, v.Message)
+	case v.Line > 0:
+		return fmt.Sprintf("line %d: %s", v.Line, v.Message)
+	case v.Field != "":
+		return fmt.Sprintf("%s: %s", v.Field, v.Message)
+	}
+
+	return v.Message
+}
+
+// validatedField is a header field with the line it starts on
+type validatedField struct {
+	headerField
+	Line int
+}
+
+// Validate checks the message read from r against RFC 5322 and reports the
+// structural problems it finds: missing required and duplicated singleton
+// header fields, malformed header lines, overlong lines and invalid syntax of
+// the origination date, address and message identification fields. Messages
+// Parse accepts may still have violations. No violations means none of the
+// checks failed, not that the message is fully conformant.
+func Validate(r io.Reader) (violations []Violation) {
+	br := bufio.NewReader(r)
+	var fields []validatedField
+	inHeader := true
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if line == "" && err != nil {
+			if err != io.EOF {
+				violations = append(violations, Violation{Line: n, Message: fmt.Sprintf("Read error: %v", err)})
+			}
+			break
+		}
+
+		trimmed := strings.TrimRight(line, "\r\n")
+		if len(trimmed) > maxLineLength {
+			violations = append(violations, Violation{Line: n, Message: fmt.Sprintf("Line longer than %d characters", maxLineLength)})
+		}
+
+		if !inHeader {
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			inHeader = false
+		case trimmed[0] == ' ' || trimmed[0] == '\t':
+			if len(fields) == 0 {
+				violations = append(violations, Violation{Line: n, Message: "Continuation line without a header field"})
+				continue
+			}
+
+			f := &fields[len(fields)-1]
+			f.Value = strings.TrimSpace(f.Value + " " + strings.TrimSpace(trimmed))
+		default:
+			colon := strings.IndexByte(trimmed, ':')
+			if colon <= 0 || !validFieldName(trimmed[:colon]) {
+				violations = append(violations, Violation{Line: n, Message: "Malformed header field"})
+				continue
+			}
+
+			fields = append(fields, validatedField{
+				headerField: headerField{
+					Name:  textproto.CanonicalMIMEHeaderKey(trimmed[:colon]),
+					Value: strings.TrimSpace(trimmed[colon+1:]),
+				},
+				Line: n,
+			})
+		}
+	}
+
+	return append(violations, validateFields(fields)...)
+}
+
+func validFieldName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if name[i] < 33 || name[i] > 126 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateFields(fields []validatedField) (violations []Violation) {
+	byName := map[string][]validatedField{}
+	for _, f := range fields {
+		byName[f.Name] = append(byName[f.Name], f)
+	}
+
+	for _, name := range []string{"Date", "From"} {
+		if len(byName[name]) == 0 {
+			violations = append(violations, Violation{Field: name, Message: "Missing required header field"})
+		}
+	}
+
+	for _, name := range singletonFields {
+		for i, f := range byName[name] {
+			if i > 0 {
+				violations = append(violations, Violation{Field: f.Name, Line: f.Line, Message: "Duplicate header field"})
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if msg := validateField(f.headerField, byName); msg != "" {
+			violations = append(violations, Violation{Field: f.Name, Line: f.Line, Message: msg})
+		}
+	}
+
+	return
+}
+
+// validateField returns what is wrong with the syntax of a field, or "" when nothing is
+func validateField(f headerField, byName map[string][]validatedField) string {
+	switch f.Name {
+	case "Date", "Resent-Date":
+		if !isDateTime(f.Value) {
+			return "Malformed date-time"
+		}
+	case "From", "Reply-To", "To", "Cc", "Resent-From", "Resent-To", "Resent-Cc":
+		list, err := mail.ParseAddressList(f.Value)
+		if err != nil {
+			return fmt.Sprintf("Malformed address list: %v", err)
+		}
+
+		if f.Name == "From" && len(list) > 1 && len(byName["Sender"]) == 0 {
+			return "Multiple From addresses require a Sender header field"
+		}
+	case "Sender", "Resent-Sender":
+		if _, err := mail.ParseAddress(f.Value); err != nil {
+			return fmt.Sprintf("Malformed address: %v", err)
+		}
+	case "Message-Id", "Resent-Message-Id":
+		if id, _ := splitComments(f.Value); !msgIDPattern.MatchString(strings.TrimSpace(id)) {
+			return "Malformed msg-id"
+		}
+	case "In-Reply-To", "References":
+		ids, _ := splitComments(f.Value)
+		if rest := strings.TrimSpace(msgIDListPattern.ReplaceAllString(ids, "")); rest != "" || strings.TrimSpace(ids) == "" {
+			return "Malformed msg-id list"
+		}
+	}
+
+	return ""
+}
+
+// isDateTime reports whether v is a RFC 5322 date-time, comments and folding white space included
+func isDateTime(v string) bool {
+	v, _ = splitComments(v)
+	v = strings.Join(strings.Fields(v), " ")
+	for _, layout := range dateTimeLayouts {
+		if _, err := time.Parse(layout, v); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
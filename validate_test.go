@@ -0,0 +1,67 @@
+package parsemail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var testData = map[int]struct {
+		mailData   string
+		violations []string
+	}{
+		1: {
+			mailData: rfc5322exampleA11,
+		},
+		2: {
+			mailData:   "Subject: no origin\n\nbody\n",
+			violations: []string{"Date: Missing required header field", "From: Missing required header field"},
+		},
+		3: {
+			mailData: "From: a@example.com\nDate: Fri, 21 Nov 1997 09:55:06 -0600\nSubject: one\nSubject: two\n\nbody\n",
+			violations: []string{
+				"line 4: Subject: Duplicate header field",
+			},
+		},
+		4: {
+			mailData: "From: a@example.com, b@example.com\nDate: 21 Nov 97 09:55 EST\nMessage-ID: 1234.example.com\nReferences: <a@example.com> garbage\nnot a field\n\n" + strings.Repeat("x", 1000) + "\n",
+			violations: []string{
+				"line 5: Malformed header field",
+				"line 7: Line longer than 998 characters",
+				"line 1: From: Multiple From addresses require a Sender header field",
+				"line 2: Date: Malformed date-time",
+				"line 3: Message-Id: Malformed msg-id",
+				"line 4: References: Malformed msg-id list",
+			},
+		},
+		5: {
+			mailData: "From: a@example.com\nDate: Fri, 21 Nov 1997 09:55:06 -0600\nMessage-ID: <1234 (comment) @example.com>\nIn-Reply-To: <a@example.com>\n <b@example.com> (and)\n\nbody\n",
+			violations: []string{
+				"line 3: Message-Id: Malformed msg-id",
+			},
+		},
+		6: {
+			mailData: "From: a@example.com\nDate: Tue, 1 Jul 2003 10:52:37 +0200 (CEST)\nResent-Date: (resent) 2 Jul 2003\n 08:00 +0000\n\nbody\n",
+		},
+		7: {
+			mailData: "From: a@example.com\nDate: Tue, 1 Jul 2003 10:52:37 (CEST)\n\nbody\n",
+			violations: []string{
+				"line 2: Date: Malformed date-time",
+			},
+		},
+	}
+
+	for index, td := range testData {
+		violations := Validate(strings.NewReader(td.mailData))
+		if len(violations) != len(td.violations) {
+			t.Errorf("[Test Case %v] Wrong violations. Expected: %v, Got: %v", index, td.violations, violations)
+			continue
+		}
+
+		for i, v := range violations {
+			if v.String() != td.violations[i] {
+				t.Errorf("[Test Case %v] Wrong violation %v. Expected: '%s', Got: '%s'", index, i, td.violations[i], v.String())
+			}
+		}
+	}
+}
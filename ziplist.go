@@ -0,0 +1,68 @@
+package parsemail
+
+import (
+	"archive/zip"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// ZipEntry is a file contained in a zip attachment
+type ZipEntry struct {
+	Name           string
+	Size           uint64
+	CompressedSize uint64
+	Modified       time.Time
+	Encrypted      bool
+}
+
+// isZipAttachment reports whether a is a zip archive by its content type, filename or signature
+func isZipAttachment(a *Attachment) bool {
+	switch strings.ToLower(a.ContentType) {
+	case "application/zip", "application/x-zip-compressed", "application/x-zip":
+		return true
+	}
+
+	if strings.EqualFold(path.Ext(a.Filename), ".zip") || a.DetectedContentType == "application/zip" {
+		return true
+	}
+
+	magic := make([]byte, 4)
+	n, _ := a.ReadAt(magic, 0)
+	return n == 4 && string(magic) == "PK\x03\x04"
+}
+
+// listZipEntries sets the ZipEntries of the zip attachments of e from their
+// central directory, without extracting them
+func listZipEntries(e *Email) {
+	for i := range e.Attachments {
+		a := &e.Attachments[i]
+		if !isZipAttachment(a) {
+			continue
+		}
+
+		sra, ok := a.Data.(sizedReaderAt)
+		if !ok {
+			e.Warnings = append(e.Warnings, fmt.Sprintf("Skipped listing zip attachment %s: %v", a.Filename, ErrNotSeekable))
+			continue
+		}
+
+		zr, err := zip.NewReader(sra, sra.Size())
+		if err != nil {
+			e.Warnings = append(e.Warnings, fmt.Sprintf("Failed to list zip attachment %s: %v", a.Filename, err))
+			continue
+		}
+
+		a.ZipEntries = make([]ZipEntry, 0, len(zr.File))
+		for _, f := range zr.File {
+			a.ZipEntries = append(a.ZipEntries, ZipEntry{
+				Name:           f.Name,
+				Size:           f.UncompressedSize64,
+				CompressedSize: f.CompressedSize64,
+				Modified:       f.Modified,
+				Encrypted:      f.Flags&0x1 != 0,
+			})
+		}
+	}
+}
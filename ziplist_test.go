@@ -0,0 +1,39 @@
+package parsemail
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestZipListing(t *testing.T) {
+	mailData := "From: jdoe@machine.example\nContent-Type: multipart/mixed; boundary=m\n\n" +
+		"--m\nContent-Type: application/octet-stream\nContent-Disposition: attachment; filename=\"files.zip\"\nContent-Transfer-Encoding: base64\n\n" +
+		base64.StdEncoding.EncodeToString(zipData(t, "a.txt", "dir/b.exe")) + "\n" +
+		"--m\nContent-Type: application/zip\nContent-Disposition: attachment; filename=\"broken.zip\"\nContent-Transfer-Encoding: base64\n\naGVsbG8=\n--m--\n"
+
+	e, err := ParseWithOptions(strings.NewReader(mailData), WithZipListing())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, entry := range e.Attachments[0].ZipEntries {
+		names = append(names, entry.Name)
+		if entry.Size != uint64(len("content")) {
+			t.Errorf("Wrong size of %s. Expected: %v, Got: %v", entry.Name, len("content"), entry.Size)
+		}
+	}
+
+	if expected := []string{"a.txt", "dir/b.exe"}; !assertSliceEq(expected, names) {
+		t.Errorf("Wrong zip entries. Expected: %v, Got: %v", expected, names)
+	}
+
+	if e.Attachments[1].ZipEntries != nil {
+		t.Errorf("Expected no entries for a broken archive, got: %v", e.Attachments[1].ZipEntries)
+	}
+
+	if len(e.Warnings) != 1 || !strings.HasPrefix(e.Warnings[0], "Failed to list zip attachment broken.zip") {
+		t.Errorf("Expected a warning about the broken archive, got: %v", e.Warnings)
+	}
+}